@@ -0,0 +1,171 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful/log"
+)
+
+// KeyExcludeFromAccessLog is the Route metadata key for a bool that, if true, stops the AccessLog from recording
+// requests for that Route, e.g. frequent health checks.
+const KeyExcludeFromAccessLog = "restful.excludeFromAccessLog"
+
+// AccessLogEntry describes one handled request.
+type AccessLogEntry struct {
+	Time            time.Time // start of handling
+	Method          string
+	Path            string // request path, e.g. /users/42
+	RoutePath       string // path template of the matched Route, e.g. /users/{id}
+	Operation       string // operation of the matched Route
+	RemoteAddr      string
+	Status          int
+	ContentLength   int // number of bytes of the response body
+	EncodedLength   int // number of bytes sent for the response body ; less than ContentLength if compressed
+	Latency         time.Duration
+	RequestHeaders  map[string]string // values of AccessLog.RequestHeaders that are present
+	ResponseHeaders map[string]string // values of AccessLog.ResponseHeaders that are present
+	Annotations     map[string]string // values added using AnnotateAccessLog
+}
+
+// accessLogContextKey is the key of the accessLogAnnotations in the context of a logged request.
+type accessLogContextKey struct{}
+
+// accessLogAnnotations collects the values added to an AccessLogEntry while the request is handled.
+type accessLogAnnotations struct {
+	lock   sync.Mutex
+	values map[string]string
+}
+
+// AnnotateAccessLog adds a key-value pair to the AccessLogEntry of the request with this context.
+// It has no effect if the request is not processed by an AccessLog filter.
+// Handlers and the services they call can use it to record, e.g., a customer id or a cache outcome.
+func AnnotateAccessLog(ctx context.Context, key, value string) {
+	annotations, ok := ctx.Value(accessLogContextKey{}).(*accessLogAnnotations)
+	if !ok {
+		return
+	}
+	annotations.lock.Lock()
+	defer annotations.lock.Unlock()
+	annotations.values[key] = value
+}
+
+// AccessLogSink receives AccessLogEntry values. Implementations must be safe for concurrent use.
+// Adapters for logging libraries such as logrus or zap are one-line implementations of this interface.
+type AccessLogSink interface {
+	Log(entry AccessLogEntry)
+}
+
+// AccessLogSinkFunc is an adapter to use a function as an AccessLogSink.
+type AccessLogSinkFunc func(entry AccessLogEntry)
+
+// Log calls f(entry).
+func (f AccessLogSinkFunc) Log(entry AccessLogEntry) { f(entry) }
+
+// AccessLog is used to create a Filter that records each request it processes.
+// Add it as the first Container filter to include the time spent in other filters.
+// Because it completes a compressed response to record the number of bytes sent, filters
+// that are processed before it must not write to the response body.
+//
+//	accessLog := restful.AccessLog{Sink: restful.SlogAccessLogSink(slog.Default()), RequestHeaders: []string{"User-Agent"}}
+//	restful.Filter(accessLog.Filter)
+type AccessLog struct {
+	// Sink receives the entries. If nil, entries are written to the package logger.
+	Sink AccessLogSink
+	// RequestHeaders lists the names of request headers to record.
+	RequestHeaders []string
+	// ResponseHeaders lists the names of response headers to record.
+	ResponseHeaders []string
+}
+
+// Filter records the request after the rest of the chain has processed it.
+func (a AccessLog) Filter(req *Request, resp *Response, chain *FilterChain) {
+	start := time.Now()
+	annotations := &accessLogAnnotations{values: map[string]string{}}
+	chain.ProcessFilter(req.WithContext(context.WithValue(req.Context(), accessLogContextKey{}, annotations)), resp)
+	// the response is complete ; close the compressor, if any, to know the number of bytes sent
+	resp.closeCompressor()
+	route := req.SelectedRoute()
+	if route != nil {
+		if exclude, _ := route.Metadata[KeyExcludeFromAccessLog].(bool); exclude {
+			return
+		}
+	}
+	entry := AccessLogEntry{
+		Time:          start,
+		Method:        req.Request.Method,
+		Path:          req.Request.URL.Path,
+		RoutePath:     req.SelectedRoutePath(),
+		RemoteAddr:    req.Request.RemoteAddr,
+		Status:        resp.StatusCode(),
+		ContentLength: resp.ContentLength(),
+		EncodedLength: resp.EncodedContentLength(),
+		Latency:       time.Since(start),
+	}
+	if route != nil {
+		entry.Operation = route.Operation
+	}
+	if len(a.RequestHeaders) > 0 {
+		entry.RequestHeaders = map[string]string{}
+		for _, each := range a.RequestHeaders {
+			if value := req.Request.Header.Get(each); len(value) > 0 {
+				entry.RequestHeaders[each] = value
+			}
+		}
+	}
+	if len(a.ResponseHeaders) > 0 {
+		entry.ResponseHeaders = map[string]string{}
+		for _, each := range a.ResponseHeaders {
+			if value := resp.Header().Get(each); len(value) > 0 {
+				entry.ResponseHeaders[each] = value
+			}
+		}
+	}
+	annotations.lock.Lock()
+	if len(annotations.values) > 0 {
+		entry.Annotations = annotations.values
+	}
+	annotations.lock.Unlock()
+	if a.Sink == nil {
+		log.Printf("[restful] %s %s %d %d %v", entry.Method, entry.Path, entry.Status, entry.ContentLength, entry.Latency)
+		return
+	}
+	a.Sink.Log(entry)
+}
+
+// SlogAccessLogSink returns an AccessLogSink that writes each entry as an Info record on the structured logger.
+func SlogAccessLogSink(logger *slog.Logger) AccessLogSink {
+	return AccessLogSinkFunc(func(entry AccessLogEntry) {
+		attrs := []slog.Attr{
+			slog.String("method", entry.Method),
+			slog.String("path", entry.Path),
+			slog.String("route", entry.RoutePath),
+			slog.String("operation", entry.Operation),
+			slog.String("remoteAddr", entry.RemoteAddr),
+			slog.Int("status", entry.Status),
+			slog.Int("bytes", entry.ContentLength),
+			slog.Int("encodedBytes", entry.EncodedLength),
+			slog.Duration("latency", entry.Latency),
+		}
+		for _, headers := range []struct {
+			group  string
+			values map[string]string
+		}{{"request", entry.RequestHeaders}, {"response", entry.ResponseHeaders}, {"annotations", entry.Annotations}} {
+			if len(headers.values) == 0 {
+				continue
+			}
+			args := []interface{}{}
+			for k, v := range headers.values {
+				args = append(args, slog.String(k, v))
+			}
+			attrs = append(attrs, slog.Group(headers.group, args...))
+		}
+		logger.LogAttrs(context.Background(), slog.LevelInfo, "access", attrs...)
+	})
+}
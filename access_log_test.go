@@ -0,0 +1,109 @@
+package restful
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestAccessLog ...restful
+func TestAccessLog(t *testing.T) {
+	var entry AccessLogEntry
+	accessLog := AccessLog{
+		Sink:            AccessLogSinkFunc(func(e AccessLogEntry) { entry = e }),
+		RequestHeaders:  []string{"User-Agent", "X-Missing"},
+		ResponseHeaders: []string{HEADER_ContentType},
+	}
+	container := NewContainer()
+	container.Filter(accessLog.Filter)
+	ws := new(WebService).Path("/food")
+	ws.Route(ws.GET("/{kind}").To(writeFood).Produces(MIME_JSON).Operation("getFood"))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/food/apple", nil)
+	httpRequest.Header.Set("User-Agent", "test")
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if got, want := entry.RoutePath, "/food/{kind}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := entry.Operation, "getFood"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := entry.Status, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if entry.ContentLength == 0 {
+		t.Error("expected content length")
+	}
+	if got, want := len(entry.RequestHeaders), 1; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := entry.ResponseHeaders[HEADER_ContentType], MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestSlogAccessLogSink(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	sink := SlogAccessLogSink(slog.New(slog.NewTextHandler(buffer, nil)))
+	sink.Log(AccessLogEntry{Method: "GET", RoutePath: "/food/{kind}", Status: 200, RequestHeaders: map[string]string{"User-Agent": "test"}})
+	for _, each := range []string{"route=/food/{kind}", "status=200", "request.User-Agent=test"} {
+		if !strings.Contains(buffer.String(), each) {
+			t.Errorf("missing %q in %q", each, buffer.String())
+		}
+	}
+}
+
+// go test -v -test.run TestAnnotateAccessLog ...restful
+func TestAnnotateAccessLog(t *testing.T) {
+	var entry AccessLogEntry
+	accessLog := AccessLog{Sink: AccessLogSinkFunc(func(e AccessLogEntry) { entry = e })}
+	container := NewContainer()
+	container.Filter(accessLog.Filter)
+	ws := new(WebService).Path("/annotated")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		AnnotateAccessLog(req.Context(), "cache", "miss")
+	}))
+	container.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/annotated", nil)
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if got, want := entry.Annotations["cache"], "miss"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// no effect without AccessLog
+	AnnotateAccessLog(httpRequest.Context(), "cache", "hit")
+}
+
+// go test -v -test.run TestAccessLogEncodedLength ...restful
+func TestAccessLogEncodedLength(t *testing.T) {
+	var entry AccessLogEntry
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	container.Filter(AccessLog{Sink: AccessLogSinkFunc(func(e AccessLogEntry) { entry = e })}.Filter)
+	ws := new(WebService).Path("/text")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(strings.Repeat("apple pie ", 100)))
+		resp.WriteHeader(http.StatusInternalServerError) // superfluous
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/text", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := entry.Status, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := entry.ContentLength, 1000; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := entry.EncodedLength, httpWriter.Body.Len(); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if entry.EncodedLength >= entry.ContentLength {
+		t.Errorf("expected compression, got %d bytes", entry.EncodedLength)
+	}
+}
@@ -0,0 +1,45 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+// AttributeKey is the name of a Request attribute together with the type of its value.
+// Filters and handlers that share a key cannot store or expect a value of a different type.
+//
+//	var tenantKey = restful.NewAttributeKey[*Tenant]("tenant")
+//	...
+//	restful.SetAttr(req, tenantKey, tenant) // in a filter
+//	...
+//	tenant, ok := restful.GetAttr(req, tenantKey) // in a RouteFunction
+type AttributeKey[T any] struct {
+	name string
+}
+
+// NewAttributeKey returns a key for attributes with values of type T.
+func NewAttributeKey[T any](name string) AttributeKey[T] {
+	return AttributeKey[T]{name: name}
+}
+
+// Name returns the attribute name ; it can be used with Request.Attribute.
+func (k AttributeKey[T]) Name() string {
+	return k.name
+}
+
+// SetAttr stores the value as attribute of the request.
+func SetAttr[T any](req *Request, key AttributeKey[T], value T) {
+	req.SetAttribute(key.name, value)
+}
+
+// GetAttr returns the attribute value of the request. It returns the zero value and false
+// if the attribute is absent or, when set using Request.SetAttribute, has a different type.
+func GetAttr[T any](req *Request, key AttributeKey[T]) (T, bool) {
+	return AttributeAs[T](req, key.name)
+}
+
+// AttributeAs returns the value of an attribute that was set by name if it has type T.
+// Unlike a type assertion on Request.Attribute, it does not panic on a mismatch.
+func AttributeAs[T any](req *Request, name string) (T, bool) {
+	value, ok := req.Attribute(name).(T)
+	return value, ok
+}
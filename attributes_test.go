@@ -0,0 +1,37 @@
+package restful
+
+import (
+	"net/http"
+	"testing"
+)
+
+// go test -v -test.run TestTypedAttributes ...restful
+func TestTypedAttributes(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	countKey := NewAttributeKey[int]("count")
+	foodKey := NewAttributeKey[*food]("food")
+
+	if _, ok := GetAttr(req, countKey); ok {
+		t.Error("expected absent attribute")
+	}
+	SetAttr(req, countKey, 42)
+	SetAttr(req, foodKey, &food{Kind: "apple"})
+	if got, ok := GetAttr(req, countKey); !ok || got != 42 {
+		t.Errorf("got %v,%v want 42,true", got, ok)
+	}
+	if got, ok := GetAttr(req, foodKey); !ok || got.Kind != "apple" {
+		t.Errorf("got %v,%v", got, ok)
+	}
+	if got, want := req.Attribute(countKey.Name()), 42; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// set by name using a different type
+	req.SetAttribute("count", "many")
+	if got, ok := GetAttr(req, countKey); ok || got != 0 {
+		t.Errorf("got %v,%v want 0,false", got, ok)
+	}
+	if got, ok := AttributeAs[string](req, "count"); !ok || got != "many" {
+		t.Errorf("got %v,%v", got, ok)
+	}
+}
@@ -0,0 +1,35 @@
+package auth
+
+import "github.com/emicklei/go-restful"
+
+// APIKey is an Authenticator for an API key given as a request header or query parameter.
+type APIKey struct {
+	Header string // name of the header with the key ; can be empty
+	Query  string // name of the query parameter with the key ; can be empty
+	// Lookup returns the Principal that owns the key.
+	Lookup func(key string) (*Principal, bool)
+}
+
+// Authenticate is part of Authenticator
+func (a APIKey) Authenticate(req *restful.Request) (*Principal, error) {
+	key := ""
+	if len(a.Header) > 0 {
+		key = req.Request.Header.Get(a.Header)
+	}
+	if len(key) == 0 && len(a.Query) > 0 {
+		key = req.QueryParameter(a.Query)
+	}
+	if len(key) == 0 {
+		return nil, ErrNoCredentials
+	}
+	principal, ok := a.Lookup(key)
+	if !ok {
+		return nil, errInvalid("invalid API key")
+	}
+	return principal, nil
+}
+
+// Challenge is part of Authenticator ; API keys have no standard challenge.
+func (a APIKey) Challenge() string {
+	return ""
+}
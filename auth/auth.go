@@ -0,0 +1,126 @@
+// Package auth provides Filters that authenticate requests using HTTP Basic, bearer tokens (JWT), API keys or signed URLs.
+//
+// An authenticated request has a Principal in its attributes:
+//
+//	ws.Filter(auth.Filter(auth.Basic{Realm: "api", Validate: checkPassword}, auth.APIKey{Header: "X-API-Key", Lookup: findKey}))
+//	...
+//	principal, _ := auth.PrincipalOf(req)
+//
+// Requests without valid credentials get a 401 (Unauthorized) problem document with a
+// WWW-Authenticate challenge for each authenticator.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+)
+
+// AttributePrincipal is the name of the Request attribute that holds the authenticated *Principal.
+const AttributePrincipal = "restful.auth.principal"
+
+// PrincipalKey is the typed key of the AttributePrincipal attribute, see restful.GetAttr.
+var PrincipalKey = restful.NewAttributeKey[*Principal](AttributePrincipal)
+
+// ErrNoCredentials is returned by an Authenticator if the request has no credentials of its kind.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Principal is the authenticated identity of a request.
+type Principal struct {
+	Name   string
+	Roles  []string
+	Scopes []string
+	Claims map[string]interface{} // e.g. the claims of a JWT ; can be nil
+}
+
+// HasRole returns whether the principal has the role.
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+// HasScope returns whether the principal has the scope.
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// Authenticator establishes the Principal of a request.
+// It returns ErrNoCredentials if the request has none of its kind, such that another Authenticator can be tried.
+// Any other error means that the credentials are invalid.
+type Authenticator interface {
+	Authenticate(req *restful.Request) (*Principal, error)
+	// Challenge returns the value of the WWW-Authenticate header for a 401 response.
+	Challenge() string
+}
+
+// Filter returns a FilterFunction that authenticates the request using the first Authenticator for which
+// the request has credentials. On success, the Principal is stored in the request attributes.
+func Filter(authenticators ...Authenticator) restful.FilterFunction {
+	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+		for _, each := range authenticators {
+			principal, err := each.Authenticate(req)
+			if err == ErrNoCredentials {
+				continue
+			}
+			if err != nil {
+				Unauthorized(resp, err.Error(), authenticators...)
+				return
+			}
+			restful.SetAttr(req, PrincipalKey, principal)
+			chain.ProcessFilter(req.WithContext(context.WithValue(req.Context(), principalContextKey{}, principal)), resp)
+			return
+		}
+		Unauthorized(resp, "authentication required", authenticators...)
+	}
+}
+
+// principalContextKey is the key of the authenticated *Principal in the context of a request.
+type principalContextKey struct{}
+
+// PrincipalFromContext returns the authenticated Principal of the request with this context, if any.
+// Use it in code that has no access to the Request, e.g. to propagate the identity to downstream services.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// PrincipalOf returns the authenticated Principal of the request, if any.
+func PrincipalOf(req *restful.Request) (*Principal, bool) {
+	principal, ok := restful.GetAttr(req, PrincipalKey)
+	return principal, ok && principal != nil
+}
+
+// Unauthorized writes a 401 problem document with a WWW-Authenticate challenge for each authenticator.
+func Unauthorized(resp *restful.Response, detail string, authenticators ...Authenticator) {
+	for _, each := range authenticators {
+		if challenge := each.Challenge(); len(challenge) > 0 {
+			resp.AddHeader("WWW-Authenticate", challenge)
+		}
+	}
+	resp.WriteProblem(restful.NewProblemDocument(http.StatusUnauthorized, detail))
+}
+
+// Forbidden writes a 403 problem document ; use it if the Principal is not allowed to perform the request.
+func Forbidden(resp *restful.Response, detail string) {
+	resp.WriteProblem(restful.NewProblemDocument(http.StatusForbidden, detail))
+}
+
+// credentials returns the credentials of the Authorization header if it uses the scheme.
+func credentials(req *restful.Request, scheme string) (string, bool) {
+	authorization := req.Request.Header.Get("Authorization")
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) || authorization[len(scheme)] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(scheme)+1:]), true
+}
+
+func contains(list []string, value string) bool {
+	for _, each := range list {
+		if each == value {
+			return true
+		}
+	}
+	return false
+}
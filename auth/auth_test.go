@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+func newTestContainer(filter restful.FilterFunction, principal **Principal) *restful.Container {
+	container := restful.NewContainer()
+	ws := new(restful.WebService).Path("/secret")
+	ws.Filter(filter)
+	ws.Route(ws.GET("").To(func(req *restful.Request, resp *restful.Response) {
+		*principal, _ = PrincipalOf(req)
+		if fromContext, _ := PrincipalFromContext(req.Context()); fromContext != *principal {
+			resp.WriteErrorString(http.StatusInternalServerError, "principal in context differs")
+		}
+	}))
+	container.Add(ws)
+	return container
+}
+
+func TestFilter(t *testing.T) {
+	basic := Basic{Realm: "test", Validate: func(user, password string) (*Principal, bool) {
+		return &Principal{Name: user}, user == "admin" && password == "secret"
+	}}
+	apiKey := APIKey{Header: "X-API-Key", Query: "api_key", Lookup: func(key string) (*Principal, bool) {
+		return &Principal{Name: "robot"}, key == "42"
+	}}
+	var principal *Principal
+	container := newTestContainer(Filter(basic, apiKey), &principal)
+
+	tests := []struct {
+		url, authorization, apiKey string
+		code                       int
+		name                       string
+	}{
+		{"/secret", "", "", http.StatusUnauthorized, ""},
+		{"/secret", "Basic YWRtaW46c2VjcmV0", "", http.StatusOK, "admin"},
+		{"/secret", "Basic YWRtaW46YWRtaW4=", "", http.StatusUnauthorized, ""},
+		{"/secret", "", "42", http.StatusOK, "robot"},
+		{"/secret?api_key=42", "", "", http.StatusOK, "robot"},
+		{"/secret", "", "7", http.StatusUnauthorized, ""},
+	}
+	for i, each := range tests {
+		principal = nil
+		httpRequest, _ := http.NewRequest("GET", each.url, nil)
+		if len(each.authorization) > 0 {
+			httpRequest.Header.Set("Authorization", each.authorization)
+		}
+		if len(each.apiKey) > 0 {
+			httpRequest.Header.Set("X-API-Key", each.apiKey)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%d: got %v want %v", i, got, want)
+		}
+		if each.code == http.StatusUnauthorized {
+			if got, want := httpWriter.Header().Get("WWW-Authenticate"), `Basic realm="test"`; got != want {
+				t.Errorf("%d: got %v want %v", i, got, want)
+			}
+			continue
+		}
+		if principal == nil || principal.Name != each.name {
+			t.Errorf("%d: unexpected principal %v", i, principal)
+		}
+	}
+}
+
+func TestPrincipal(t *testing.T) {
+	p := &Principal{Roles: []string{"admin"}, Scopes: []string{"read"}}
+	if !p.HasRole("admin") || p.HasRole("user") {
+		t.Error("unexpected roles")
+	}
+	if !p.HasScope("read") || p.HasScope("write") {
+		t.Error("unexpected scopes")
+	}
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+func TestAuthorize(t *testing.T) {
+	principals := map[string]*Principal{
+		"admin":  {Name: "ann", Roles: []string{"admin"}, Scopes: []string{"users:read", "users:write"}},
+		"reader": {Name: "rob", Roles: []string{"user"}, Scopes: []string{"users:read"}},
+	}
+	apiKey := APIKey{Header: "X-API-Key", Lookup: func(key string) (*Principal, bool) {
+		p, ok := principals[key]
+		return p, ok
+	}}
+	container := restful.NewContainer()
+	ws := new(restful.WebService).Path("/users")
+	ws.Filter(Filter(apiKey))
+	ws.Filter(Authorize)
+	noop := func(req *restful.Request, resp *restful.Response) {}
+	ws.Route(ws.GET("").To(noop))
+	ws.Route(ws.GET("/{id}").To(noop).Metadata(KeyScopes, []string{"users:read"}))
+	ws.Route(ws.DELETE("/{id}").To(noop).Metadata(KeyRoles, []string{"admin", "owner"}).Metadata(KeyScopes, []string{"users:write"}))
+	container.Add(ws)
+
+	tests := []struct {
+		method, path, key string
+		code              int
+	}{
+		{"GET", "/users", "reader", http.StatusOK},
+		{"GET", "/users/1", "reader", http.StatusOK},
+		{"DELETE", "/users/1", "reader", http.StatusForbidden},
+		{"DELETE", "/users/1", "admin", http.StatusOK},
+	}
+	for i, each := range tests {
+		httpRequest, _ := http.NewRequest(each.method, each.path, nil)
+		httpRequest.Header.Set("X-API-Key", each.key)
+		httpWriter := httptest.NewRecorder()
+		container.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%d: got %v want %v", i, got, want)
+		}
+		if each.code == http.StatusForbidden {
+			var problem restful.ProblemDocument
+			if err := json.Unmarshal(httpWriter.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if got, want := problem.Detail, "requires one of the roles: admin, owner"; got != want {
+				t.Errorf("got %v want %v", got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Basic is an Authenticator for HTTP Basic authentication.
+type Basic struct {
+	Realm string
+	// Validate returns the Principal for the user if the password is correct.
+	// Implementations should compare passwords in constant time, e.g. using crypto/subtle.
+	Validate func(user, password string) (*Principal, bool)
+}
+
+// Authenticate is part of Authenticator
+func (b Basic) Authenticate(req *restful.Request) (*Principal, error) {
+	if _, ok := credentials(req, "Basic"); !ok {
+		return nil, ErrNoCredentials
+	}
+	user, password, ok := req.Request.BasicAuth()
+	if !ok {
+		return nil, errInvalid("malformed basic credentials")
+	}
+	principal, ok := b.Validate(user, password)
+	if !ok {
+		return nil, errInvalid("invalid user or password")
+	}
+	return principal, nil
+}
+
+// Challenge is part of Authenticator
+func (b Basic) Challenge() string {
+	return "Basic realm=" + strconv.Quote(b.Realm)
+}
+
+// errInvalid is the error for invalid credentials.
+type errInvalid string
+
+func (e errInvalid) Error() string { return string(e) }
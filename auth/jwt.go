@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+// JWTHeader is the header of a JSON Web Token.
+type JWTHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid,omitempty"`
+	Type      string `json:"typ,omitempty"`
+}
+
+// JWT is an Authenticator for bearer tokens that are signed JSON Web Tokens (RFC 7519).
+// Supported algorithms are HS256/384/512 ([]byte key), RS256/384/512 and PS256/384/512 (*rsa.PublicKey)
+// and ES256/384/512 (*ecdsa.PublicKey). The "exp" and "nbf" claims are checked if present.
+type JWT struct {
+	Realm string
+	// KeyProvider returns the key that verifies the signature of a token with this header, e.g. by its KeyID.
+	// The type of the key must match the algorithm ; this prevents algorithm confusion.
+	KeyProvider func(header JWTHeader) (interface{}, error)
+	// Validate, if set, checks the claims of a token with a valid signature, e.g. "iss" and "aud".
+	Validate func(claims map[string]interface{}) error
+	// Principal, if set, creates the Principal from the claims.
+	// The default uses "sub" as Name, "roles" as Roles and "scope" (or "scp") as Scopes.
+	Principal func(claims map[string]interface{}) *Principal
+	// Leeway is the allowed clock skew for checking "exp" and "nbf".
+	Leeway time.Duration
+}
+
+// Authenticate is part of Authenticator
+func (j JWT) Authenticate(req *restful.Request) (*Principal, error) {
+	token, ok := credentials(req, "Bearer")
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+	claims, err := j.verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if j.Validate != nil {
+		if err := j.Validate(claims); err != nil {
+			return nil, errInvalid(err.Error())
+		}
+	}
+	if j.Principal != nil {
+		return j.Principal(claims), nil
+	}
+	return principalFromClaims(claims), nil
+}
+
+// Challenge is part of Authenticator
+func (j JWT) Challenge() string {
+	if len(j.Realm) == 0 {
+		return "Bearer"
+	}
+	return "Bearer realm=" + strconv.Quote(j.Realm)
+}
+
+// verify checks the signature and time claims of the token and returns its claims.
+func (j JWT) verify(token string, now time.Time) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errInvalid("malformed token")
+	}
+	var header JWTHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, errInvalid("malformed token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errInvalid("malformed token signature")
+	}
+	key, err := j.KeyProvider(header)
+	if err != nil {
+		return nil, errInvalid(err.Error())
+	}
+	if err := verifySignature(header.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, errInvalid(err.Error())
+	}
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, errInvalid("malformed token claims")
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(j.Leeway)) {
+		return nil, errInvalid("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(j.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, errInvalid("token is not valid yet")
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var errSignature = errors.New("invalid token signature")
+
+// verifySignature checks the signature of the signing input using the algorithm and key.
+func verifySignature(algorithm string, key interface{}, signingInput string, signature []byte) error {
+	if len(algorithm) != 5 {
+		return errors.New("unsupported token algorithm: " + algorithm)
+	}
+	var hash crypto.Hash
+	switch algorithm[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return errors.New("unsupported token algorithm: " + algorithm)
+	}
+	hasher := hash.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+	switch algorithm[:2] {
+	case "HS":
+		secret, ok := key.([]byte)
+		if !ok {
+			return errors.New("key does not match token algorithm")
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errSignature
+		}
+		return nil
+	case "RS", "PS":
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("key does not match token algorithm")
+		}
+		var err error
+		if algorithm[:2] == "RS" {
+			err = rsa.VerifyPKCS1v15(publicKey, hash, digest, signature)
+		} else {
+			err = rsa.VerifyPSS(publicKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		if err != nil {
+			return errSignature
+		}
+		return nil
+	case "ES":
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("key does not match token algorithm")
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errSignature
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return errSignature
+		}
+		return nil
+	}
+	return errors.New("unsupported token algorithm: " + algorithm)
+}
+
+// principalFromClaims uses the registered "sub" claim and the common "roles", "scope" and "scp" claims.
+func principalFromClaims(claims map[string]interface{}) *Principal {
+	principal := &Principal{Claims: claims}
+	principal.Name, _ = claims["sub"].(string)
+	principal.Roles = stringsOfClaim(claims["roles"])
+	if scope, ok := claims["scope"].(string); ok {
+		principal.Scopes = strings.Fields(scope)
+	} else {
+		principal.Scopes = stringsOfClaim(claims["scp"])
+	}
+	return principal
+}
+
+// stringsOfClaim returns the strings of a claim that is either a list or a space separated string.
+func stringsOfClaim(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		list := []string{}
+		for _, each := range value {
+			if s, ok := each.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
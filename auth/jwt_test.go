@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func signedToken(t *testing.T, header JWTHeader, claims map[string]interface{}, sign func(input []byte) []byte) string {
+	h, _ := json.Marshal(header)
+	c, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(c)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(input)))
+}
+
+func hs256(secret []byte) func([]byte) []byte {
+	return func(input []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(input)
+		return mac.Sum(nil)
+	}
+}
+
+func TestJWT_HS256(t *testing.T) {
+	secret := []byte("secret")
+	authenticator := JWT{
+		KeyProvider: func(header JWTHeader) (interface{}, error) { return secret, nil },
+		Validate: func(claims map[string]interface{}) error {
+			if claims["iss"] != "me" {
+				return errors.New("unknown issuer")
+			}
+			return nil
+		},
+	}
+	var principal *Principal
+	container := newTestContainer(Filter(authenticator), &principal)
+	now := time.Now().Unix()
+	tests := []struct {
+		claims map[string]interface{}
+		secret []byte
+		code   int
+	}{
+		{map[string]interface{}{"sub": "joe", "iss": "me", "roles": []string{"admin"}, "scope": "read write", "exp": now + 60}, secret, http.StatusOK},
+		{map[string]interface{}{"sub": "joe", "iss": "me", "exp": now - 60}, secret, http.StatusUnauthorized},
+		{map[string]interface{}{"sub": "joe", "iss": "me", "nbf": now + 60}, secret, http.StatusUnauthorized},
+		{map[string]interface{}{"sub": "joe", "iss": "you"}, secret, http.StatusUnauthorized},
+		{map[string]interface{}{"sub": "joe", "iss": "me"}, []byte("other"), http.StatusUnauthorized},
+	}
+	for i, each := range tests {
+		principal = nil
+		token := signedToken(t, JWTHeader{Algorithm: "HS256"}, each.claims, hs256(each.secret))
+		httpRequest, _ := http.NewRequest("GET", "/secret", nil)
+		httpRequest.Header.Set("Authorization", "Bearer "+token)
+		httpWriter := httptest.NewRecorder()
+		container.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%d: got %v want %v", i, got, want)
+		}
+	}
+	token := signedToken(t, JWTHeader{Algorithm: "HS256"}, tests[0].claims, hs256(secret))
+	httpRequest, _ := http.NewRequest("GET", "/secret", nil)
+	httpRequest.Header.Set("Authorization", "Bearer "+token)
+	container.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if principal == nil || principal.Name != "joe" || !principal.HasRole("admin") || !principal.HasScope("write") {
+		t.Errorf("unexpected principal %#v", principal)
+	}
+}
+
+func TestJWT_RS256AndES256(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	authenticator := JWT{KeyProvider: func(header JWTHeader) (interface{}, error) {
+		switch header.KeyID {
+		case "rsa":
+			return &rsaKey.PublicKey, nil
+		case "ec":
+			return &ecKey.PublicKey, nil
+		}
+		return nil, errors.New("unknown key")
+	}}
+	claims := map[string]interface{}{"sub": "joe"}
+	rs256 := signedToken(t, JWTHeader{Algorithm: "RS256", KeyID: "rsa"}, claims, func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+		return signature
+	})
+	es256 := signedToken(t, JWTHeader{Algorithm: "ES256", KeyID: "ec"}, claims, func(input []byte) []byte {
+		digest := sha256.Sum256(input)
+		r, s, _ := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+		signature := make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+		return signature
+	})
+	for _, each := range []string{rs256, es256} {
+		if _, err := authenticator.verify(each, time.Now()); err != nil {
+			t.Error(err)
+		}
+	}
+	// algorithm confusion: HMAC signed with the RSA key id must be rejected
+	confused := signedToken(t, JWTHeader{Algorithm: "HS256", KeyID: "rsa"}, claims, hs256([]byte("guess")))
+	if _, err := authenticator.verify(confused, time.Now()); err == nil {
+		t.Error("expected error for mismatching key type")
+	}
+	none := signedToken(t, JWTHeader{Algorithm: "none", KeyID: "rsa"}, claims, func([]byte) []byte { return nil })
+	if _, err := authenticator.verify(none, time.Now()); err == nil {
+		t.Error("expected error for alg none")
+	}
+}
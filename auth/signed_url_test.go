@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+func TestSignedURL(t *testing.T) {
+	signer := SignedURL{Key: []byte("secret")}
+	container := restful.NewContainer()
+	ws := new(restful.WebService).Path("/files")
+	ws.Filter(Filter(signer))
+	noop := func(req *restful.Request, resp *restful.Response) {}
+	ws.Route(ws.GET("/{name}").To(noop).Metadata(KeySignedURL, true))
+	ws.Route(ws.HEAD("/{name}").To(noop).Metadata(KeySignedURL, true))
+	ws.Route(ws.DELETE("/{name}").To(noop).Metadata(KeySignedURL, true))
+	ws.Route(ws.GET("/{name}/versions").To(noop))
+	container.Add(ws)
+
+	sign := func(method, target string, expires time.Duration) string {
+		link, err := signer.Sign(method, target, time.Now().Add(expires))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return link
+	}
+	valid := sign("GET", "/files/report.pdf?inline=true", time.Hour)
+	tests := []struct {
+		method, url string
+		code        int
+	}{
+		{"GET", valid, http.StatusOK},
+		{"HEAD", valid, http.StatusOK},
+		{"DELETE", valid, http.StatusUnauthorized},
+		{"GET", strings.Replace(valid, "report", "secrets", 1), http.StatusUnauthorized},
+		{"GET", strings.Replace(valid, "inline=true", "inline=false", 1), http.StatusUnauthorized},
+		{"GET", sign("GET", "/files/report.pdf", -time.Minute), http.StatusUnauthorized},
+		{"GET", sign("GET", "/files/report.pdf/versions", time.Hour), http.StatusUnauthorized},
+		{"GET", "/files/report.pdf", http.StatusUnauthorized},
+		{"DELETE", sign("DELETE", "/files/report.pdf", time.Hour), http.StatusOK},
+	}
+	for i, each := range tests {
+		httpRequest, _ := http.NewRequest(each.method, each.url, nil)
+		httpWriter := httptest.NewRecorder()
+		container.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%d: got %v want %v, body: %s", i, got, want, httpWriter.Body.String())
+		}
+	}
+	if _, err := (SignedURL{}).Sign("GET", "/files/report.pdf", time.Now()); err == nil {
+		t.Error("expected error for missing key")
+	}
+}
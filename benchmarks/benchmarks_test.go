@@ -0,0 +1,154 @@
+package benchmarks
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+// go test -v -test.run TestFixturesDispatch ...benchmarks
+func TestFixturesDispatch(t *testing.T) {
+	for _, fixture := range Fixtures() {
+		for _, selector := range Selectors {
+			container := fixture.Container(selector.New())
+			w := new(DiscardWriter)
+			for _, each := range fixture.Requests() {
+				w.Reset()
+				container.ServeHTTP(w, each)
+				if got, want := w.Status, http.StatusOK; got != want {
+					t.Fatalf("%s %s %s: got %d want %d", fixture.Name, selector.Name, each.URL.Path, got, want)
+				}
+			}
+		}
+	}
+}
+
+// BenchmarkDispatch dispatches the requests of each fixture, in turn, with each of the Selectors.
+func BenchmarkDispatch(b *testing.B) {
+	for _, fixture := range Fixtures() {
+		for _, selector := range Selectors {
+			b.Run(fixture.Name+"/"+selector.Name, func(b *testing.B) {
+				container := fixture.Container(selector.New())
+				requests := fixture.Requests()
+				w := new(DiscardWriter)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Reset()
+					container.ServeHTTP(w, requests[i%len(requests)])
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkWrapperPooling dispatches the requests of the 1000 Routes fixtures with and without pooling of Request and Response.
+func BenchmarkWrapperPooling(b *testing.B) {
+	for _, fixture := range []Fixture{StaticFixture(1000), ParameterizedFixture(1000)} {
+		for _, pooling := range []bool{false, true} {
+			b.Run(fixture.Name+"/pooling="+strconv.FormatBool(pooling), func(b *testing.B) {
+				container := fixture.Container(restful.CurlyRouter{})
+				container.EnableWrapperPooling(pooling)
+				requests := fixture.Requests()
+				w := new(DiscardWriter)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Reset()
+					container.ServeHTTP(w, requests[i%len(requests)])
+				}
+			})
+		}
+	}
+}
+
+func passFilter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	chain.ProcessFilter(req, resp)
+}
+
+// BenchmarkFilterChain dispatches a request through a number of Container, WebService and Route filters each.
+func BenchmarkFilterChain(b *testing.B) {
+	for _, count := range []int{0, 1, 5, 10} {
+		b.Run(strconv.Itoa(count), func(b *testing.B) {
+			ws := new(restful.WebService).Path("/filtered").Produces(restful.MIME_JSON)
+			route := ws.GET("").To(writeOK)
+			container := restful.NewContainer()
+			container.Router(restful.CurlyRouter{})
+			for i := 0; i < count; i++ {
+				container.Filter(passFilter)
+				ws.Filter(passFilter)
+				route.Filter(passFilter)
+			}
+			ws.Route(route)
+			container.Add(ws)
+			request, _ := http.NewRequest(http.MethodGet, "http://bench.com/filtered", nil)
+			w := new(DiscardWriter)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				w.Reset()
+				container.ServeHTTP(w, request)
+			}
+		})
+	}
+}
+
+type benchmarkItem struct {
+	XMLName xml.Name `json:"-" xml:"item"`
+	ID      int      `json:"id" xml:"id,attr"`
+	Name    string   `json:"name" xml:"name"`
+	Tags    []string `json:"tags" xml:"tag"`
+}
+
+type benchmarkItems struct {
+	XMLName xml.Name        `json:"-" xml:"items"`
+	Items   []benchmarkItem `json:"items" xml:"item"`
+}
+
+func newBenchmarkItems(count int) benchmarkItems {
+	items := benchmarkItems{}
+	for i := 0; i < count; i++ {
+		items.Items = append(items.Items, benchmarkItem{ID: i, Name: "item " + strconv.Itoa(i), Tags: []string{"a", "b"}})
+	}
+	return items
+}
+
+// BenchmarkWriteEntity writes a small and a large entity as JSON and XML.
+func BenchmarkWriteEntity(b *testing.B) {
+	for _, count := range []int{1, 1000} {
+		for _, mime := range []string{restful.MIME_JSON, restful.MIME_XML} {
+			b.Run(strconv.Itoa(count)+"/"+mime, func(b *testing.B) {
+				entity := newBenchmarkItems(count)
+				w := new(DiscardWriter)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					w.Reset()
+					resp := restful.NewResponse(w)
+					resp.SetRequestAccepts(mime)
+					if err := resp.WriteEntity(entity); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// go test -v -test.run TestWriteEntityFixture ...benchmarks
+func TestWriteEntityFixture(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := restful.NewResponse(recorder)
+	resp.SetRequestAccepts(restful.MIME_XML)
+	if err := resp.WriteEntity(newBenchmarkItems(2)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := recorder.Body.String(), `<item id="1">`; !strings.Contains(got, want) {
+		t.Errorf("got %s want it to contain %s", got, want)
+	}
+}
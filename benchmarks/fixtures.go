@@ -0,0 +1,143 @@
+// Package benchmarks has route table fixtures and benchmarks for dispatching requests, running filter chains
+// and writing entities, such that performance regressions are caught and RouteSelectors can be compared.
+//
+//	go test -run=none -bench=. -benchmem ./benchmarks
+//	go test -run=none -bench=Dispatch/Parameterized5000 -cpuprofile=cpu.out ./benchmarks
+//	go tool pprof benchmarks.test cpu.out
+//
+// To compare an alternative RouteSelector, add it to Selectors in a test of this package.
+// The fixtures are exported to benchmark it in other packages too.
+package benchmarks
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Sizes are the numbers of Routes of the fixtures.
+var Sizes = []int{100, 1000, 5000}
+
+// routesPerWebService is the number of Routes of each WebService of a fixture.
+const routesPerWebService = 50
+
+// Selector creates a RouteSelector to benchmark.
+type Selector struct {
+	Name string
+	New  func() restful.RouteSelector
+}
+
+// Selectors are the RouteSelectors that are benchmarked.
+var Selectors = []Selector{
+	{Name: "RouterJSR311", New: func() restful.RouteSelector { return restful.RouterJSR311{} }},
+	{Name: "CurlyRouter", New: func() restful.RouteSelector { return restful.CurlyRouter{} }},
+}
+
+// Fixture is a route table with a request path for each of its Routes.
+type Fixture struct {
+	Name        string // e.g. Static100
+	WebServices []*restful.WebService
+	Paths       []string // in order of the Routes
+}
+
+// Fixtures returns the static and parameterized fixtures for each of the Sizes.
+func Fixtures() []Fixture {
+	fixtures := []Fixture{}
+	for _, each := range Sizes {
+		fixtures = append(fixtures, StaticFixture(each), ParameterizedFixture(each))
+	}
+	return fixtures
+}
+
+// StaticFixture returns a fixture with the number of Routes without path parameters, e.g. GET /static/s3/r7.
+func StaticFixture(routes int) Fixture {
+	fixture := Fixture{Name: fmt.Sprintf("Static%d", routes)}
+	for i := 0; i*routesPerWebService < routes; i++ {
+		ws := new(restful.WebService).Path(fmt.Sprintf("/static/s%d", i)).Produces(restful.MIME_JSON)
+		for j := 0; j < routesPerWebService && i*routesPerWebService+j < routes; j++ {
+			ws.Route(ws.GET(fmt.Sprintf("/r%d", j)).To(writeOK))
+			fixture.Paths = append(fixture.Paths, fmt.Sprintf("/static/s%d/r%d", i, j))
+		}
+		fixture.WebServices = append(fixture.WebServices, ws)
+	}
+	return fixture
+}
+
+// ParameterizedFixture returns a fixture with the number of Routes with path parameters,
+// e.g. GET /api/v3/{tenant}/r7/{id}/items/{item}.
+func ParameterizedFixture(routes int) Fixture {
+	fixture := Fixture{Name: fmt.Sprintf("Parameterized%d", routes)}
+	for i := 0; i*routesPerWebService < routes; i++ {
+		ws := new(restful.WebService).Path(fmt.Sprintf("/api/v%d/{tenant}", i)).Produces(restful.MIME_JSON)
+		for j := 0; j < routesPerWebService && i*routesPerWebService+j < routes; j++ {
+			ws.Route(ws.GET(fmt.Sprintf("/r%d/{id}/items/{item}", j)).To(writeOK))
+			fixture.Paths = append(fixture.Paths, fmt.Sprintf("/api/v%d/acme/r%d/%d/items/%d", i, j, i*j, j))
+		}
+		fixture.WebServices = append(fixture.WebServices, ws)
+	}
+	return fixture
+}
+
+// Container returns a new Container with the WebServices of the fixture and the RouteSelector.
+func (f Fixture) Container(selector restful.RouteSelector) *restful.Container {
+	container := restful.NewContainer()
+	container.Router(selector)
+	for _, each := range f.WebServices {
+		container.Add(each)
+	}
+	return container
+}
+
+// Requests returns a GET request for each of the Paths ; requests can be dispatched repeatedly because they have no body.
+func (f Fixture) Requests() []*http.Request {
+	requests := make([]*http.Request, len(f.Paths))
+	for i, each := range f.Paths {
+		requests[i], _ = http.NewRequest(http.MethodGet, "http://bench.com"+each, nil)
+		requests[i].Header.Set(restful.HEADER_Accept, restful.MIME_JSON)
+	}
+	return requests
+}
+
+func writeOK(req *restful.Request, resp *restful.Response) {
+	io.WriteString(resp, "ok")
+}
+
+// DiscardWriter is an http.ResponseWriter that keeps the status and discards the body,
+// such that benchmarks measure the Container and not a recorder.
+type DiscardWriter struct {
+	header http.Header
+	Status int
+}
+
+// Reset clears the header and status for the next request.
+func (w *DiscardWriter) Reset() {
+	for each := range w.header {
+		delete(w.header, each)
+	}
+	w.Status = 0
+}
+
+// Header is part of http.ResponseWriter
+func (w *DiscardWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = http.Header{}
+	}
+	return w.header
+}
+
+// Write is part of http.ResponseWriter
+func (w *DiscardWriter) Write(data []byte) (int, error) {
+	if w.Status == 0 {
+		w.Status = http.StatusOK
+	}
+	return len(data), nil
+}
+
+// WriteHeader is part of http.ResponseWriter
+func (w *DiscardWriter) WriteHeader(status int) {
+	if w.Status == 0 {
+		w.Status = status
+	}
+}
@@ -0,0 +1,211 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParameterError describes a parameter that is missing or has a value that cannot be converted.
+type ParameterError struct {
+	Kind  string // "path", "query" or "header"
+	Name  string
+	Value string
+	Err   error
+}
+
+// Error returns a text representation of the parameter error
+func (e ParameterError) Error() string {
+	if len(e.Value) == 0 {
+		return fmt.Sprintf("%s parameter %q: %v", e.Kind, e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s parameter %q has invalid value %q: %v", e.Kind, e.Name, e.Value, e.Err)
+}
+
+// ParameterErrors is returned by BindParameters if one or more parameters are invalid.
+// Handlers typically respond with resp.WriteError(http.StatusBadRequest, err).
+type ParameterErrors []ParameterError
+
+// Error returns the texts of all parameter errors
+func (e ParameterErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, each := range e {
+		messages[i] = each.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+var errMissingParameter = errors.New("missing required value")
+
+// BindParameters populates the exported fields of the struct pointed to by dst using the
+// path, query and header parameters named by the field tags.
+// A field keeps its value if the parameter is absent, unless the tag has the option "required".
+//
+//	var params struct {
+//		ID     int64     `path:"id"`
+//		Page   int       `query:"page"`
+//		Tags   []string  `query:"tag"`
+//		Since  time.Time `query:"since"` // RFC 3339
+//		Tenant string    `header:"X-Tenant,required"`
+//	}
+//	if err := req.BindParameters(&params); err != nil {
+//		resp.WriteError(http.StatusBadRequest, err)
+//		return
+//	}
+//
+// Supported field types are strings, booleans, integers, floats, time.Duration, time.Time,
+// types implementing encoding.TextUnmarshaler, pointers to those and slices of those (multiple values).
+// Embedded structs are bound too. All invalid parameters are reported as ParameterErrors.
+// If all parameters are valid then the struct is checked by the Validator of the Container, if any.
+func (r *Request) BindParameters(dst interface{}) error {
+	target := reflect.ValueOf(dst)
+	if target.Kind() != reflect.Ptr || target.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("BindParameters requires a pointer to a struct, got %T", dst)
+	}
+	var errs ParameterErrors
+	r.bindParameters(target.Elem(), &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return r.validate(dst, http.StatusBadRequest)
+}
+
+func (r *Request) bindParameters(target reflect.Value, errs *ParameterErrors) {
+	structType := target.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			r.bindParameters(target.Field(i), errs)
+			continue
+		}
+		if len(field.PkgPath) > 0 { // unexported
+			continue
+		}
+		for _, kind := range []string{"path", "query", "header"} {
+			tag, ok := field.Tag.Lookup(kind)
+			if !ok {
+				continue
+			}
+			name, option := tag, ""
+			if comma := strings.Index(tag, ","); comma != -1 {
+				name, option = tag[:comma], tag[comma+1:]
+			}
+			values := r.parameterValues(kind, name)
+			if len(values) == 0 {
+				if option == "required" {
+					*errs = append(*errs, ParameterError{Kind: kind, Name: name, Err: errMissingParameter})
+				}
+				continue
+			}
+			if err := setParameterValue(target.Field(i), values); err != nil {
+				if numError, ok := err.(*strconv.NumError); ok {
+					err = numError.Err
+				}
+				*errs = append(*errs, ParameterError{Kind: kind, Name: name, Value: values[0], Err: err})
+			}
+		}
+	}
+}
+
+// parameterValues returns all values of a path, query or header parameter.
+func (r *Request) parameterValues(kind, name string) []string {
+	switch kind {
+	case "path":
+		if value, ok := r.pathParameters[name]; ok {
+			return []string{value}
+		}
+		return nil
+	case "query":
+		return r.Request.URL.Query()[name]
+	default:
+		return r.Request.Header.Values(name)
+	}
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setParameterValue converts the values into the field ; slices get all values, other types the first one.
+func setParameterValue(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, each := range values {
+			if err := setParameterValue(slice.Index(i), []string{each}); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		value := reflect.New(field.Type().Elem())
+		if err := setParameterValue(value.Elem(), values); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+	value := values[0]
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+	switch field.Type() {
+	case durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	case timeType:
+		moment, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(moment))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %v", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package restful
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pageParameters struct {
+	Page int `query:"page"`
+	Size int `query:"size"`
+}
+
+type orderParameters struct {
+	pageParameters
+	ID      int64         `path:"id"`
+	Tags    []string      `query:"tag"`
+	Since   *time.Time    `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	Ratio   float32       `query:"ratio"`
+	Debug   bool          `query:"debug"`
+	Origin  net.IP        `header:"X-Origin"`
+	Tenant  string        `header:"X-Tenant,required"`
+	ignored string        `query:"ignored"`
+}
+
+// go test -v -test.run TestBindParameters ...restful
+func TestBindParameters(t *testing.T) {
+	var params orderParameters
+	params.Size = 20
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		if err := req.BindParameters(&params); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	c := NewContainer()
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/orders/42?page=3&tag=a&tag=b&since=2015-06-01T12:00:00Z&timeout=2s&ratio=0.5&debug=true&ignored=x", nil)
+	httpRequest.Header.Set("X-Tenant", "acme")
+	httpRequest.Header.Set("X-Origin", "192.0.2.1")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if params.ID != 42 || params.Page != 3 || params.Size != 20 {
+		t.Errorf("got %+v", params)
+	}
+	if len(params.Tags) != 2 || params.Tags[1] != "b" {
+		t.Errorf("got tags %v", params.Tags)
+	}
+	if params.Since == nil || params.Since.Month() != time.June {
+		t.Errorf("got since %v", params.Since)
+	}
+	if params.Timeout != 2*time.Second || params.Ratio != 0.5 || !params.Debug {
+		t.Errorf("got %+v", params)
+	}
+	if params.Tenant != "acme" || params.Origin.String() != "192.0.2.1" || params.ignored != "" {
+		t.Errorf("got %+v", params)
+	}
+}
+
+// go test -v -test.run TestBindParametersErrors ...restful
+func TestBindParametersErrors(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/orders?page=x&ratio=y", nil)
+	req := NewRequest(httpRequest)
+	var params orderParameters
+	err := req.BindParameters(&params)
+	errs, ok := err.(ParameterErrors)
+	if !ok || len(errs) != 3 {
+		t.Fatalf("got %v want 3 ParameterErrors", err)
+	}
+	if got, want := errs.Error(), `query parameter "page" has invalid value "x": invalid syntax; query parameter "ratio" has invalid value "y": invalid syntax; header parameter "X-Tenant": missing required value`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if err := req.BindParameters(params); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+}
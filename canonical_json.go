@@ -0,0 +1,190 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// NewCanonicalJSONEntityAccessor returns an EntityReaderWriter that writes JSON in the canonical
+// form of RFC 8785 (JCS) such that clients can sign or hash response bodies:
+// object keys are sorted, there is no whitespace, strings use minimal escaping and
+// numbers are formatted as IEEE 754 doubles (integers beyond 2^53 lose precision).
+// Reading is the same as for MIME_JSON.
+//
+//	restful.RegisterEntityAccessor("application/jose+json", restful.NewCanonicalJSONEntityAccessor("application/jose+json"))
+//
+// To use it for selected Routes only, set RenderOptions.CanonicalJSON using KeyRenderOptions metadata.
+func NewCanonicalJSONEntityAccessor(contentType string) EntityReaderWriter {
+	return entityCanonicalJSONAccess{entityJSONAccess{ContentType: contentType}}
+}
+
+// entityCanonicalJSONAccess is a EntityReaderWriter for canonical JSON encoding
+type entityCanonicalJSONAccess struct {
+	entityJSONAccess
+}
+
+// Write marshalls the value to canonical JSON and set the Content-Type Header.
+func (e entityCanonicalJSONAccess) Write(resp *Response, status int, v interface{}) error {
+	return writeCanonicalJSON(resp, status, e.ContentType, v)
+}
+
+// writeCanonicalJSON marshalls the value to canonical JSON and set the Content-Type Header.
+func writeCanonicalJSON(resp *Response, status int, contentType string, v interface{}) error {
+	if v == nil {
+		resp.WriteHeader(status)
+		// do not write a nil representation
+		return nil
+	}
+	if fields := resp.sparseFields(status); len(fields) > 0 {
+		pruned, err := pruneJSON(v, fields)
+		if err != nil {
+			return err
+		}
+		v = pruned
+	}
+	output, err := CanonicalJSON(v)
+	if err != nil {
+		return err
+	}
+	resp.Header().Set(HEADER_ContentType, contentType)
+	resp.WriteHeader(status)
+	_, err = resp.Write(output)
+	return err
+}
+
+// CanonicalJSON returns the canonical (RFC 8785) JSON encoding of the value.
+// The value is first marshalled using encoding/json, so json tags and Marshaler implementations are respected.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	buffer := new(bytes.Buffer)
+	if err := writeCanonical(buffer, document); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func writeCanonical(buffer *bytes.Buffer, document interface{}) error {
+	switch value := document.(type) {
+	case nil:
+		buffer.WriteString("null")
+	case bool:
+		buffer.WriteString(strconv.FormatBool(value))
+	case string:
+		writeCanonicalString(buffer, value)
+	case json.Number:
+		f, err := value.Float64()
+		if err != nil {
+			return err
+		}
+		// encoding/json formats float64 values like ECMAScript, as required by RFC 8785
+		number, err := json.Marshal(f)
+		if err != nil {
+			return err
+		}
+		buffer.Write(number)
+	case []interface{}:
+		buffer.WriteByte('[')
+		for i, each := range value {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if err := writeCanonical(buffer, each); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		// keys are sorted by their UTF-16 code units
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
+		buffer.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			writeCanonicalString(buffer, k)
+			buffer.WriteByte(':')
+			if err := writeCanonical(buffer, value[k]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", document)
+	}
+	return nil
+}
+
+// writeCanonicalString writes the string with only the escaping that JSON requires.
+func writeCanonicalString(buffer *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+	buffer.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			buffer.WriteString(`\"`)
+		case r == '\\':
+			buffer.WriteString(`\\`)
+		case r == '\b':
+			buffer.WriteString(`\b`)
+		case r == '\t':
+			buffer.WriteString(`\t`)
+		case r == '\n':
+			buffer.WriteString(`\n`)
+		case r == '\f':
+			buffer.WriteString(`\f`)
+		case r == '\r':
+			buffer.WriteString(`\r`)
+		case r < 0x20:
+			buffer.WriteString(`\u00`)
+			buffer.WriteByte(hex[r>>4])
+			buffer.WriteByte(hex[r&0xF])
+		default:
+			buffer.WriteRune(r)
+		}
+	}
+	buffer.WriteByte('"')
+}
+
+func lessUTF16(a, b string) bool {
+	if isASCII(a) && isASCII(b) {
+		return a < b
+	}
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestCanonicalJSON ...restful
+func TestCanonicalJSON(t *testing.T) {
+	value := struct {
+		Zebra  string                 `json:"zebra"`
+		Alpha  []interface{}          `json:"alpha"`
+		Nested map[string]interface{} `json:"nested"`
+	}{
+		Zebra:  "<tag> & \u2028 \"q\"\n",
+		Alpha:  []interface{}{1.0, 1e21, 0.000001, 1e-7, -0.5, true, nil},
+		Nested: map[string]interface{}{"\uFB01": 1, "\U0001F600": 2, "b": 3},
+	}
+	// U+2028 is not escaped, unlike by encoding/json
+	// U+FB01 sorts after U+1F600 by UTF-16 code units, before it by UTF-8 bytes
+	output, err := CanonicalJSON(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"alpha":[1,1e+21,0.000001,1e-7,-0.5,true,null],"nested":{"b":3,"` + "\U0001F600" + `":2,"` + "\uFB01" + `":1},"zebra":"<tag> & ` + "\u2028" + ` \"q\"\n"}`
+	if got := string(output); got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func TestCanonicalJSON_RenderOptions(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService).Path("/signed")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(map[string]int{"b": 2, "a": 1})
+	}).Produces(MIME_JSON).Metadata(KeyRenderOptions, func(o *RenderOptions) { o.CanonicalJSON = true }))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/signed", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), `{"a":1,"b":2}`; got != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
@@ -0,0 +1,106 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net"
+	"net/textproto"
+	"strings"
+)
+
+const (
+	HEADER_XForwardedFor = "X-Forwarded-For"
+	HEADER_Forwarded     = "Forwarded" // RFC 7239
+)
+
+// ClientIPPolicy tells how to find the IP address of the client of a request that has passed through proxies.
+// Addresses added to the forwarding header by the trusted proxies are skipped from right to left ;
+// the first address that is not trusted is the client. Headers sent by untrusted peers are ignored.
+//
+//	proxies, _ := restful.ParseNetworks("10.0.0.0/8")
+//	container.TrustProxies(restful.ClientIPPolicy{TrustedProxies: proxies})
+type ClientIPPolicy struct {
+	TrustedProxies []*net.IPNet
+	// Header is HEADER_XForwardedFor (default), HEADER_Forwarded or a header with a single address, e.g. "X-Real-IP".
+	Header string
+}
+
+// TrustProxies sets the policy used by Request.ClientIP. Without it, the remote address of the connection is the client.
+func (c *Container) TrustProxies(policy ClientIPPolicy) {
+	c.clientIPPolicy = &policy
+}
+
+// ClientIP returns the IP address of the client that sent the request. It takes forwarding headers
+// into account only if the request was dispatched by a Container with a ClientIPPolicy (see TrustProxies).
+// It returns nil if the address cannot be parsed.
+func (r *Request) ClientIP() net.IP {
+	remote := parseHostIP(r.Request.RemoteAddr)
+	policy := r.clientIPPolicy
+	if policy == nil || remote == nil || !containsIP(policy.TrustedProxies, remote) {
+		return remote
+	}
+	forwarded := policy.forwardedFor(r.Request.Header)
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := parseHostIP(forwarded[i])
+		if ip == nil {
+			// a malformed entry cannot be trusted to be the client nor skipped
+			return remote
+		}
+		remote = ip
+		if !containsIP(policy.TrustedProxies, ip) {
+			return ip
+		}
+	}
+	return remote
+}
+
+// forwardedFor returns the addresses from the forwarding header, client first.
+func (p ClientIPPolicy) forwardedFor(header map[string][]string) (addresses []string) {
+	name := p.Header
+	if len(name) == 0 {
+		name = HEADER_XForwardedFor
+	}
+	for _, line := range header[textproto.CanonicalMIMEHeaderKey(name)] {
+		for _, each := range strings.Split(line, ",") {
+			each = strings.TrimSpace(each)
+			if name == HEADER_Forwarded {
+				each = forwardedForParameter(each)
+			}
+			if len(each) > 0 {
+				addresses = append(addresses, each)
+			}
+		}
+	}
+	return
+}
+
+// forwardedForParameter returns the value of the for= parameter of a Forwarded element, e.g. for="[2001:db8::1]:4711";proto=https
+func forwardedForParameter(element string) string {
+	for _, pair := range strings.Split(element, ";") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) > 4 && strings.EqualFold(pair[:4], "for=") {
+			return strings.Trim(pair[4:], `"`)
+		}
+	}
+	return ""
+}
+
+// parseHostIP parses an address with or without port, e.g. 192.0.2.1, [2001:db8::1]:80
+func parseHostIP(address string) net.IP {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	return net.ParseIP(strings.Trim(address, "[]"))
+}
+
+// containsIP returns true if any of the networks contains the ip.
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, each := range networks {
+		if each.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
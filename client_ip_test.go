@@ -0,0 +1,38 @@
+package restful
+
+import (
+	"net/http"
+	"testing"
+)
+
+// go test -v -test.run TestClientIP ...restful
+func TestClientIP(t *testing.T) {
+	proxies, _ := ParseNetworks("10.0.0.0/8", "2001:db8::/32")
+	for i, each := range []struct {
+		policy     *ClientIPPolicy
+		remoteAddr string
+		header     string
+		value      string
+		want       string
+	}{
+		{nil, "192.0.2.1:1234", HEADER_XForwardedFor, "198.51.100.1", "192.0.2.1"},
+		{&ClientIPPolicy{TrustedProxies: proxies}, "192.0.2.1:1234", HEADER_XForwardedFor, "198.51.100.1", "192.0.2.1"},
+		{&ClientIPPolicy{TrustedProxies: proxies}, "10.0.0.1:1234", HEADER_XForwardedFor, "203.0.113.9, 198.51.100.1, 10.0.0.2", "198.51.100.1"},
+		{&ClientIPPolicy{TrustedProxies: proxies}, "10.0.0.1:1234", HEADER_XForwardedFor, "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{&ClientIPPolicy{TrustedProxies: proxies}, "10.0.0.1:1234", HEADER_XForwardedFor, "bogus, 10.0.0.2", "10.0.0.2"},
+		{&ClientIPPolicy{TrustedProxies: proxies}, "10.0.0.1:1234", "", "", "10.0.0.1"},
+		{&ClientIPPolicy{TrustedProxies: proxies, Header: HEADER_Forwarded}, "[2001:db8::1]:443", HEADER_Forwarded, `for="[2001:db9::7]:4711";proto=https, for=10.0.0.2`, "2001:db9::7"},
+		{&ClientIPPolicy{TrustedProxies: proxies, Header: "X-Real-IP"}, "10.0.0.1:1234", "X-Real-IP", "198.51.100.7", "198.51.100.7"},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.RemoteAddr = each.remoteAddr
+		if len(each.header) > 0 {
+			httpRequest.Header.Set(each.header, each.value)
+		}
+		req := NewRequest(httpRequest)
+		req.clientIPPolicy = each.policy
+		if got := req.ClientIP().String(); got != each.want {
+			t.Errorf("[%d] got %v want %v", i, got, each.want)
+		}
+	}
+}
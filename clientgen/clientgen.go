@@ -0,0 +1,536 @@
+// Package clientgen generates a typed Go client package, and curl examples, from the Routes of WebServices.
+//
+// Because the client is generated from the same Routes, parameters and Reads/Writes samples that the
+// Container dispatches, server and client stay in lockstep without an external swagger-codegen toolchain.
+// A test or a small program of the service can regenerate the client:
+//
+//	source, err := clientgen.Generate(clientgen.Config{Package: "usersclient"}, container.RegisteredWebServices())
+//	...
+//	os.WriteFile("usersclient/client.go", source, 0644)
+//
+// For each documented Route, the Client has a method named after its Operation (or its method and path) with:
+//   - a context and a parameter for each path parameter and each required query and header parameter ;
+//   - the body, of the type of the ReadSample, if any ;
+//   - an Options struct for the optional query and header parameters, if any.
+//
+// It returns the decoded WriteSample type, if any. Responses with a status of 400 or higher are returned as *Error.
+// Form parameters are not supported. The types of the samples are generated with the same fields and tags.
+package clientgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Config describes the generated client.
+type Config struct {
+	// Package is the name of the package of the generated client ; default is "client".
+	Package string
+	// BaseURL is used in the curl examples ; default is http://localhost:8080.
+	BaseURL string
+}
+
+// Generate returns the Go source of a client package for the documented Routes of the WebServices.
+func Generate(config Config, webServices []*restful.WebService) ([]byte, error) {
+	if len(config.Package) == 0 {
+		config.Package = "client"
+	}
+	types := newTypeWriter()
+	var methods bytes.Buffer
+	names := map[string]bool{}
+	for _, ws := range webServices {
+		for _, route := range ws.DocumentedRoutes() {
+			name := methodName(route)
+			for suffix := 2; names[name]; suffix++ {
+				name = methodName(route) + strconv.Itoa(suffix)
+			}
+			names[name] = true
+			writeMethod(&methods, types, name, route)
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by clientgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "// Package %s is a client of the service.\n", config.Package)
+	fmt.Fprintf(&out, "package %s\n\n", config.Package)
+	imports := []string{"bytes", "context", "encoding/json", "encoding/xml", "fmt", "io", "net/http", "net/url", "strings"}
+	if types.usesTime {
+		imports = append(imports, "time")
+	}
+	sort.Strings(imports)
+	fmt.Fprintf(&out, "import (\n")
+	for _, each := range imports {
+		fmt.Fprintf(&out, "%q\n", each)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	out.WriteString(clientSource)
+	for _, each := range types.declarations {
+		out.WriteString(each)
+	}
+	out.Write(methods.Bytes())
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("clientgen: invalid source: %v", err)
+	}
+	return source, nil
+}
+
+// clientSource is the part of the client that does not depend on the Routes.
+const clientSource = `// Client calls the operations of the service.
+type Client struct {
+	// BaseURL is the scheme, host and port of the service, e.g. http://localhost:8080.
+	BaseURL string
+	// HTTPClient is used to send requests ; default is http.DefaultClient.
+	HTTPClient *http.Client
+	// Header is added to each request, e.g. for authorization.
+	Header http.Header
+}
+
+// New returns a Client for the service at the baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: baseURL, Header: http.Header{}}
+}
+
+// Error is returned for a response with a status of 400 or higher.
+type Error struct {
+	Status int
+	Body   []byte
+}
+
+// Error returns the status and body.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), strings.TrimSpace(string(e.Body)))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body interface{}, contentType, accept string, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		var data []byte
+		var err error
+		if strings.Contains(contentType, "xml") {
+			data, err = xml.Marshal(body)
+		} else {
+			data, err = json.Marshal(body)
+		}
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := strings.TrimRight(c.BaseURL, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for name, values := range c.Header {
+		req.Header[name] = values
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", accept)
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 400 {
+		return &Error{Status: resp.StatusCode, Body: data}
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if strings.Contains(resp.Header.Get("Content-Type"), "xml") {
+		return xml.Unmarshal(data, result)
+	}
+	return json.Unmarshal(data, result)
+}
+
+`
+
+// anonymousFunction matches the Operation that a RouteBuilder derives from a function literal.
+var anonymousFunction = regexp.MustCompile(`^func\d+$`)
+
+// methodName returns the name of the Client method for a Route.
+func methodName(route restful.Route) string {
+	if len(route.Operation) > 0 && !anonymousFunction.MatchString(route.Operation) {
+		return goIdentifier(route.Operation, true)
+	}
+	return goIdentifier(strings.ToLower(route.Method)+" "+route.Path, true)
+}
+
+var pathParameterPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// argument is a parameter of a generated method.
+type argument struct {
+	name     string // Go name
+	goType   string
+	data     restful.ParameterData
+	wildcard bool // a path parameter that matches the rest of the path, e.g. {subpath:*}
+}
+
+// writeMethod writes the Client method, and its Options struct if needed, for a Route.
+func writeMethod(out *bytes.Buffer, types *typeWriter, name string, route restful.Route) {
+	documented := map[string]restful.ParameterData{}
+	for _, each := range route.ParameterDocs {
+		data := each.Data()
+		documented[fmt.Sprintf("%d/%s", data.Kind, data.Name)] = data
+	}
+	used := map[string]bool{"ctx": true, "options": true, "body": true, "query": true, "header": true, "result": true, "err": true}
+	arguments := []argument{}
+	newArgument := func(data restful.ParameterData) argument {
+		goName := goIdentifier(data.Name, false)
+		for used[goName] || isReserved(goName) {
+			goName += "Param"
+		}
+		used[goName] = true
+		return argument{name: goName, goType: parameterType(data.DataType), data: data}
+	}
+	// path parameters in order of the path
+	for _, match := range pathParameterPattern.FindAllStringSubmatch(route.Path, -1) {
+		paramName := strings.TrimSpace(match[1])
+		data, ok := documented[fmt.Sprintf("%d/%s", restful.PathParameterKind, paramName)]
+		if !ok {
+			data = restful.ParameterData{Name: paramName, Kind: restful.PathParameterKind, DataType: "string"}
+		}
+		arg := newArgument(data)
+		arg.wildcard = strings.TrimSpace(match[2]) == ":*"
+		arguments = append(arguments, arg)
+	}
+	options := []argument{}
+	for _, each := range route.ParameterDocs {
+		data := each.Data()
+		if data.Kind != restful.QueryParameterKind && data.Kind != restful.HeaderParameterKind {
+			continue
+		}
+		if data.Required {
+			arguments = append(arguments, newArgument(data))
+		} else {
+			arg := argument{name: goIdentifier(data.Name, true), goType: parameterType(data.DataType), data: data}
+			if arg.goType != "string" {
+				arg.goType = "*" + arg.goType
+			}
+			options = append(options, arg)
+		}
+	}
+	bodyType := ""
+	if route.ReadSample != nil {
+		bodyType = types.expr(reflect.TypeOf(route.ReadSample))
+	}
+	resultType := ""
+	if route.WriteSample != nil {
+		resultType = types.expr(reflect.TypeOf(route.WriteSample))
+	}
+
+	if len(options) > 0 {
+		fmt.Fprintf(out, "// %sOptions has the optional parameters of %s ; zero values are not sent.\n", name, name)
+		fmt.Fprintf(out, "type %sOptions struct {\n", name)
+		for _, each := range options {
+			if len(each.data.Description) > 0 {
+				fmt.Fprintf(out, "// %s %s\n", each.name, oneLine(each.data.Description))
+			}
+			fmt.Fprintf(out, "%s %s\n", each.name, each.goType)
+		}
+		fmt.Fprintf(out, "}\n\n")
+	}
+
+	signature := []string{"ctx context.Context"}
+	for _, each := range arguments {
+		signature = append(signature, each.name+" "+each.goType)
+	}
+	if len(bodyType) > 0 {
+		signature = append(signature, "body "+bodyType)
+	}
+	if len(options) > 0 {
+		signature = append(signature, "options *"+name+"Options")
+	}
+	returns := "error"
+	if len(resultType) > 0 {
+		returns = "(" + resultPointer(resultType) + ", error)"
+	}
+	comment := fmt.Sprintf("%s calls %s %s", name, route.Method, route.Path)
+	if doc := oneLine(route.Doc); len(doc) > 0 {
+		comment += ": " + doc
+	}
+	fmt.Fprintf(out, "// %s\n", comment)
+	fmt.Fprintf(out, "func (c *Client) %s(%s) %s {\n", name, strings.Join(signature, ", "), returns)
+	fmt.Fprintf(out, "query := url.Values{}\n")
+	fmt.Fprintf(out, "header := http.Header{}\n")
+	for _, each := range arguments {
+		switch each.data.Kind {
+		case restful.QueryParameterKind:
+			fmt.Fprintf(out, "query.Set(%q, %s)\n", each.data.Name, formatted(each.name, each.goType))
+		case restful.HeaderParameterKind:
+			fmt.Fprintf(out, "header.Set(%q, %s)\n", each.data.Name, formatted(each.name, each.goType))
+		}
+	}
+	if len(options) > 0 {
+		fmt.Fprintf(out, "if options != nil {\n")
+		for _, each := range options {
+			target := "query"
+			if each.data.Kind == restful.HeaderParameterKind {
+				target = "header"
+			}
+			if each.goType == "string" {
+				fmt.Fprintf(out, "if len(options.%s) > 0 {\n%s.Set(%q, options.%s)\n}\n", each.name, target, each.data.Name, each.name)
+			} else {
+				fmt.Fprintf(out, "if options.%s != nil {\n%s.Set(%q, fmt.Sprint(*options.%s))\n}\n", each.name, target, each.data.Name, each.name)
+			}
+		}
+		fmt.Fprintf(out, "}\n")
+	}
+	bodyArgument, contentType := "nil", ""
+	if len(bodyType) > 0 {
+		bodyArgument, contentType = "body", mediaType(route.Consumes)
+		if len(contentType) == 0 {
+			contentType = restful.MIME_JSON
+		}
+	}
+	resultArgument := "nil"
+	if len(resultType) > 0 {
+		fmt.Fprintf(out, "var result %s\n", resultType)
+		resultArgument = "&result"
+	}
+	call := fmt.Sprintf("c.do(ctx, %q, %s, query, header, %s, %q, %q, %s)",
+		route.Method, pathExpression(route.Path, arguments), bodyArgument, contentType, mediaType(route.Produces), resultArgument)
+	if len(resultType) == 0 {
+		fmt.Fprintf(out, "return %s\n}\n\n", call)
+		return
+	}
+	fmt.Fprintf(out, "if err := %s; err != nil {\nreturn nil, err\n}\n", call)
+	if resultPointer(resultType) == resultType {
+		fmt.Fprintf(out, "return result, nil\n}\n\n")
+	} else {
+		fmt.Fprintf(out, "return &result, nil\n}\n\n")
+	}
+}
+
+// resultPointer returns the type that a method returns for a WriteSample of the type ;
+// slices, maps and pointers are returned as is, other types by pointer.
+func resultPointer(goType string) string {
+	if strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[") || strings.HasPrefix(goType, "*") {
+		return goType
+	}
+	return "*" + goType
+}
+
+// pathExpression returns a Go expression that builds the path with the values of the path parameters.
+func pathExpression(path string, arguments []argument) string {
+	parts := []string{}
+	rest := path
+	index := 0
+	for _, match := range pathParameterPattern.FindAllStringIndex(path, -1) {
+		offset := len(path) - len(rest)
+		if literal := rest[:match[0]-offset]; len(literal) > 0 {
+			parts = append(parts, strconv.Quote(literal))
+		}
+		arg := arguments[index]
+		index++
+		value := formatted(arg.name, arg.goType)
+		if arg.wildcard {
+			parts = append(parts, value)
+		} else {
+			parts = append(parts, "url.PathEscape("+value+")")
+		}
+		rest = path[match[1]:]
+	}
+	if len(rest) > 0 || len(parts) == 0 {
+		parts = append(parts, strconv.Quote(rest))
+	}
+	return strings.Join(parts, "+")
+}
+
+// formatted returns a Go expression of type string for the value.
+func formatted(name, goType string) string {
+	if goType == "string" {
+		return name
+	}
+	return "fmt.Sprint(" + name + ")"
+}
+
+// parameterType returns the Go type of a parameter with the DataType.
+func parameterType(dataType string) string {
+	switch dataType {
+	case "integer", "int", "int32", "int64":
+		return "int64"
+	case "number", "float", "double":
+		return "float64"
+	case "boolean", "bool":
+		return "bool"
+	}
+	return "string"
+}
+
+// mediaType returns the first MIME type that is not */*, or empty.
+func mediaType(mimes []string) string {
+	for _, each := range mimes {
+		if each != "*/*" {
+			return each
+		}
+	}
+	return ""
+}
+
+// goIdentifier returns a Go identifier for a text, e.g. "getUser" -> "GetUser", "get /users/{id}" -> "GetUsersId".
+// The first letter is uppercase if exported.
+func goIdentifier(text string, exported bool) string {
+	var identifier strings.Builder
+	upper := exported
+	for _, each := range text {
+		if !unicode.IsLetter(each) && !unicode.IsDigit(each) {
+			upper = identifier.Len() > 0 || exported
+			continue
+		}
+		if identifier.Len() == 0 && unicode.IsDigit(each) {
+			if exported {
+				identifier.WriteRune('N')
+			} else {
+				identifier.WriteRune('n')
+			}
+		}
+		if upper {
+			each = unicode.ToUpper(each)
+			upper = false
+		} else if identifier.Len() == 0 {
+			each = unicode.ToLower(each)
+		}
+		identifier.WriteRune(each)
+	}
+	if identifier.Len() == 0 {
+		return "Value"
+	}
+	return identifier.String()
+}
+
+// isReserved returns whether the name is a Go keyword or is used by the generated methods, e.g. an imported package.
+func isReserved(name string) bool {
+	switch name {
+	case "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func",
+		"go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var",
+		"c", "bytes", "context", "json", "xml", "fmt", "io", "http", "url", "strings", "time":
+		return true
+	}
+	return false
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// typeWriter declares Go types with the same fields and tags as the types of the samples.
+type typeWriter struct {
+	names        map[reflect.Type]string
+	used         map[string]bool
+	declarations []string
+	usesTime     bool
+}
+
+func newTypeWriter() *typeWriter {
+	return &typeWriter{
+		names: map[reflect.Type]string{},
+		used:  map[string]bool{"Client": true, "Error": true, "New": true},
+	}
+}
+
+// expr returns the Go type expression for the type, declaring named types on first use.
+func (w *typeWriter) expr(t reflect.Type) string {
+	if t == timeType {
+		w.usesTime = true
+		return "time.Time"
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + w.expr(t.Elem())
+	case reflect.Slice:
+		return "[]" + w.expr(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), w.expr(t.Elem()))
+	case reflect.Map:
+		return "map[" + w.expr(t.Key()) + "]" + w.expr(t.Elem())
+	case reflect.Interface, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return "interface{}"
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return w.structBody(t)
+		}
+		return w.named(t, w.structBody)
+	}
+	if len(t.Name()) > 0 && len(t.PkgPath()) > 0 {
+		return w.named(t, func(t reflect.Type) string { return t.Kind().String() })
+	}
+	return t.Kind().String()
+}
+
+// named returns the name of the declaration for a named type ; the declaration is added on first use.
+func (w *typeWriter) named(t reflect.Type, underlying func(reflect.Type) string) string {
+	if name, ok := w.names[t]; ok {
+		return name
+	}
+	name := goIdentifier(t.Name(), true)
+	for suffix := 2; w.used[name]; suffix++ {
+		name = goIdentifier(t.Name(), true) + strconv.Itoa(suffix)
+	}
+	w.used[name] = true
+	w.names[t] = name // before the underlying type, for recursive types
+	declaration := underlying(t)
+	w.declarations = append(w.declarations, fmt.Sprintf("// %s is generated from %s.\ntype %s %s\n\n", name, t.String(), name, declaration))
+	return name
+}
+
+// structBody returns the struct type expression with the exported fields and their json and xml tags.
+func (w *typeWriter) structBody(t reflect.Type) string {
+	var body strings.Builder
+	body.WriteString("struct {\n")
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		tags := []string{}
+		for _, key := range []string{"json", "xml"} {
+			if value, ok := field.Tag.Lookup(key); ok {
+				tags = append(tags, key+":"+strconv.Quote(value))
+			}
+		}
+		tag := ""
+		if len(tags) > 0 {
+			tag = " `" + strings.Join(tags, " ") + "`"
+		}
+		if field.Anonymous {
+			fmt.Fprintf(&body, "%s%s\n", w.expr(field.Type), tag)
+		} else {
+			fmt.Fprintf(&body, "%s %s%s\n", field.Name, w.expr(field.Type), tag)
+		}
+	}
+	body.WriteString("}")
+	return body.String()
+}
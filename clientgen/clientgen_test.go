@@ -0,0 +1,115 @@
+package clientgen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+type clientgenAddress struct {
+	Street string `json:"street"`
+}
+
+type clientgenUser struct {
+	ID      int               `json:"id"`
+	Name    string            `json:"name" xml:"name,attr"`
+	Created time.Time         `json:"created"`
+	Address *clientgenAddress `json:"address,omitempty"`
+	secret  string
+}
+
+func newClientgenWebService() *restful.WebService {
+	noop := func(req *restful.Request, resp *restful.Response) {}
+	ws := new(restful.WebService).Path("/users").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/{user-id}").To(noop).Operation("getUser").Doc("get a user").
+		Param(ws.PathParameter("user-id", "identifier").DataType("integer").DefaultValue("1")).
+		Param(ws.HeaderParameter("X-Tenant", "tenant").Required(true)).
+		Param(ws.QueryParameter("expand", "expand the address").DataType("boolean")).
+		Writes(clientgenUser{}))
+	ws.Route(ws.GET("").To(noop).Operation("listUsers").
+		Param(ws.QueryParameter("type", "kind of user").Required(true)).
+		Writes([]clientgenUser{}))
+	ws.Route(ws.POST("").To(noop).Operation("createUser").Reads(clientgenUser{}).Writes(clientgenUser{}))
+	ws.Route(ws.DELETE("/{user-id}").To(noop))
+	ws.Route(ws.GET("/internal").To(noop).Metadata(restful.KeyExcludeFromDocumentation, true))
+	return ws
+}
+
+// go test -v -test.run TestGenerate ...clientgen
+func TestGenerate(t *testing.T) {
+	source, err := Generate(Config{Package: "usersclient"}, []*restful.WebService{newClientgenWebService()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "client.go", source, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, source)
+	}
+	text := string(source)
+	for _, want := range []string{
+		"package usersclient",
+		`"time"`,
+		"func (c *Client) GetUser(ctx context.Context, userId int64, xTenant string, options *GetUserOptions) (*ClientgenUser, error)",
+		"Expand *bool",
+		`header.Set("X-Tenant", xTenant)`,
+		`"/users/"+url.PathEscape(fmt.Sprint(userId))`,
+		"func (c *Client) ListUsers(ctx context.Context, typeParam string) ([]ClientgenUser, error)",
+		"func (c *Client) CreateUser(ctx context.Context, body ClientgenUser) (*ClientgenUser, error)",
+		"func (c *Client) DeleteUsersUserId(ctx context.Context, userId string) error",
+		"Name    string            `json:\"name\" xml:\"name,attr\"`",
+		"Created time.Time",
+		"type ClientgenAddress struct",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("got source without %q\n%s", want, text)
+		}
+	}
+	for _, unwanted := range []string{"secret", "Internal"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("got source with %q", unwanted)
+		}
+	}
+}
+
+// go test -v -test.run TestGenerateTypeChecks ...clientgen
+func TestGenerateTypeChecks(t *testing.T) {
+	source, err := Generate(Config{Package: "usersclient"}, []*restful.WebService{newClientgenWebService()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "client.go", source, 0)
+	if err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, source)
+	}
+	config := types.Config{Importer: importer.Default()}
+	if _, err := config.Check("usersclient", fset, []*ast.File{file}, nil); err != nil {
+		t.Fatalf("generated source does not type-check: %v\n%s", err, source)
+	}
+}
+
+// go test -v -test.run TestCurl ...clientgen
+func TestCurl(t *testing.T) {
+	script := string(Curl(Config{}, []*restful.WebService{newClientgenWebService()}))
+	for _, want := range []string{
+		"# GET /users/{user-id}: get a user\ncurl -X GET 'http://localhost:8080/users/1'",
+		"-H 'X-Tenant: <X-Tenant>'",
+		"curl -X GET 'http://localhost:8080/users/?type=<type>'",
+		"-H 'Content-Type: application/json'",
+		`-d '{"id":0,"name":"","created":"0001-01-01T00:00:00Z"}'`,
+		"curl -X DELETE 'http://localhost:8080/users/<user-id>'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("got script without %q\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "/internal") {
+		t.Error("got excluded route")
+	}
+}
@@ -0,0 +1,93 @@
+package clientgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Curl returns a shell script with a curl command for each documented Route of the WebServices.
+// Path parameters are their DefaultValue or else a <name> placeholder ; required query and header
+// parameters are included likewise. The body is the JSON of the ReadSample, if any.
+func Curl(config Config, webServices []*restful.WebService) []byte {
+	if len(config.BaseURL) == 0 {
+		config.BaseURL = "http://localhost:8080"
+	}
+	var out bytes.Buffer
+	for _, ws := range webServices {
+		for _, route := range ws.DocumentedRoutes() {
+			writeCurl(&out, strings.TrimRight(config.BaseURL, "/"), route)
+		}
+	}
+	return out.Bytes()
+}
+
+func writeCurl(out *bytes.Buffer, baseURL string, route restful.Route) {
+	documented := map[string]restful.ParameterData{}
+	for _, each := range route.ParameterDocs {
+		data := each.Data()
+		documented[fmt.Sprintf("%d/%s", data.Kind, data.Name)] = data
+	}
+	path := pathParameterPattern.ReplaceAllStringFunc(route.Path, func(match string) string {
+		name := strings.TrimSpace(pathParameterPattern.FindStringSubmatch(match)[1])
+		return exampleValue(documented[fmt.Sprintf("%d/%s", restful.PathParameterKind, name)], name)
+	})
+	query := []string{}
+	headers := []string{}
+	for _, each := range route.ParameterDocs {
+		data := each.Data()
+		if !data.Required {
+			continue
+		}
+		switch data.Kind {
+		case restful.QueryParameterKind:
+			query = append(query, url.QueryEscape(data.Name)+"="+exampleValue(data, data.Name))
+		case restful.HeaderParameterKind:
+			headers = append(headers, data.Name+": "+exampleValue(data, data.Name))
+		}
+	}
+	target := baseURL + path
+	if len(query) > 0 {
+		target += "?" + strings.Join(query, "&")
+	}
+	comment := route.Method + " " + route.Path
+	if doc := oneLine(route.Doc); len(doc) > 0 {
+		comment += ": " + doc
+	}
+	fmt.Fprintf(out, "# %s\n", comment)
+	fmt.Fprintf(out, "curl -X %s %s", route.Method, shellQuote(target))
+	if accept := mediaType(route.Produces); len(accept) > 0 {
+		fmt.Fprintf(out, " \\\n  -H %s", shellQuote(restful.HEADER_Accept+": "+accept))
+	}
+	for _, each := range headers {
+		fmt.Fprintf(out, " \\\n  -H %s", shellQuote(each))
+	}
+	if route.ReadSample != nil {
+		contentType := mediaType(route.Consumes)
+		if len(contentType) == 0 {
+			contentType = restful.MIME_JSON
+		}
+		fmt.Fprintf(out, " \\\n  -H %s", shellQuote(restful.HEADER_ContentType+": "+contentType))
+		if data, err := json.Marshal(route.ReadSample); err == nil {
+			fmt.Fprintf(out, " \\\n  -d %s", shellQuote(string(data)))
+		}
+	}
+	fmt.Fprintf(out, "\n\n")
+}
+
+// exampleValue returns the DefaultValue of the parameter or else a <name> placeholder.
+func exampleValue(data restful.ParameterData, name string) string {
+	if len(data.DefaultValue) > 0 {
+		return data.DefaultValue
+	}
+	return "<" + name + ">"
+}
+
+// shellQuote returns the text in single quotes for a POSIX shell.
+func shellQuote(text string) string {
+	return "'" + strings.Replace(text, "'", `'\''`, -1) + "'"
+}
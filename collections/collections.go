@@ -0,0 +1,157 @@
+// Package collections exports the Routes of WebServices as a Postman collection (v2.1) or an Insomnia export (v4)
+// such that teams using those tools can call the API without writing requests by hand.
+//
+// Requests are grouped per WebService. Path parameters become variables, documented query parameters are
+// added disabled, the ReadSample of a Route becomes the JSON body and the Auth of the Config is applied to all requests.
+//
+//	config := collections.Config{Name: "Users API", BaseURL: "http://localhost:8080", Auth: &collections.Auth{Type: collections.BearerAuth}}
+//	collections.WritePostman(file, config, restful.RegisteredWebServices())
+//
+// Or serve them:
+//
+//	collections.RegisterWebService(config, restful.DefaultContainer) // GET /collections/postman.json and /collections/insomnia.json
+package collections
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Auth types
+const (
+	BearerAuth = "bearer"
+	BasicAuth  = "basic"
+	APIKeyAuth = "apikey"
+)
+
+// Config describes the collection.
+type Config struct {
+	Name    string
+	BaseURL string // value of the baseUrl variable, e.g. http://localhost:8080
+	Auth    *Auth  // can be nil
+	// ApiPath is the root path of the WebService created by RegisterWebService ; default is "/collections".
+	ApiPath string
+}
+
+// Auth describes how requests are authenticated. Credentials are variables (token, username, password, apiKey)
+// to be filled in by the user.
+type Auth struct {
+	Type string // BearerAuth, BasicAuth or APIKeyAuth
+	// KeyName is the name of the header or query parameter for APIKeyAuth
+	KeyName string
+	// InQuery is true if the API key is passed as query parameter instead of header
+	InQuery bool
+}
+
+// credentialVariables returns the names of the variables that hold the credentials.
+func (a *Auth) credentialVariables() []string {
+	if a == nil {
+		return nil
+	}
+	switch a.Type {
+	case BearerAuth:
+		return []string{"token"}
+	case BasicAuth:
+		return []string{"username", "password"}
+	case APIKeyAuth:
+		return []string{"apiKey"}
+	}
+	return nil
+}
+
+// WritePostman writes the Postman collection of the WebServices as JSON.
+func WritePostman(w io.Writer, config Config, webServices []*restful.WebService) error {
+	return writeJSON(w, Postman(config, webServices))
+}
+
+// WriteInsomnia writes the Insomnia export of the WebServices as JSON.
+func WriteInsomnia(w io.Writer, config Config, webServices []*restful.WebService) error {
+	return writeJSON(w, Insomnia(config, webServices))
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// RegisterWebService adds a WebService that serves the collections of all WebServices of the container.
+func RegisterWebService(config Config, container *restful.Container) {
+	path := config.ApiPath
+	if len(path) == 0 {
+		path = "/collections"
+	}
+	ws := new(restful.WebService).Path(path).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/postman.json").To(func(req *restful.Request, resp *restful.Response) {
+		resp.WriteEntity(Postman(config, container.RegisteredWebServices()))
+	}).Doc("Postman collection (v2.1) of all WebServices"))
+	ws.Route(ws.GET("/insomnia.json").To(func(req *restful.Request, resp *restful.Response) {
+		resp.WriteEntity(Insomnia(config, container.RegisteredWebServices()))
+	}).Doc("Insomnia export (v4) of all WebServices"))
+	container.Add(ws)
+}
+
+// requestName returns the name of a request for a Route.
+func requestName(route restful.Route) string {
+	if len(route.Doc) > 0 {
+		return route.Doc
+	}
+	if len(route.Operation) > 0 {
+		return route.Operation
+	}
+	return route.Method + " " + route.Path
+}
+
+var pathParameterPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// pathWithVariables returns the path with parameters in :name notation and the names of the parameters.
+func pathWithVariables(path string) (string, []string) {
+	names := []string{}
+	replaced := pathParameterPattern.ReplaceAllStringFunc(path, func(each string) string {
+		name := strings.TrimSpace(pathParameterPattern.FindStringSubmatch(each)[1])
+		names = append(names, name)
+		return ":" + name
+	})
+	return replaced, names
+}
+
+// parametersOfKind returns the documented parameters of a Route with the given kind.
+func parametersOfKind(route restful.Route, kind int) (params []restful.ParameterData) {
+	for _, each := range route.ParameterDocs {
+		if each.Kind() == kind {
+			params = append(params, each.Data())
+		}
+	}
+	return
+}
+
+// sampleBody returns the ReadSample of a Route as indented JSON, or empty if it has none.
+func sampleBody(route restful.Route) string {
+	if route.ReadSample == nil {
+		return ""
+	}
+	data, err := json.MarshalIndent(route.ReadSample, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// contentHeaders returns the Content-Type and Accept headers for a Route.
+func contentHeaders(route restful.Route, hasBody bool) (headers [][2]string) {
+	if hasBody {
+		contentType := restful.MIME_JSON
+		if len(route.Consumes) > 0 && route.Consumes[0] != "*/*" {
+			contentType = route.Consumes[0]
+		}
+		headers = append(headers, [2]string{restful.HEADER_ContentType, contentType})
+	}
+	if len(route.Produces) > 0 && route.Produces[0] != "*/*" {
+		headers = append(headers, [2]string{restful.HEADER_Accept, route.Produces[0]})
+	}
+	return
+}
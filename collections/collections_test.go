@@ -0,0 +1,111 @@
+package collections
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func dummy(req *restful.Request, resp *restful.Response) {}
+
+func usersService() *restful.WebService {
+	ws := new(restful.WebService).Path("/users").Produces(restful.MIME_JSON).Consumes(restful.MIME_JSON).Doc("Manage users")
+	ws.Route(ws.GET("/{id:[0-9]+}").To(dummy).Doc("Get a user").
+		Param(ws.PathParameter("id", "identifier")).
+		Param(ws.QueryParameter("fields", "fields to return")).
+		Param(ws.HeaderParameter("X-Tenant", "tenant").Required(true)))
+	ws.Route(ws.POST("").To(dummy).Operation("createUser").Reads(user{Name: "alice"}))
+	return ws
+}
+
+// go test -v -test.run TestPostman ...collections
+func TestPostman(t *testing.T) {
+	config := Config{Name: "Users", BaseURL: "http://localhost:8080", Auth: &Auth{Type: BearerAuth}}
+	collection := Postman(config, []*restful.WebService{usersService()})
+	if got, want := len(collection.Item), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	get := collection.Item[0].Item[0]
+	if got, want := get.Name, "Get a user"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := get.Request.URL.Raw, "{{baseUrl}}/users/:id"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := get.Request.URL.Query[0].Disabled, true; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := get.Request.Header[1].Key, "X-Tenant"; got != want || get.Request.Header[1].Disabled {
+		t.Errorf("got %v want enabled %v", got, want)
+	}
+	post := collection.Item[0].Item[1]
+	if got, want := post.Name, "createUser"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if post.Request.Body == nil || post.Request.Body.Raw != "{\n  \"name\": \"alice\"\n}" {
+		t.Errorf("got body %v", post.Request.Body)
+	}
+	if got, want := collection.Auth.Bearer[0].Value, "{{token}}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(collection.Variable), 2; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestInsomnia ...collections
+func TestInsomnia(t *testing.T) {
+	config := Config{Name: "Users", BaseURL: "http://localhost:8080", Auth: &Auth{Type: APIKeyAuth, KeyName: "X-API-Key"}}
+	var buffer bytes.Buffer
+	if err := WriteInsomnia(&buffer, config, []*restful.WebService{usersService()}); err != nil {
+		t.Fatal(err)
+	}
+	var export InsomniaExport
+	if err := json.Unmarshal(buffer.Bytes(), &export); err != nil {
+		t.Fatal(err)
+	}
+	// workspace, environment, folder, 2 requests
+	if got, want := len(export.Resources), 5; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	get := export.Resources[3]
+	if got, want := get.URL, "{{ _.baseUrl }}/users/:id"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := *get.ParentID, "fld_1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := get.Authentication["addTo"], "header"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := export.Resources[1].Data["apiKey"], ""; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRegisterWebService ...collections
+func TestRegisterWebService(t *testing.T) {
+	container := restful.NewContainer()
+	container.Add(usersService())
+	RegisterWebService(Config{Name: "Users"}, container)
+	httpRequest, _ := http.NewRequest("GET", "/collections/postman.json", nil)
+	httpRequest.Header.Set(restful.HEADER_Accept, restful.MIME_JSON)
+	recorder := httptest.NewRecorder()
+	container.ServeHTTP(recorder, httpRequest)
+	var collection PostmanCollection
+	if err := json.Unmarshal(recorder.Body.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	// users and collections
+	if got, want := len(collection.Item), 2; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package collections
+
+import (
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+)
+
+// InsomniaExport is an Insomnia export (format v4) ; all items are in Resources.
+type InsomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []InsomniaResource `json:"resources"`
+}
+
+// InsomniaResource is a workspace, environment, request group (folder) or request.
+type InsomniaResource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       *string                `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Data           map[string]string      `json:"data,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Headers        []InsomniaPair         `json:"headers,omitempty"`
+	Parameters     []InsomniaPair         `json:"parameters,omitempty"`
+	PathParameters []InsomniaPair         `json:"pathParameters,omitempty"`
+	Body           *InsomniaBody          `json:"body,omitempty"`
+	Authentication map[string]interface{} `json:"authentication,omitempty"`
+}
+
+// InsomniaPair is used for headers and (path) parameters.
+type InsomniaPair struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// InsomniaBody is a request body.
+type InsomniaBody struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Insomnia returns the export of the Routes of the WebServices.
+func Insomnia(config Config, webServices []*restful.WebService) *InsomniaExport {
+	export := &InsomniaExport{Type: "export", ExportFormat: 4, ExportSource: "go-restful"}
+	workspaceID := "wrk_1"
+	export.Resources = append(export.Resources, InsomniaResource{ID: workspaceID, Type: "workspace", Name: config.Name})
+	environment := map[string]string{"baseUrl": config.BaseURL}
+	for _, variable := range config.Auth.credentialVariables() {
+		environment[variable] = ""
+	}
+	export.Resources = append(export.Resources, InsomniaResource{ID: "env_1", Type: "environment", ParentID: &workspaceID, Name: "Base Environment", Data: environment})
+	authentication := insomniaAuthentication(config.Auth)
+	requestCount := 0
+	for i, ws := range webServices {
+		routes := ws.DocumentedRoutes()
+		if len(routes) == 0 {
+			continue
+		}
+		folderID := "fld_" + strconv.Itoa(i+1)
+		export.Resources = append(export.Resources, InsomniaResource{ID: folderID, Type: "request_group", ParentID: &workspaceID, Name: ws.RootPath(), Description: ws.Documentation()})
+		for _, route := range routes {
+			requestCount++
+			request := insomniaRequest(route, "req_"+strconv.Itoa(requestCount), folderID)
+			request.Authentication = authentication
+			export.Resources = append(export.Resources, request)
+		}
+	}
+	return export
+}
+
+func insomniaRequest(route restful.Route, id, folderID string) InsomniaResource {
+	path, variables := pathWithVariables(route.Path)
+	request := InsomniaResource{
+		ID:          id,
+		Type:        "request",
+		ParentID:    &folderID,
+		Name:        requestName(route),
+		Description: route.Notes,
+		Method:      route.Method,
+		URL:         "{{ _.baseUrl }}" + path,
+	}
+	for _, each := range variables {
+		request.PathParameters = append(request.PathParameters, InsomniaPair{Name: each})
+	}
+	for _, each := range parametersOfKind(route, restful.QueryParameterKind) {
+		request.Parameters = append(request.Parameters, InsomniaPair{Name: each.Name, Description: each.Description, Disabled: !each.Required})
+	}
+	body := sampleBody(route)
+	for _, each := range contentHeaders(route, len(body) > 0) {
+		request.Headers = append(request.Headers, InsomniaPair{Name: each[0], Value: each[1]})
+	}
+	for _, each := range parametersOfKind(route, restful.HeaderParameterKind) {
+		request.Headers = append(request.Headers, InsomniaPair{Name: each.Name, Description: each.Description, Disabled: !each.Required})
+	}
+	if len(body) > 0 {
+		request.Body = &InsomniaBody{MimeType: restful.MIME_JSON, Text: body}
+	}
+	return request
+}
+
+func insomniaAuthentication(auth *Auth) map[string]interface{} {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Type {
+	case BearerAuth:
+		return map[string]interface{}{"type": "bearer", "token": "{{ _.token }}"}
+	case BasicAuth:
+		return map[string]interface{}{"type": "basic", "username": "{{ _.username }}", "password": "{{ _.password }}"}
+	case APIKeyAuth:
+		addTo := "header"
+		if auth.InQuery {
+			addTo = "queryParams"
+		}
+		return map[string]interface{}{"type": "apikey", "key": auth.KeyName, "value": "{{ _.apiKey }}", "addTo": addTo}
+	}
+	return nil
+}
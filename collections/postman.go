@@ -0,0 +1,148 @@
+package collections
+
+import (
+	"strings"
+
+	"github.com/emicklei/go-restful"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// PostmanCollection is a Postman collection (format v2.1).
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// PostmanInfo holds the name of the collection.
+type PostmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// PostmanItem is either a folder (with Item) or a request.
+type PostmanItem struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Item        []PostmanItem   `json:"item,omitempty"`
+	Request     *PostmanRequest `json:"request,omitempty"`
+}
+
+// PostmanRequest is a request of a collection.
+type PostmanRequest struct {
+	Method string            `json:"method"`
+	Header []PostmanKeyValue `json:"header"`
+	URL    PostmanURL        `json:"url"`
+	Body   *PostmanBody      `json:"body,omitempty"`
+}
+
+// PostmanURL is the URL of a request ; Raw is used by Postman, the other fields by tools that import it.
+type PostmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host"`
+	Path     []string          `json:"path"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+// PostmanBody is a raw request body.
+type PostmanBody struct {
+	Mode    string                 `json:"mode"`
+	Raw     string                 `json:"raw"`
+	Options map[string]interface{} `json:"options,omitempty"`
+}
+
+// PostmanKeyValue is used for headers, query parameters and variables.
+type PostmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// PostmanAuth is the authentication of all requests of a collection.
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []PostmanKeyValue `json:"bearer,omitempty"`
+	Basic  []PostmanKeyValue `json:"basic,omitempty"`
+	APIKey []PostmanKeyValue `json:"apikey,omitempty"`
+}
+
+// Postman returns the collection of the Routes of the WebServices.
+func Postman(config Config, webServices []*restful.WebService) *PostmanCollection {
+	collection := &PostmanCollection{
+		Info:     PostmanInfo{Name: config.Name, Schema: postmanSchema},
+		Item:     []PostmanItem{},
+		Auth:     postmanAuth(config.Auth),
+		Variable: []PostmanKeyValue{{Key: "baseUrl", Value: config.BaseURL}},
+	}
+	for _, variable := range config.Auth.credentialVariables() {
+		collection.Variable = append(collection.Variable, PostmanKeyValue{Key: variable})
+	}
+	for _, ws := range webServices {
+		routes := ws.DocumentedRoutes()
+		if len(routes) == 0 {
+			continue
+		}
+		folder := PostmanItem{Name: ws.RootPath(), Description: ws.Documentation(), Item: []PostmanItem{}}
+		for _, route := range routes {
+			folder.Item = append(folder.Item, postmanItem(route))
+		}
+		collection.Item = append(collection.Item, folder)
+	}
+	return collection
+}
+
+func postmanItem(route restful.Route) PostmanItem {
+	path, variables := pathWithVariables(route.Path)
+	url := PostmanURL{
+		Raw:  "{{baseUrl}}" + path,
+		Host: []string{"{{baseUrl}}"},
+		Path: strings.Split(strings.Trim(path, "/"), "/"),
+	}
+	for _, each := range variables {
+		url.Variable = append(url.Variable, PostmanKeyValue{Key: each})
+	}
+	for _, each := range parametersOfKind(route, restful.QueryParameterKind) {
+		url.Query = append(url.Query, PostmanKeyValue{Key: each.Name, Description: each.Description, Disabled: !each.Required})
+	}
+	request := &PostmanRequest{Method: route.Method, Header: []PostmanKeyValue{}, URL: url}
+	body := sampleBody(route)
+	for _, each := range contentHeaders(route, len(body) > 0) {
+		request.Header = append(request.Header, PostmanKeyValue{Key: each[0], Value: each[1]})
+	}
+	for _, each := range parametersOfKind(route, restful.HeaderParameterKind) {
+		request.Header = append(request.Header, PostmanKeyValue{Key: each.Name, Description: each.Description, Disabled: !each.Required})
+	}
+	if len(body) > 0 {
+		request.Body = &PostmanBody{Mode: "raw", Raw: body, Options: map[string]interface{}{"raw": map[string]string{"language": "json"}}}
+	}
+	return PostmanItem{Name: requestName(route), Description: route.Notes, Request: request}
+}
+
+func postmanAuth(auth *Auth) *PostmanAuth {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Type {
+	case BearerAuth:
+		return &PostmanAuth{Type: "bearer", Bearer: []PostmanKeyValue{{Key: "token", Value: "{{token}}", Type: "string"}}}
+	case BasicAuth:
+		return &PostmanAuth{Type: "basic", Basic: []PostmanKeyValue{
+			{Key: "username", Value: "{{username}}", Type: "string"},
+			{Key: "password", Value: "{{password}}", Type: "string"}}}
+	case APIKeyAuth:
+		in := "header"
+		if auth.InQuery {
+			in = "query"
+		}
+		return &PostmanAuth{Type: "apikey", APIKey: []PostmanKeyValue{
+			{Key: "key", Value: auth.KeyName, Type: "string"},
+			{Key: "value", Value: "{{apiKey}}", Type: "string"},
+			{Key: "in", Value: in, Type: "string"}}}
+	}
+	return nil
+}
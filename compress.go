@@ -5,11 +5,14 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
+	"bufio"
 	"compress/gzip"
 	"compress/zlib"
 	"errors"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -21,6 +24,26 @@ type CompressingResponseWriter struct {
 	writer     http.ResponseWriter
 	compressor io.WriteCloser
 	encoding   string
+	counter    *countingWriter // between compressor and writer
+	closed     bool
+
+	// threshold mode, see newCompressingResponseWriter
+	threshold int    // number of bytes to buffer before deciding to compress
+	decided   bool   // whether the response is either compressed or written as is
+	status    int    // written when decided ; 0 means not written yet
+	buffered  []byte // content written before deciding
+}
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	writer io.Writer
+	count  int
+}
+
+func (w *countingWriter) Write(bytes []byte) (int, error) {
+	written, err := w.writer.Write(bytes)
+	w.count += written
+	return written, err
 }
 
 // Header is part of http.ResponseWriter interface
@@ -29,30 +52,121 @@ func (c *CompressingResponseWriter) Header() http.Header {
 }
 
 // WriteHeader is part of http.ResponseWriter interface
+// Until it is decided whether to compress, the status is kept ; informational statuses are always sent.
 func (c *CompressingResponseWriter) WriteHeader(status int) {
-	c.writer.WriteHeader(status)
+	if c.decided || status < http.StatusOK {
+		c.writer.WriteHeader(status)
+		return
+	}
+	if c.status == 0 {
+		c.status = status
+	}
 }
 
 // Write is part of http.ResponseWriter interface
-// It is passed through the compressor
+// It is passed through the compressor, if any. Until it is decided whether to compress, the bytes are buffered.
 func (c *CompressingResponseWriter) Write(bytes []byte) (int, error) {
 	if c.isCompressorClosed() {
-		return -1, errors.New("Compressing error: tried to write data using closed compressor")
+		return 0, errors.New("Compressing error: tried to write data using closed compressor")
+	}
+	if !c.decided {
+		c.buffered = append(c.buffered, bytes...)
+		if len(c.buffered) < c.threshold {
+			return len(bytes), nil
+		}
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+		return len(bytes), nil
+	}
+	if c.compressor == nil {
+		return c.counter.Write(bytes)
 	}
 	return c.compressor.Write(bytes)
 }
 
+// decide installs the compressor or not, writes the kept status and then the buffered bytes.
+// An uncompressed response gets a Content-Length unless one is set.
+func (c *CompressingResponseWriter) decide(compress bool) error {
+	c.decided = true
+	if compress {
+		c.writer.Header().Set(HEADER_ContentEncoding, c.encoding)
+		c.writer.Header().Del(HEADER_ContentLength)
+		c.acquireCompressor()
+	} else if len(c.writer.Header().Get(HEADER_ContentLength)) == 0 {
+		c.writer.Header().Set(HEADER_ContentLength, strconv.Itoa(len(c.buffered)))
+	}
+	if c.status != 0 {
+		c.writer.WriteHeader(c.status)
+	}
+	buffered := c.buffered
+	c.buffered = nil
+	if len(buffered) == 0 {
+		return nil
+	}
+	var err error
+	if compress {
+		_, err = c.compressor.Write(buffered)
+	} else {
+		_, err = c.counter.Write(buffered)
+	}
+	return err
+}
+
 // CloseNotify is part of http.CloseNotifier interface
 func (c *CompressingResponseWriter) CloseNotify() <-chan bool {
 	return c.writer.(http.CloseNotifier).CloseNotify()
 }
 
+// Flush is part of http.Flusher interface
+// It writes the data buffered by the compressor and then flushes the underlying writer.
+func (c *CompressingResponseWriter) Flush() {
+	if !c.decided && !c.isCompressorClosed() {
+		// a flushed response is streamed ; its size is unknown
+		c.decide(true)
+	}
+	if flusher, ok := c.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := c.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is part of http.Hijacker interface
+func (c *CompressingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := c.writer.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Push is part of http.Pusher interface
+func (c *CompressingResponseWriter) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := c.writer.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying writer ; it is used by http.ResponseController.
+func (c *CompressingResponseWriter) Unwrap() http.ResponseWriter {
+	return c.writer
+}
+
 // Close the underlying compressor
+// If it is not decided whether to compress, the buffered bytes are written as is.
 func (c *CompressingResponseWriter) Close() error {
 	if c.isCompressorClosed() {
 		return errors.New("Compressing error: tried to close already closed compressor")
 	}
-
+	if !c.decided {
+		c.decide(false)
+	}
+	c.closed = true
+	if c.compressor == nil {
+		return nil
+	}
 	c.compressor.Close()
 	if ENCODING_GZIP == c.encoding {
 		currentCompressorProvider.ReleaseGzipWriter(c.compressor.(*gzip.Writer))
@@ -66,7 +180,20 @@ func (c *CompressingResponseWriter) Close() error {
 }
 
 func (c *CompressingResponseWriter) isCompressorClosed() bool {
-	return nil == c.compressor
+	return c.closed
+}
+
+// acquireCompressor gets a writer for the encoding from the CompressorProvider.
+func (c *CompressingResponseWriter) acquireCompressor() {
+	if ENCODING_GZIP == c.encoding {
+		w := currentCompressorProvider.AcquireGzipWriter()
+		w.Reset(c.counter)
+		c.compressor = w
+	} else {
+		w := currentCompressorProvider.AcquireZlibWriter()
+		w.Reset(c.counter)
+		c.compressor = w
+	}
 }
 
 // WantsCompressedResponse reads the Accept-Encoding header to see if and which encoding is requested.
@@ -89,22 +216,23 @@ func wantsCompressedResponse(httpRequest *http.Request) (bool, string) {
 
 // NewCompressingResponseWriter create a CompressingResponseWriter for a known encoding = {gzip,deflate}
 func NewCompressingResponseWriter(httpWriter http.ResponseWriter, encoding string) (*CompressingResponseWriter, error) {
-	httpWriter.Header().Set(HEADER_ContentEncoding, encoding)
+	return newCompressingResponseWriter(httpWriter, encoding, 0)
+}
+
+// newCompressingResponseWriter creates a CompressingResponseWriter that buffers up to threshold bytes before
+// deciding to compress ; a smaller response is written as is, with a Content-Length. A threshold of 0 or less
+// compresses all responses and sets the Content-Encoding header right away.
+func newCompressingResponseWriter(httpWriter http.ResponseWriter, encoding string, threshold int) (*CompressingResponseWriter, error) {
+	if ENCODING_GZIP != encoding && ENCODING_DEFLATE != encoding {
+		return nil, errors.New("Unknown encoding:" + encoding)
+	}
 	c := new(CompressingResponseWriter)
 	c.writer = httpWriter
-	var err error
-	if ENCODING_GZIP == encoding {
-		w := currentCompressorProvider.AcquireGzipWriter()
-		w.Reset(httpWriter)
-		c.compressor = w
-		c.encoding = ENCODING_GZIP
-	} else if ENCODING_DEFLATE == encoding {
-		w := currentCompressorProvider.AcquireZlibWriter()
-		w.Reset(httpWriter)
-		c.compressor = w
-		c.encoding = ENCODING_DEFLATE
-	} else {
-		return nil, errors.New("Unknown encoding:" + encoding)
+	c.counter = &countingWriter{writer: httpWriter}
+	c.encoding = encoding
+	c.threshold = threshold
+	if threshold <= 0 {
+		c.decide(true)
 	}
-	return c, err
+	return c, nil
 }
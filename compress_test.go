@@ -125,3 +125,123 @@ func TestZlibDecompressRequestBody(t *testing.T) {
 		t.Errorf("got %v want %v", got, want)
 	}
 }
+
+// go test -v -test.run TestFlushThroughCompressor ...restful
+func TestFlushThroughCompressor(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := NewResponse(httpWriter)
+	resp.renderOptions.Encoding = ENCODING_GZIP
+	resp.Write([]byte("partial"))
+	resp.Flush()
+	if !httpWriter.Flushed {
+		t.Error("recorder not flushed")
+	}
+	// the flushed data can be decompressed before the compressor is closed
+	reader, err := gzip.NewReader(bytes.NewReader(httpWriter.Body.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 7)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "partial"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	resp.closeCompressor()
+}
+
+func TestCompressingResponseWriterUnwrap(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	compressWriter, _ := NewCompressingResponseWriter(httpWriter, ENCODING_DEFLATE)
+	defer compressWriter.Close()
+	if got := compressWriter.Unwrap(); got != httpWriter {
+		t.Errorf("got %v want %v", got, httpWriter)
+	}
+	if err := compressWriter.Push("/style.css", nil); err != http.ErrNotSupported {
+		t.Errorf("got %v want %v", err, http.ErrNotSupported)
+	}
+	if _, _, err := compressWriter.Hijack(); err != http.ErrNotSupported {
+		t.Errorf("got %v want %v", err, http.ErrNotSupported)
+	}
+}
+
+// go test -v -test.run TestContentEncodingThreshold ...restful
+func TestContentEncodingThreshold(t *testing.T) {
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	container.ContentEncodingThreshold(100)
+	ws := new(WebService).Path("/threshold")
+	ws.Route(ws.GET("/{size}").To(func(req *Request, resp *Response) {
+		resp.WriteHeader(http.StatusCreated)
+		if req.PathParameter("size") == "large" {
+			resp.Write(bytes.Repeat([]byte("a"), 60))
+			resp.Write(bytes.Repeat([]byte("b"), 60))
+			return
+		}
+		resp.Write([]byte("small"))
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/threshold/small", nil)
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got := httpWriter.Header().Get("Content-Encoding"); got != "" {
+		t.Errorf("got encoding %q for a small body", got)
+	}
+	if got, want := httpWriter.Header().Get("Content-Length"), "5"; got != want {
+		t.Errorf("got Content-Length %q want %q", got, want)
+	}
+	if got, want := httpWriter.Code, http.StatusCreated; got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+	if got, want := httpWriter.Body.String(), "small"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "/threshold/large", nil)
+	httpRequest.Header.Set("Accept-Encoding", "gzip")
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get("Content-Encoding"), "gzip"; got != want {
+		t.Fatalf("got encoding %q want %q", got, want)
+	}
+	if got := httpWriter.Header().Get("Content-Length"); got != "" {
+		t.Errorf("got Content-Length %q for a compressed body", got)
+	}
+	if got, want := httpWriter.Code, http.StatusCreated; got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+	reader, err := gzip.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	if got, want := len(data), 120; got != want {
+		t.Errorf("got %d bytes want %d", got, want)
+	}
+}
+
+// go test -v -test.run TestContentEncodingThresholdFlush ...restful
+func TestContentEncodingThresholdFlush(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	c, _ := newCompressingResponseWriter(httpWriter, ENCODING_DEFLATE, 1000)
+	c.Write([]byte("streamed"))
+	if httpWriter.Header().Get("Content-Encoding") != "" {
+		t.Fatal("decided before the threshold or a flush")
+	}
+	c.Flush()
+	if got, want := httpWriter.Header().Get("Content-Encoding"), "deflate"; got != want {
+		t.Errorf("got encoding %q want %q", got, want)
+	}
+	c.Close()
+	reader, err := zlib.NewReader(httpWriter.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(reader)
+	if got, want := string(data), "streamed"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,103 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// ConcurrencyLimiter is used to create a Filter that limits the number of requests that are processed at the same time.
+// If all slots are taken then a request waits in a queue ; if the queue is full or the request waits longer than
+// the QueueTimeout then it is shed with a 503 (Service Unavailable) and a Retry-After header.
+// This prevents a slow downstream from exhausting the goroutines and memory of the process.
+// Add it as a Container filter after filters that must see all requests, such as AccessLog.
+//
+//	limiter := restful.NewConcurrencyLimiter(100)
+//	limiter.QueueDepth, limiter.QueueTimeout = 200, time.Second
+//	restful.Filter(limiter.Filter)
+type ConcurrencyLimiter struct {
+	QueueDepth   int           // maximum number of waiting requests ; default is 0, no queue
+	QueueTimeout time.Duration // maximum time a request waits ; 0 means until a slot is free or the client goes away
+	RetryAfter   time.Duration // value of the Retry-After header of shed requests ; default is 1 second
+
+	slots  chan struct{} // one element for each request in flight
+	queued int64
+	shed   uint64
+}
+
+// ConcurrencyStats is a snapshot of the state of a ConcurrencyLimiter.
+type ConcurrencyStats struct {
+	InFlight int    // number of requests being processed
+	Queued   int    // number of requests waiting
+	Shed     uint64 // number of requests rejected since creation
+}
+
+// NewConcurrencyLimiter returns a ConcurrencyLimiter that processes at most maxInFlight requests at the same time.
+func NewConcurrencyLimiter(maxInFlight int) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{RetryAfter: time.Second, slots: make(chan struct{}, maxInFlight)}
+}
+
+// Filter processes the request if a slot is available, possibly after waiting in the queue, or sheds it.
+func (l *ConcurrencyLimiter) Filter(req *Request, resp *Response, chain *FilterChain) {
+	select {
+	case l.slots <- struct{}{}:
+	default:
+		if !l.wait(req, resp) {
+			return
+		}
+	}
+	defer func() { <-l.slots }()
+	chain.ProcessFilter(req, resp)
+}
+
+// wait queues the request until a slot is taken. It returns false if the request is shed or its client went away.
+func (l *ConcurrencyLimiter) wait(req *Request, resp *Response) bool {
+	if atomic.AddInt64(&l.queued, 1) > int64(l.QueueDepth) {
+		atomic.AddInt64(&l.queued, -1)
+		l.reject(resp, "too many requests in flight")
+		return false
+	}
+	defer atomic.AddInt64(&l.queued, -1)
+	var timeout <-chan time.Time
+	if l.QueueTimeout > 0 {
+		timer := time.NewTimer(l.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timeout:
+		l.reject(resp, fmt.Sprintf("no request slot available within %v", l.QueueTimeout))
+		return false
+	case <-req.Context().Done():
+		// the client went away ; nobody reads the response
+		return false
+	}
+}
+
+func (l *ConcurrencyLimiter) reject(resp *Response, reason string) {
+	atomic.AddUint64(&l.shed, 1)
+	retryAfter := l.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	resp.Header().Set(HEADER_RetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	resp.WriteProblem(NewProblemDocument(http.StatusServiceUnavailable, reason))
+}
+
+// Stats returns the current number of requests in flight and queued and the number of shed requests.
+func (l *ConcurrencyLimiter) Stats() ConcurrencyStats {
+	return ConcurrencyStats{
+		InFlight: len(l.slots),
+		Queued:   int(atomic.LoadInt64(&l.queued)),
+		Shed:     atomic.LoadUint64(&l.shed),
+	}
+}
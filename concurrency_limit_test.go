@@ -0,0 +1,66 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestConcurrencyLimiter ...restful
+func TestConcurrencyLimiter(t *testing.T) {
+	limiter := NewConcurrencyLimiter(1)
+	limiter.QueueDepth, limiter.QueueTimeout = 1, 20*time.Millisecond
+	entered, release := make(chan bool), make(chan bool)
+	ws := new(WebService).Path("/slow")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		entered <- true
+		<-release
+	}))
+	c := NewContainer().Add(ws)
+	c.Filter(limiter.Filter)
+
+	serve := func() int {
+		httpRequest, _ := http.NewRequest("GET", "/slow", nil)
+		httpWriter := httptest.NewRecorder()
+		c.ServeHTTP(httpWriter, httpRequest)
+		return httpWriter.Code
+	}
+	var wait sync.WaitGroup
+	wait.Add(1)
+	go func() { defer wait.Done(); serve() }()
+	<-entered // the slot is taken
+
+	// one request can wait in the queue but times out
+	if got, want := serve(), http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// a queued request gets the slot when it is released
+	limiter.QueueTimeout = time.Minute
+	queued := make(chan int)
+	go func() { queued <- serve() }()
+	for limiter.Stats().Queued == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	// the queue is full
+	httpRequest, _ := http.NewRequest("GET", "/slow", nil)
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_RetryAfter), "1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	release <- true
+	wait.Wait()
+	<-entered
+	release <- true
+	if got, want := <-queued, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := limiter.Stats(), (ConcurrencyStats{Shed: 2}); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
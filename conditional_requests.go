@@ -0,0 +1,94 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetETag sets the ETag header of the response. The tag is quoted if needed and prefixed with W/ if weak.
+// A tag that already has the W/ prefix is set unchanged.
+func (r *Response) SetETag(tag string, weak bool) {
+	if strings.HasPrefix(tag, `W/"`) {
+		r.Header().Set(HEADER_ETag, tag)
+		return
+	}
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		tag = `"` + tag + `"`
+	}
+	if weak {
+		tag = "W/" + tag
+	}
+	r.Header().Set(HEADER_ETag, tag)
+}
+
+// SetLastModified sets the Last-Modified header of the response using the HTTP date format.
+func (r *Response) SetLastModified(modified time.Time) {
+	r.Header().Set(HEADER_LastModified, modified.UTC().Format(http.TimeFormat))
+}
+
+// EvaluatePreconditions evaluates the conditional headers of the request (If-Match, If-Unmodified-Since,
+// If-None-Match and If-Modified-Since) against the current state of the resource, as specified by RFC 7232 section 6.
+// Pass the current (quoted) entity tag, or an empty string if the resource does not exist, and the time of
+// its last modification, or the zero time if unknown.
+// It returns the status to write instead of processing the request, 304 (Not Modified) or 412 (Precondition Failed),
+// or 0 if the request must be processed.
+//
+//	if status := req.EvaluatePreconditions(meeting.ETag(), meeting.Updated); status != 0 {
+//		resp.WriteHeader(status)
+//		return
+//	}
+func (r *Request) EvaluatePreconditions(etag string, lastModified time.Time) int {
+	header := r.Request.Header
+	// step 1 and 2: the state the client expects for changing the resource
+	if match := header.Get(HEADER_IfMatch); len(match) > 0 {
+		if !matchesETag(match, etag, false) {
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(header.Get(HEADER_IfUnmodifiedSince)); err == nil && !lastModified.IsZero() {
+		if lastModified.Truncate(time.Second).After(since) {
+			return http.StatusPreconditionFailed
+		}
+	}
+	// step 3 and 4: whether the client already has the current representation
+	safe := r.Request.Method == http.MethodGet || r.Request.Method == http.MethodHead
+	if match := header.Get(HEADER_IfNoneMatch); len(match) > 0 {
+		if matchesETag(match, etag, true) {
+			if safe {
+				return http.StatusNotModified
+			}
+			return http.StatusPreconditionFailed
+		}
+	} else if since, err := http.ParseTime(header.Get(HEADER_IfModifiedSince)); err == nil && safe && !lastModified.IsZero() {
+		if !lastModified.Truncate(time.Second).After(since) {
+			return http.StatusNotModified
+		}
+	}
+	return 0
+}
+
+// matchesETag returns whether one of the entity tags in the header value matches the etag.
+// The wildcard * matches any existing resource. A strong comparison never matches a weak tag.
+func matchesETag(headerValue, etag string, weakComparison bool) bool {
+	if len(etag) == 0 {
+		return false
+	}
+	for _, each := range strings.Split(headerValue, ",") {
+		each = strings.TrimSpace(each)
+		if each == "*" {
+			return true
+		}
+		if weakComparison {
+			if strings.TrimPrefix(each, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if !strings.HasPrefix(each, "W/") && each == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSetETagAndLastModified(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	resp.SetETag("v1", true)
+	if got, want := resp.Header().Get(HEADER_ETag), `W/"v1"`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for _, each := range []struct {
+		tag  string
+		weak bool
+		want string
+	}{
+		{`"v2"`, false, `"v2"`},
+		{`"v2"`, true, `W/"v2"`},
+		{`W/"v2"`, false, `W/"v2"`},
+		{`W/"v2"`, true, `W/"v2"`},
+	} {
+		resp.SetETag(each.tag, each.weak)
+		if got := resp.Header().Get(HEADER_ETag); got != each.want {
+			t.Errorf("%s: got %v want %v", each.tag, got, each.want)
+		}
+	}
+	resp.SetLastModified(time.Date(2015, 10, 21, 7, 28, 0, 0, time.FixedZone("CEST", 7200)))
+	if got, want := resp.Header().Get(HEADER_LastModified), "Wed, 21 Oct 2015 05:28:00 GMT"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestEvaluatePreconditions ...restful
+func TestEvaluatePreconditions(t *testing.T) {
+	modified := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+	for i, each := range []struct {
+		method string
+		header string
+		value  string
+		etag   string
+		want   int
+	}{
+		{"GET", "", "", `"v1"`, 0},
+		{"PUT", HEADER_IfMatch, `"v1"`, `"v1"`, 0},
+		{"PUT", HEADER_IfMatch, `"v0", "v2"`, `"v1"`, http.StatusPreconditionFailed},
+		{"PUT", HEADER_IfMatch, `W/"v1"`, `"v1"`, http.StatusPreconditionFailed},
+		{"PUT", HEADER_IfMatch, "*", `"v1"`, 0},
+		{"PUT", HEADER_IfMatch, "*", "", http.StatusPreconditionFailed},
+		{"PUT", HEADER_IfUnmodifiedSince, before, `"v1"`, http.StatusPreconditionFailed},
+		{"PUT", HEADER_IfUnmodifiedSince, after, `"v1"`, 0},
+		{"GET", HEADER_IfNoneMatch, `W/"v1"`, `"v1"`, http.StatusNotModified},
+		{"GET", HEADER_IfNoneMatch, `"v0"`, `"v1"`, 0},
+		{"PUT", HEADER_IfNoneMatch, "*", `"v1"`, http.StatusPreconditionFailed},
+		{"PUT", HEADER_IfNoneMatch, "*", "", 0},
+		{"GET", HEADER_IfModifiedSince, after, `"v1"`, http.StatusNotModified},
+		{"GET", HEADER_IfModifiedSince, before, `"v1"`, 0},
+		{"POST", HEADER_IfModifiedSince, after, `"v1"`, 0},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, "/meetings/1", nil)
+		if len(each.header) > 0 {
+			httpRequest.Header.Set(each.header, each.value)
+		}
+		if got := NewRequest(httpRequest).EvaluatePreconditions(each.etag, modified); got != each.want {
+			t.Errorf("%d: %s %s: got %v want %v", i, each.method, each.header, got, each.want)
+		}
+	}
+}
+
+func TestEvaluatePreconditionsIfNoneMatchBeforeIfModifiedSince(t *testing.T) {
+	modified := time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC)
+	httpRequest, _ := http.NewRequest("GET", "/meetings/1", nil)
+	httpRequest.Header.Set(HEADER_IfNoneMatch, `"v0"`)
+	httpRequest.Header.Set(HEADER_IfModifiedSince, modified.Format(http.TimeFormat))
+	if got := NewRequest(httpRequest).EvaluatePreconditions(`"v1"`, modified); got != 0 {
+		t.Errorf("got %v want 0", got)
+	}
+}
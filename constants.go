@@ -14,8 +14,27 @@ const (
 	HEADER_Origin                        = "Origin"
 	HEADER_ContentType                   = "Content-Type"
 	HEADER_LastModified                  = "Last-Modified"
+	HEADER_ETag                          = "ETag"
+	HEADER_IfNoneMatch                   = "If-None-Match"
+	HEADER_IfModifiedSince               = "If-Modified-Since"
+	HEADER_IfMatch                       = "If-Match"
+	HEADER_IfUnmodifiedSince             = "If-Unmodified-Since"
 	HEADER_AcceptEncoding                = "Accept-Encoding"
 	HEADER_ContentEncoding               = "Content-Encoding"
+	HEADER_ContentLength                 = "Content-Length"
+	HEADER_IdempotencyKey                = "Idempotency-Key"
+	HEADER_IdempotentReplayed            = "Idempotent-Replayed"
+	HEADER_ContentLanguage               = "Content-Language"
+	HEADER_AcceptLanguage                = "Accept-Language"
+	HEADER_ContentDisposition            = "Content-Disposition"
+	HEADER_Connection                    = "Connection"
+	HEADER_Upgrade                       = "Upgrade"
+	HEADER_RetryAfter                    = "Retry-After"
+	HEADER_RateLimitLimit                = "RateLimit-Limit"
+	HEADER_RateLimitRemaining            = "RateLimit-Remaining"
+	HEADER_RateLimitReset                = "RateLimit-Reset"
+	HEADER_Vary                          = "Vary"
+	HEADER_CacheControl                  = "Cache-Control"
 	HEADER_AccessControlExposeHeaders    = "Access-Control-Expose-Headers"
 	HEADER_AccessControlRequestMethod    = "Access-Control-Request-Method"
 	HEADER_AccessControlRequestHeaders   = "Access-Control-Request-Headers"
@@ -6,12 +6,16 @@ package restful
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/emicklei/go-restful/log"
 )
@@ -24,11 +28,28 @@ type Container struct {
 	ServeMux               *http.ServeMux
 	isRegisteredOnRoot     bool
 	containerFilters       []FilterFunction
-	doNotRecover           bool // default is false
-	recoverHandleFunc      RecoverHandleFunction
+	filterPriorities       []int          // priority of each of the containerFilters
+	recoveryPolicy         RecoveryPolicy // default is RecoverAndLog
 	serviceErrorHandleFunc ServiceErrorHandleFunction
+	notFoundHandleFunc     RouteFunction // default is nil ; the serviceErrorHandleFunc writes the 404
 	router                 RouteSelector // default is a RouterJSR311, CurlyRouter is the faster alternative
 	contentEncodingEnabled bool          // default is false
+	encodingThreshold      int           // default is 0 ; all response bodies are compressed
+	strictEntityWriters    bool          // default is false
+	fallbackEntityWriter   EntityReaderWriter
+	identifierFormat       IdentifierFormat // default is UUIDFormat
+	lateWritePolicy        LateWritePolicy  // default is LogLateWrites
+	errorVerbosity         *ErrorVerbosity  // default is nil ; error messages are written as given
+	dependencies           map[reflect.Type]interface{}
+	dependenciesLock       sync.RWMutex
+	requestTracing         *RequestTracing // default is nil ; no request is traced
+	clientIPPolicy         *ClientIPPolicy // default is nil ; forwarding headers are ignored
+	validator              Validator       // default is nil ; values are not validated
+	lifecycleHooks         lifecycleHooks  // default has no hooks
+	maxBodyBytes           int64           // default is 0 ; request bodies are buffered without limit
+	messageCatalog         MessageCatalog  // default is nil ; messages are not localized
+	wrapperPooling         bool            // default is false
+	scopedConstructors     map[string]scopedConstructor
 }
 
 // NewContainer creates a new Container using a new ServeMux and default router (RouterJSR311)
@@ -38,11 +59,11 @@ func NewContainer() *Container {
 		ServeMux:               http.NewServeMux(),
 		isRegisteredOnRoot:     false,
 		containerFilters:       []FilterFunction{},
-		doNotRecover:           false,
-		recoverHandleFunc:      logStackOnRecover,
+		recoveryPolicy:         RecoverAndLog,
 		serviceErrorHandleFunc: writeServiceError,
 		router:                 RouterJSR311{},
-		contentEncodingEnabled: false}
+		contentEncodingEnabled: false,
+		identifierFormat:       UUIDFormat}
 }
 
 // RecoverHandleFunction declares functions that can be used to handle a panic situation.
@@ -51,8 +72,9 @@ type RecoverHandleFunction func(interface{}, http.ResponseWriter)
 
 // RecoverHandler changes the default function (logStackOnRecover) to be called
 // when a panic is detected. DoNotRecover must be have its default value (=false).
+// Routes with a RecoveryPolicy of their own are not affected.
 func (c *Container) RecoverHandler(handler RecoverHandleFunction) {
-	c.recoverHandleFunc = handler
+	c.recoveryPolicy.handler = handler
 }
 
 // ServiceErrorHandleFunction declares functions that can be used to handle a service error situation.
@@ -61,16 +83,45 @@ func (c *Container) RecoverHandler(handler RecoverHandleFunction) {
 type ServiceErrorHandleFunction func(ServiceError, *Request, *Response)
 
 // ServiceErrorHandler changes the default function (writeServiceError) to be called
-// when a ServiceError is detected.
+// when a ServiceError is detected, e.g. a 405, 406 or 415 during route selection.
+// A 404 is handled by the NotFoundHandler instead, if set.
 func (c *Container) ServiceErrorHandler(handler ServiceErrorHandleFunction) {
 	c.serviceErrorHandleFunc = handler
 }
 
+// NotFoundHandler sets the function that is called instead of the ServiceErrorHandler if no
+// WebService or Route matches the path of a request, e.g. to write a negotiated error document
+// or to redirect. It is also called for paths outside the root paths of all WebServices ;
+// therefore the Container handles "/" on its ServeMux from now on.
+//
+//	container.NotFoundHandler(func(req *restful.Request, resp *restful.Response) {
+//		resp.WriteErrorEntity(http.StatusNotFound, nil, map[string]string{"error": "no such resource: " + req.Request.URL.Path})
+//	})
+func (c *Container) NotFoundHandler(handler RouteFunction) {
+	c.webServicesLock.Lock()
+	defer c.webServicesLock.Unlock()
+	c.notFoundHandleFunc = handler
+	if !c.isRegisteredOnRoot {
+		c.registerOnRoot()
+	}
+}
+
+// registerOnRoot maps "/" to the dispatch of the Container unless a handler for "/" was registered with Handle.
+func (c *Container) registerOnRoot() {
+	c.isRegisteredOnRoot = true
+	if _, pattern := c.ServeMux.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: "/"}}); pattern == "/" {
+		log.Printf("[restful] a handler for / is already registered ; requests that match no other pattern are not dispatched by the Container")
+		return
+	}
+	c.ServeMux.HandleFunc("/", c.dispatch)
+}
+
 // DoNotRecover controls whether panics will be caught to return HTTP 500.
 // If set to true, Route functions are responsible for handling any error situation.
-// Default value is false = recover from panics. This has performance implications.
+// Default value is false = recover from panics.
+// It changes the RecoveryPolicy of the Container (see Recovery) ; Routes with a policy of their own are not affected.
 func (c *Container) DoNotRecover(doNot bool) {
-	c.doNotRecover = doNot
+	c.recoveryPolicy.propagate = doNot
 }
 
 // Router changes the default Router (currently RouterJSR311)
@@ -83,8 +134,29 @@ func (c *Container) EnableContentEncoding(enabled bool) {
 	c.contentEncodingEnabled = enabled
 }
 
+// ContentEncodingThreshold (default=0) sets the minimum number of bytes of a response body to be compressed,
+// if content encoding is enabled. Up to that number of bytes is buffered ; a smaller body is written uncompressed
+// with a Content-Length header because compressing it costs more than it saves. A response that is flushed is compressed.
+func (c *Container) ContentEncodingThreshold(size int) {
+	c.encodingThreshold = size
+}
+
+// StrictEntityWriters (default=false) controls whether adding a WebService fails if any of its Routes
+// produces a MIME type for which no EntityReaderWriter is registered.
+func (c *Container) StrictEntityWriters(strict bool) {
+	c.strictEntityWriters = strict
+}
+
+// FallbackEntityWriter sets the EntityReaderWriter that is used to write entities in case no registered
+// EntityReaderWriter can be found for the negotiated MIME type. Default is nil which results in a 406 response.
+// Adding a WebService with Routes that would need the fallback writer will log a warning.
+func (c *Container) FallbackEntityWriter(writer EntityReaderWriter) {
+	c.fallbackEntityWriter = writer
+}
+
 // Add a WebService to the Container. It will detect duplicate root paths and panic in that case.
 func (c *Container) Add(service *WebService) *Container {
+	defer service.attached(c) // after unlocking
 	c.webServicesLock.Lock()
 	defer c.webServicesLock.Unlock()
 	// If registered on root then no additional specific mapping is needed
@@ -92,8 +164,7 @@ func (c *Container) Add(service *WebService) *Container {
 		pattern := c.fixedPrefixPath(service.RootPath())
 		// check if root path registration is needed
 		if "/" == pattern || "" == pattern {
-			c.ServeMux.HandleFunc("/", c.dispatch)
-			c.isRegisteredOnRoot = true
+			c.registerOnRoot()
 		} else {
 			// detect if registration already exists
 			alreadyMapped := false
@@ -118,6 +189,11 @@ func (c *Container) Add(service *WebService) *Container {
 			os.Exit(1)
 		}
 	}
+	c.checkEntityWriters(service)
+	c.documentIdentifiers(service)
+	if service.messageCatalog != nil {
+		service.mergedCatalog = serviceMessageCatalog{service: service.messageCatalog, container: c}
+	}
 	// if rootPath was not set then lazy initialize it
 	if len(service.rootPath) == 0 {
 		service.Path("/")
@@ -126,13 +202,38 @@ func (c *Container) Add(service *WebService) *Container {
 	return c
 }
 
+// checkEntityWriters inspects the MIME types produced by the Routes of a WebService.
+// In strict mode, a missing EntityReaderWriter is a fatal error ; otherwise the use of the fallback writer is logged.
+func (c *Container) checkEntityWriters(service *WebService) {
+	if !c.strictEntityWriters && c.fallbackEntityWriter == nil {
+		return
+	}
+	for _, route := range service.Routes() {
+		for _, mime := range missingEntityWriters(route.Produces) {
+			if c.strictEntityWriters {
+				log.Printf("[restful] no EntityReaderWriter registered for %s produced by Route:%v", mime, route)
+				os.Exit(1)
+			}
+			log.Printf("[restful] WARNING: no EntityReaderWriter registered for %s produced by Route:%v ; using fallback writer %T", mime, route, c.fallbackEntityWriter)
+		}
+	}
+}
+
 func (c *Container) Remove(ws *WebService) error {
+	removed := []*WebService{}
+	defer func() { // after unlocking
+		for _, each := range removed {
+			each.detached(c)
+		}
+	}()
 	c.webServicesLock.Lock()
 	defer c.webServicesLock.Unlock()
 	newServices := []*WebService{}
 	for ix := range c.webServices {
 		if c.webServices[ix].rootPath != ws.rootPath {
 			newServices = append(newServices, c.webServices[ix])
+		} else {
+			removed = append(removed, c.webServices[ix])
 		}
 	}
 	c.webServices = newServices
@@ -140,20 +241,28 @@ func (c *Container) Remove(ws *WebService) error {
 }
 
 // logStackOnRecover is the default RecoverHandleFunction and is called
-// when DoNotRecover is false and the recoverHandleFunc is not set for the container.
+// when DoNotRecover is false and no RecoverHandler is set for the container.
 // Default implementation logs the stacktrace and writes the stacktrace on the response.
 // This may be a security issue as it exposes sourcecode information.
 func logStackOnRecover(panicReason interface{}, httpWriter http.ResponseWriter) {
 	var buffer bytes.Buffer
 	buffer.WriteString(fmt.Sprintf("[restful] recover from panic situation: - %v\r\n", panicReason))
-	for i := 2; ; i += 1 {
-		_, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
+	if recovered, ok := panicReason.(*RecoveredPanic); ok {
+		buffer.Write(recovered.Stack) // of the goroutine that panicked
+	} else {
+		for i := 2; ; i += 1 {
+			_, file, line, ok := runtime.Caller(i)
+			if !ok {
+				break
+			}
+			buffer.WriteString(fmt.Sprintf("    %s:%d\r\n", file, line))
 		}
-		buffer.WriteString(fmt.Sprintf("    %s:%d\r\n", file, line))
 	}
 	log.Print(buffer.String())
+	if resp, ok := httpWriter.(*Response); ok && resp.terseError(http.StatusInternalServerError) {
+		resp.WriteErrorString(http.StatusInternalServerError, "")
+		return
+	}
 	httpWriter.WriteHeader(http.StatusInternalServerError)
 	httpWriter.Write(buffer.Bytes())
 }
@@ -167,24 +276,45 @@ func writeServiceError(err ServiceError, req *Request, resp *Response) {
 
 // Dispatch the incoming Http Request to a matching WebService.
 func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.Request) {
-	writer := httpWriter
+	start := time.Now()
+	// writer is replaced by the Response once created ; it is used to communicate a panic situation
+	var writer http.ResponseWriter = httpWriter
 
-	// CompressingResponseWriter should be closed after all operations are done
+	// pooledRequest is set if the Request and Response are to be released after all operations are done
+	var pooledRequest *Request
+	// scope has the cleanups of request scoped dependencies, see ProvideScoped
+	scope := c.newRequestScope()
+
+	// A compressor installed by the Response should be closed after all operations are done
 	defer func() {
-		if compressWriter, ok := writer.(*CompressingResponseWriter); ok {
-			compressWriter.Close()
+		if resp, ok := writer.(*Response); ok {
+			resp.closeCompressor()
+			if resp.lifecycle != nil {
+				resp.lifecycle.fire(resp.lifecycle.hooks.requestEnd, resp, nil, resp.lifecycle.err)
+			}
+			if scope != nil {
+				scope.close()
+			}
+			if pooledRequest != nil {
+				releaseRequestResponse(pooledRequest, resp)
+			}
 		}
 	}()
 
-	// Instal panic recovery unless told otherwise
-	if !c.doNotRecover { // catch all for 500 response
-		defer func() {
-			if r := recover(); r != nil {
-				c.recoverHandleFunc(r, writer)
-				return
+	// Install panic recovery unless the policy of the selected Route, or else of the Container, tells otherwise
+	var route *Route
+	defer func() {
+		policy := c.recoveryPolicyFor(route)
+		if policy.propagate {
+			return
+		}
+		if r := recover(); r != nil { // catch all for 500 response
+			if policy.handler == nil {
+				policy.handler = logStackOnRecover
 			}
-		}()
-	}
+			policy.handler(r, writer)
+		}
+	}()
 	// Install closing the request body (if any)
 	defer func() {
 		if nil != httpRequest.Body {
@@ -192,23 +322,8 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 		}
 	}()
 
-	// Detect if compression is needed
-	// assume without compression, test for override
-	if c.contentEncodingEnabled {
-		doCompress, encoding := wantsCompressedResponse(httpRequest)
-		if doCompress {
-			var err error
-			writer, err = NewCompressingResponseWriter(httpWriter, encoding)
-			if err != nil {
-				log.Print("[restful] unable to install compressor: ", err)
-				httpWriter.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-		}
-	}
 	// Find best match Route ; err is non nil if no match was found
 	var webService *WebService
-	var route *Route
 	var err error
 	func() {
 		c.webServicesLock.RLock()
@@ -217,6 +332,13 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 			c.webServices,
 			httpRequest)
 	}()
+	tracer := c.requestTracer(httpRequest)
+	if tracer != nil {
+		traceRouteSelection(tracer, webService, route, err)
+		httpRequest = httpRequest.WithContext(context.WithValue(httpRequest.Context(), tracerContextKey{}, tracer))
+	}
+	// Detect how the response must be written ; compression is installed when the header is written
+	renderOptions := c.defaultRenderOptions(httpRequest)
 	if err != nil {
 		// a non-200 response has already been written
 		// run container filters anyway ; they should not touch the response...
@@ -224,14 +346,64 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 			switch err.(type) {
 			case ServiceError:
 				ser := err.(ServiceError)
-				c.serviceErrorHandleFunc(ser, req, resp)
+				if ser.Code == http.StatusNotFound && c.notFoundHandleFunc != nil {
+					c.notFoundHandleFunc(req, resp)
+					return
+				}
+				c.serviceErrorHandleFunc(resp.localizedServiceError(ser), req, resp)
 			}
 			// TODO
 		}}
-		chain.ProcessFilter(NewRequest(httpRequest), NewResponse(writer))
+		resp := NewResponse(httpWriter)
+		resp.requestAccept = httpRequest.Header.Get(HEADER_Accept)
+		resp.requestAcceptLanguage = httpRequest.Header.Get(HEADER_AcceptLanguage)
+		resp.renderOptions = renderOptions
+		resp.defaultWriter = c.fallbackEntityWriter
+		resp.errorDetail = c.errorDetailLevel(httpRequest)
+		resp.tracer = tracer
+		writer = resp
+		req := NewRequest(httpRequest)
+		req.tracer = tracer
+		req.clientIPPolicy = c.clientIPPolicy
+		req.validator = c.validator
+		req.maxBodyBytes = c.maxBodyBytes
+		req.messageCatalog = c.messageCatalogFor(webService)
+		resp.messageCatalog = req.messageCatalog
+		req.scope = scope
+		if !c.lifecycleHooks.isEmpty() {
+			resp.lifecycle = &requestLifecycle{hooks: &c.lifecycleHooks, request: req, start: start, err: err}
+			resp.lifecycle.fire(c.lifecycleHooks.requestStart, resp, nil, nil)
+		}
+		chain.ProcessFilter(req, resp)
 		return
 	}
-	wrappedRequest, wrappedResponse := route.wrapRequestResponse(writer, httpRequest)
+	var wrappedRequest *Request
+	var wrappedResponse *Response
+	if c.wrapperPooling {
+		wrappedRequest, wrappedResponse = route.wrapInto(acquireRequestResponse(httpWriter, httpRequest))
+		pooledRequest = wrappedRequest
+	} else {
+		wrappedRequest, wrappedResponse = route.wrapRequestResponse(httpWriter, httpRequest)
+	}
+	wrappedRequest.identifierFormat = c.identifierFormat
+	wrappedRequest.clientIPPolicy = c.clientIPPolicy
+	wrappedRequest.validator = c.validator
+	wrappedRequest.maxBodyBytes = c.maxBodyBytes
+	wrappedRequest.messageCatalog = c.messageCatalogFor(webService)
+	wrappedRequest.scope = scope
+	wrappedResponse.messageCatalog = wrappedRequest.messageCatalog
+	wrappedResponse.renderOptions = route.renderOptions(renderOptions)
+	wrappedResponse.renderOptions.Fields = route.sparseFieldset(httpRequest, wrappedResponse.renderOptions.Fields)
+	wrappedResponse.defaultWriter = c.fallbackEntityWriter
+	wrappedResponse.lateWritePolicy = c.lateWritePolicy
+	wrappedResponse.errorDetail = c.errorDetailLevel(httpRequest)
+	wrappedRequest.tracer, wrappedResponse.tracer = tracer, tracer
+	writer = wrappedResponse
+	if !c.lifecycleHooks.isEmpty() {
+		wrappedResponse.lifecycle = &requestLifecycle{hooks: &c.lifecycleHooks, request: wrappedRequest, start: start}
+		wrappedResponse.lifecycle.fire(c.lifecycleHooks.requestStart, wrappedResponse, nil, nil)
+		wrappedResponse.lifecycle.fire(c.lifecycleHooks.routeSelected, wrappedResponse, nil, nil)
+	}
 	// pass through filters (if any)
 	if len(c.containerFilters)+len(webService.filters)+len(route.Filters) > 0 {
 		// compose filter chain
@@ -241,11 +413,25 @@ func (c *Container) dispatch(httpWriter http.ResponseWriter, httpRequest *http.R
 		allFilters = append(allFilters, route.Filters...)
 		chain := FilterChain{Filters: allFilters, Target: func(req *Request, resp *Response) {
 			// handle request by route after passing all filters
-			route.Function(wrappedRequest, wrappedResponse)
+			if serviceError, violated := route.violatedParameterDependency(req); violated {
+				c.serviceErrorHandleFunc(serviceError, req, resp)
+				return
+			}
+			resp.pushDeclared(route)
+			route.Function(req, resp)
+			resp.routeFunctionDone = true
 		}}
 		chain.ProcessFilter(wrappedRequest, wrappedResponse)
 	} else {
 		// no filters, handle request by route
+		if serviceError, violated := route.violatedParameterDependency(wrappedRequest); violated {
+			c.serviceErrorHandleFunc(serviceError, wrappedRequest, wrappedResponse)
+			return
+		}
+		wrappedResponse.pushDeclared(route)
+		if tracer != nil {
+			defer traceCall(tracer, "route function", route.Function)()
+		}
 		route.Function(wrappedRequest, wrappedResponse)
 	}
 }
@@ -291,7 +477,13 @@ func (c *Container) HandleWithFilter(pattern string, handler http.Handler) {
 // Filter appends a container FilterFunction. These are called before dispatching
 // a http.Request to a WebService from the container
 func (c *Container) Filter(filter FilterFunction) {
-	c.containerFilters = append(c.containerFilters, filter)
+	c.FilterWithPriority(DefaultFilterPriority, filter)
+}
+
+// FilterWithPriority adds a container FilterFunction that is called before all filters with a higher priority
+// and after those with a lower or equal priority. Filter uses DefaultFilterPriority.
+func (c *Container) FilterWithPriority(priority int, filter FilterFunction) {
+	c.containerFilters, c.filterPriorities = insertFilter(c.containerFilters, c.filterPriorities, filter, priority)
 }
 
 // RegisteredWebServices returns the collections of added WebServices
@@ -307,9 +499,18 @@ func (c Container) RegisteredWebServices() []*WebService {
 
 // computeAllowedMethods returns a list of HTTP methods that are valid for a Request
 func (c Container) computeAllowedMethods(req *Request) []string {
-	// Go through all RegisteredWebServices() and all its Routes to collect the options
 	methods := []string{}
-	requestPath := req.Request.URL.Path
+	for _, rt := range c.routesForPath(req.Request.URL.Path) {
+		methods = append(methods, rt.Method)
+	}
+	// methods = append(methods, "OPTIONS")  not sure about this
+	return methods
+}
+
+// routesForPath returns the Routes, of any method, that match the path of a request.
+func (c *Container) routesForPath(requestPath string) []Route {
+	// Go through all RegisteredWebServices() and all its Routes to collect the options
+	routes := []Route{}
 	for _, ws := range c.RegisteredWebServices() {
 		matches := ws.pathExpr.Matcher.FindStringSubmatch(requestPath)
 		if matches != nil {
@@ -319,14 +520,38 @@ func (c Container) computeAllowedMethods(req *Request) []string {
 				if matches != nil {
 					lastMatch := matches[len(matches)-1]
 					if lastMatch == "" || lastMatch == "/" { // do not include if value is neither empty nor ‘/’.
-						methods = append(methods, rt.Method)
+						routes = append(routes, rt)
 					}
 				}
 			}
 		}
 	}
-	// methods = append(methods, "OPTIONS")  not sure about this
-	return methods
+	return routes
+}
+
+// routeForMethod returns the Route that matches the path of the Http request for another method, ignoring media types.
+// Returns nil if there is none.
+func (c *Container) routeForMethod(httpRequest *http.Request, method string) *Route {
+	requestPath := httpRequest.URL.Path
+	for _, ws := range c.RegisteredWebServices() {
+		matches := ws.pathExpr.Matcher.FindStringSubmatch(requestPath)
+		if matches == nil {
+			continue
+		}
+		finalMatch := matches[len(matches)-1]
+		for _, rt := range ws.Routes() {
+			if rt.Method != method {
+				continue
+			}
+			matches := rt.pathExpr.Matcher.FindStringSubmatch(finalMatch)
+			if matches != nil {
+				if lastMatch := matches[len(matches)-1]; lastMatch == "" || lastMatch == "/" {
+					return &rt
+				}
+			}
+		}
+	}
+	return nil
 }
 
 // newBasicRequestResponse creates a pair of Request,Response from its http versions.
@@ -334,5 +559,6 @@ func (c Container) computeAllowedMethods(req *Request) []string {
 func newBasicRequestResponse(httpWriter http.ResponseWriter, httpRequest *http.Request) (*Request, *Response) {
 	resp := NewResponse(httpWriter)
 	resp.requestAccept = httpRequest.Header.Get(HEADER_Accept)
+	resp.requestAcceptLanguage = httpRequest.Header.Get(HEADER_AcceptLanguage)
 	return NewRequest(httpRequest), resp
 }
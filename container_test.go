@@ -3,6 +3,7 @@ package restful
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -59,3 +60,82 @@ func TestContainer_HandleWithFilter(t *testing.T) {
 		t.Errorf("handler added by calling HandleWithFilter wasn't called")
 	}
 }
+
+// go test -v -test.run TestContainer_FallbackEntityWriter ...restful
+func TestContainer_FallbackEntityWriter(t *testing.T) {
+	wc := NewContainer()
+	wc.FallbackEntityWriter(entityJSONAccess{ContentType: MIME_JSON})
+	ws := new(WebService).Path("/csv")
+	ws.Route(ws.GET("").To(writeFood).Produces("text/csv"))
+	wc.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/csv", nil)
+	httpRequest.Header.Set(HEADER_Accept, "text/csv")
+	httpWriter := httptest.NewRecorder()
+	wc.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestContainer_NotFoundHandlerWithRootHandler ...restful
+func TestContainer_NotFoundHandlerWithRootHandler(t *testing.T) {
+	wc := NewContainer()
+	wc.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	wc.NotFoundHandler(func(req *Request, resp *Response) {
+		resp.WriteHeader(http.StatusNotFound)
+	})
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/users").To(dummy))
+	wc.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/outside", nil)
+	httpWriter := httptest.NewRecorder()
+	wc.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusTeapot; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestContainer_NotFoundHandler ...restful
+func TestContainer_NotFoundHandler(t *testing.T) {
+	wc := NewContainer()
+	ws := new(WebService).Path("/users").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(dummy))
+	wc.Add(ws)
+	wc.NotFoundHandler(func(req *Request, resp *Response) {
+		resp.WriteErrorEntity(http.StatusNotFound, nil, map[string]string{"missing": req.Request.URL.Path})
+	})
+	wc.ServiceErrorHandler(ServiceErrorEntityHandler(func(err ServiceError, req *Request) interface{} {
+		return map[string]int{"code": err.Code}
+	}))
+
+	for _, each := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/users/1/friends", http.StatusNotFound, `"missing": "/users/1/friends"`},
+		{"GET", "/outside", http.StatusNotFound, `"missing": "/outside"`},
+		{"DELETE", "/users/1", http.StatusMethodNotAllowed, `"code": 405`},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, each.path, nil)
+		httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+		httpWriter := httptest.NewRecorder()
+		wc.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.status; got != want {
+			t.Errorf("%s: got %v want %v", each.path, got, want)
+		}
+		if got, want := httpWriter.Body.String(), each.body; !strings.Contains(got, want) {
+			t.Errorf("%s: got %v want %v", each.path, got, want)
+		}
+		if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+			t.Errorf("%s: got %v want %v", each.path, got, want)
+		}
+	}
+}
@@ -9,6 +9,11 @@ import (
 	"strings"
 )
 
+// KeyCORS is the Route metadata key for a CrossOriginResourceSharing value that replaces the configuration
+// of the CORS Filter for that Route. Use WebService.Metadata to replace it for all Routes of a WebService.
+// For preflight requests, the Route is the one that would handle the requested method ; this requires the Container field.
+const KeyCORS = "restful.cors"
+
 // CrossOriginResourceSharing is used to create a Container Filter that implements CORS.
 // Cross-origin resource sharing (CORS) is a mechanism that allows JavaScript on a web page
 // to make XMLHttpRequests to another domain, not the domain the JavaScript originated from.
@@ -17,9 +22,11 @@ import (
 // http://enable-cors.org/server.html
 // http://www.html5rocks.com/en/tutorials/cors/#toc-handling-a-not-so-simple-request
 type CrossOriginResourceSharing struct {
-	ExposeHeaders  []string // list of Header names
+	ExposeHeaders  []string // list of Header names ; the headers declared by the Route using ReturnsHeader are added
 	AllowedHeaders []string // list of Header names
-	AllowedDomains []string // list of allowed values for Http Origin. If empty all are allowed.
+	// AllowedDomains lists the allowed values for Http Origin. If empty all are allowed.
+	// A value can be a pattern with a * for the subdomain part, e.g. https://*.example.com
+	AllowedDomains []string
 	AllowedMethods []string
 	MaxAge         int // number of seconds before requiring new Options request
 	CookiesAllowed bool
@@ -29,6 +36,31 @@ type CrossOriginResourceSharing struct {
 // Filter is a filter function that implements the CORS flow as documented on http://enable-cors.org/server.html
 // and http://www.html5rocks.com/static/images/cors_server_flowchart.png
 func (c CrossOriginResourceSharing) Filter(req *Request, resp *Response, chain *FilterChain) {
+	c.configFor(req).filter(req, resp, chain)
+}
+
+// configFor returns the configuration from the KeyCORS metadata of the Route for the request, if any.
+func (c CrossOriginResourceSharing) configFor(req *Request) CrossOriginResourceSharing {
+	route := req.SelectedRoute()
+	if route == nil && c.Container != nil && req.Request.Method == "OPTIONS" {
+		if acrm := req.Request.Header.Get(HEADER_AccessControlRequestMethod); acrm != "" {
+			route = c.Container.routeForMethod(req.Request, acrm)
+		}
+	}
+	if route == nil {
+		return c
+	}
+	override, ok := route.Metadata[KeyCORS].(CrossOriginResourceSharing)
+	if !ok {
+		return c
+	}
+	if override.Container == nil {
+		override.Container = c.Container
+	}
+	return override
+}
+
+func (c CrossOriginResourceSharing) filter(req *Request, resp *Response, chain *FilterChain) {
 	origin := req.Request.Header.Get(HEADER_Origin)
 	if len(origin) == 0 {
 		if trace {
@@ -38,14 +70,7 @@ func (c CrossOriginResourceSharing) Filter(req *Request, resp *Response, chain *
 		return
 	}
 	if len(c.AllowedDomains) > 0 { // if provided then origin must be included
-		included := false
-		for _, each := range c.AllowedDomains {
-			if each == origin {
-				included = true
-				break
-			}
-		}
-		if !included {
+		if !c.isOriginAllowed(origin) {
 			if trace {
 				traceLogger.Printf("HTTP Origin:%s is not part of %v", origin, c.AllowedDomains)
 			}
@@ -109,7 +134,7 @@ func (c *CrossOriginResourceSharing) doPreflightRequest(req *Request, resp *Resp
 }
 
 func (c CrossOriginResourceSharing) setOptionsHeaders(req *Request, resp *Response) {
-	c.checkAndSetExposeHeaders(resp)
+	c.checkAndSetExposeHeaders(req, resp)
 	c.setAllowOriginHeader(req, resp)
 	c.checkAndSetAllowCredentials(resp)
 	if c.MaxAge > 0 {
@@ -126,7 +151,7 @@ func (c CrossOriginResourceSharing) isOriginAllowed(origin string) bool {
 	}
 	allowed := false
 	for _, each := range c.AllowedDomains {
-		if each == origin {
+		if each == origin || matchesOriginPattern(each, origin) {
 			allowed = true
 			break
 		}
@@ -134,17 +159,57 @@ func (c CrossOriginResourceSharing) isOriginAllowed(origin string) bool {
 	return allowed
 }
 
+// matchesOriginPattern returns whether the origin matches a pattern such as https://*.example.com
+// where the * stands for one or more subdomain labels.
+func matchesOriginPattern(pattern, origin string) bool {
+	star := strings.Index(pattern, "*")
+	if star == -1 {
+		return false
+	}
+	prefix, suffix := pattern[:star], pattern[star+1:]
+	if len(origin) <= len(prefix)+len(suffix) || !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	for _, each := range origin[len(prefix) : len(origin)-len(suffix)] {
+		if !(each == '-' || each == '.' || each >= 'a' && each <= 'z' || each >= 'A' && each <= 'Z' || each >= '0' && each <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
 func (c CrossOriginResourceSharing) setAllowOriginHeader(req *Request, resp *Response) {
 	origin := req.Request.Header.Get(HEADER_Origin)
 	if c.isOriginAllowed(origin) {
 		resp.AddHeader(HEADER_AccessControlAllowOrigin, origin)
+		// the response depends on the origin ; caches must not serve it to others
+		resp.AddHeader(HEADER_Vary, HEADER_Origin)
+	}
+}
+
+func (c CrossOriginResourceSharing) checkAndSetExposeHeaders(req *Request, resp *Response) {
+	exposed := c.ExposeHeaders
+	if route := req.SelectedRoute(); route != nil && len(route.ResponseHeaders) > 0 {
+		exposed = append([]string{}, exposed...)
+		for _, each := range route.ResponseHeaders {
+			if !containsHeaderName(exposed, each.Name) {
+				exposed = append(exposed, each.Name)
+			}
+		}
+	}
+	if len(exposed) > 0 {
+		resp.AddHeader(HEADER_AccessControlExposeHeaders, strings.Join(exposed, ","))
 	}
 }
 
-func (c CrossOriginResourceSharing) checkAndSetExposeHeaders(resp *Response) {
-	if len(c.ExposeHeaders) > 0 {
-		resp.AddHeader(HEADER_AccessControlExposeHeaders, strings.Join(c.ExposeHeaders, ","))
+// containsHeaderName returns whether the names include the name, ignoring case.
+func containsHeaderName(names []string, name string) bool {
+	for _, each := range names {
+		if strings.EqualFold(each, name) {
+			return true
+		}
 	}
+	return false
 }
 
 func (c CrossOriginResourceSharing) checkAndSetAllowCredentials(resp *Response) {
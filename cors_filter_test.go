@@ -123,3 +123,100 @@ func TestCORSFilter_AllowedDomains(t *testing.T) {
 		}
 	}
 }
+
+// go test -v -test.run TestCORSFilter_OriginPatterns ...restful
+func TestCORSFilter_OriginPatterns(t *testing.T) {
+	cors := CrossOriginResourceSharing{AllowedDomains: []string{"https://*.example.com", "http://localhost:8080"}}
+	for origin, want := range map[string]bool{
+		"https://api.example.com":       true,
+		"https://a.b.example.com":       true,
+		"https://example.com":           false,
+		"http://api.example.com":        false,
+		"https://evil.com/.example.com": false,
+		"https://evil.com?.example.com": false,
+		"https://api.example.com.evil":  false,
+		"http://localhost:8080":         true,
+	} {
+		if got := cors.isOriginAllowed(origin); got != want {
+			t.Errorf("%s: got %v want %v", origin, got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestCORSFilter_RouteOverride ...restful
+func TestCORSFilter_RouteOverride(t *testing.T) {
+	container := NewContainer()
+	cors := CrossOriginResourceSharing{AllowedDomains: []string{"https://app.example.com"}, Container: container}
+	container.Filter(cors.Filter)
+	partners := CrossOriginResourceSharing{
+		AllowedDomains: []string{"https://*.partner.com"},
+		ExposeHeaders:  []string{"X-Total-Count"},
+		CookiesAllowed: true,
+		MaxAge:         600}
+	ws := new(WebService).Path("/api")
+	ws.Route(ws.GET("/public").To(dummy))
+	ws.Route(ws.PUT("/shared").To(dummy).Consumes(MIME_JSON).Metadata(KeyCORS, partners))
+	container.Add(ws)
+	other := new(WebService).Path("/other").Metadata(KeyCORS, partners)
+	other.Route(other.GET("").To(dummy))
+	container.Add(other)
+
+	// actual request on Route with override
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/other", nil)
+	httpRequest.Header.Set(HEADER_Origin, "https://a.partner.com")
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlAllowOrigin), "https://a.partner.com"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlExposeHeaders), "X-Total-Count"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_Vary), HEADER_Origin; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+
+	// partner origin is not allowed on a Route without override
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/api/public", nil)
+	httpRequest.Header.Set(HEADER_Origin, "https://a.partner.com")
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got := httpWriter.Header().Get(HEADER_AccessControlAllowOrigin); got != "" {
+		t.Errorf("unexpected allowed origin %q", got)
+	}
+
+	// preflight uses the Route for the requested method
+	httpRequest, _ = http.NewRequest("OPTIONS", "http://here.com/api/shared", nil)
+	httpRequest.Header.Set(HEADER_Origin, "https://b.partner.com")
+	httpRequest.Header.Set(HEADER_AccessControlRequestMethod, "PUT")
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlAllowOrigin), "https://b.partner.com"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlAllowCredentials), "true"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlMaxAge), "600"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestCORSFilter_ExposeReturnedHeaders ...restful
+func TestCORSFilter_ExposeReturnedHeaders(t *testing.T) {
+	container := NewContainer()
+	container.Filter(CrossOriginResourceSharing{ExposeHeaders: []string{"X-Request-Id", "x-total-count"}, Container: container}.Filter)
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("").To(dummy).
+		ReturnsHeader("X-Total-Count", "number of items").
+		ReturnsHeader(HEADER_RetryAfter, "seconds until the rate limit resets"))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/items", nil)
+	httpRequest.Header.Set(HEADER_Origin, "https://app.example.com")
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_AccessControlExposeHeaders), "X-Request-Id,x-total-count,Retry-After"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+)
+
+const (
+	// KeyCSRFExempt is the Route metadata key that, if set to true, exempts the Route from CSRF protection.
+	KeyCSRFExempt = "restful.csrfExempt"
+	// AttributeCSRFToken is the name of the Request attribute with the CSRF token that clients must submit ; see CSRFToken.
+	AttributeCSRFToken = "restful.csrfToken"
+)
+
+// CSRFTokenSource provides the token that a request with a state-changing method must submit.
+// The default is a double-submit cookie ; a source backed by a server-side session implements the synchronizer token pattern.
+type CSRFTokenSource interface {
+	// Token returns the expected token for the request, creating (and e.g. setting a cookie) if needed.
+	Token(req *Request, resp *Response) (string, error)
+}
+
+// CSRFProtection is used to create a Filter that protects against cross-site request forgery.
+// Requests with methods other than GET, HEAD, OPTIONS and TRACE must submit the token of the TokenSource
+// in a header or form field, or they get a 403 (Forbidden) problem document.
+// The token is available to RouteFunctions, e.g. for rendering forms, using CSRFToken.
+//
+//	restful.Filter(restful.CSRFProtection{}.Filter)
+type CSRFProtection struct {
+	TokenSource CSRFTokenSource // default is a double-submit cookie named "csrf_token"
+	HeaderName  string          // default is "X-CSRF-Token"
+	FormField   string          // default is "csrf_token" ; set to "-" to not read forms
+	// Extract, if set, returns the token submitted with the request instead of using HeaderName and FormField.
+	Extract func(req *Request) string
+	// Exempt, if set, returns whether the request is not protected, in addition to Routes with KeyCSRFExempt metadata.
+	Exempt func(req *Request) bool
+}
+
+// Filter checks the submitted token for state-changing requests.
+func (c CSRFProtection) Filter(req *Request, resp *Response, chain *FilterChain) {
+	source := c.TokenSource
+	if source == nil {
+		source = CSRFCookie{}
+	}
+	expected, err := source.Token(req, resp)
+	if err != nil {
+		resp.WriteProblem(NewProblemDocument(http.StatusInternalServerError, err.Error()))
+		return
+	}
+	req.SetAttribute(AttributeCSRFToken, expected)
+	if isSafeMethod(req.Request.Method) || c.exempt(req) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	submitted := c.submitted(req)
+	if len(submitted) == 0 || subtle.ConstantTimeCompare([]byte(submitted), []byte(expected)) != 1 {
+		resp.WriteProblem(NewProblemDocument(http.StatusForbidden, "missing or invalid CSRF token"))
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+func (c CSRFProtection) exempt(req *Request) bool {
+	if route := req.SelectedRoute(); route != nil {
+		if exempt, _ := route.Metadata[KeyCSRFExempt].(bool); exempt {
+			return true
+		}
+	}
+	return c.Exempt != nil && c.Exempt(req)
+}
+
+func (c CSRFProtection) submitted(req *Request) string {
+	if c.Extract != nil {
+		return c.Extract(req)
+	}
+	headerName := c.HeaderName
+	if len(headerName) == 0 {
+		headerName = "X-CSRF-Token"
+	}
+	if token := req.Request.Header.Get(headerName); len(token) > 0 {
+		return token
+	}
+	formField := c.FormField
+	if formField == "-" {
+		return ""
+	}
+	if len(formField) == 0 {
+		formField = "csrf_token"
+	}
+	token, _ := req.BodyParameter(formField)
+	return token
+}
+
+// CSRFToken returns the token that the client must submit with state-changing requests.
+// It is empty if the request was not processed by a CSRFProtection filter.
+func CSRFToken(req *Request) string {
+	token, _ := req.Attribute(AttributeCSRFToken).(string)
+	return token
+}
+
+// CSRFCookie is a CSRFTokenSource that keeps a random token in a cookie (double-submit cookie pattern).
+// The cookie is not HttpOnly because browser scripts must read it to submit it as a header.
+type CSRFCookie struct {
+	Name     string // default is "csrf_token"
+	Path     string // default is "/"
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite // default is http.SameSiteLaxMode
+}
+
+// Token is part of CSRFTokenSource
+func (c CSRFCookie) Token(req *Request, resp *Response) (string, error) {
+	name := c.Name
+	if len(name) == 0 {
+		name = "csrf_token"
+	}
+	if cookie, err := req.Request.Cookie(name); err == nil && len(cookie.Value) > 0 {
+		return cookie.Value, nil
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(random)
+	path := c.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+	sameSite := c.SameSite
+	if sameSite == 0 {
+		sameSite = http.SameSiteLaxMode
+	}
+	http.SetCookie(resp, &http.Cookie{Name: name, Value: token, Path: path, Domain: c.Domain, Secure: c.Secure, SameSite: sameSite})
+	return token, nil
+}
+
+// isSafeMethod returns whether the Http method is not meant to change state.
+func isSafeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+	return false
+}
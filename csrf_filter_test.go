@@ -0,0 +1,61 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestCSRFProtection ...restful
+func TestCSRFProtection(t *testing.T) {
+	container := NewContainer()
+	container.Filter(CSRFProtection{}.Filter)
+	ws := new(WebService).Path("/form")
+	var token string
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { token = CSRFToken(req) }))
+	ws.Route(ws.POST("").To(dummy))
+	ws.Route(ws.POST("/webhook").To(dummy).Metadata(KeyCSRFExempt, true))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/form", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	cookies := httpWriter.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != token || len(token) == 0 {
+		t.Fatalf("expected cookie with token %q, got %v", token, cookies)
+	}
+
+	tests := []struct {
+		path, header, form string
+		cookie             bool
+		code               int
+	}{
+		{"/form", "", "", true, http.StatusForbidden},
+		{"/form", token, "", true, http.StatusOK},
+		{"/form", "", token, true, http.StatusOK},
+		{"/form", "wrong", "", true, http.StatusForbidden},
+		{"/form", token, "", false, http.StatusForbidden},
+		{"/form/webhook", "", "", false, http.StatusOK},
+	}
+	for i, each := range tests {
+		form := url.Values{}
+		if len(each.form) > 0 {
+			form.Set("csrf_token", each.form)
+		}
+		httpRequest, _ := http.NewRequest("POST", "http://here.com"+each.path, strings.NewReader(form.Encode()))
+		httpRequest.Header.Set(HEADER_ContentType, "application/x-www-form-urlencoded")
+		if len(each.header) > 0 {
+			httpRequest.Header.Set("X-CSRF-Token", each.header)
+		}
+		if each.cookie {
+			httpRequest.AddCookie(cookies[0])
+		}
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%d: got %v want %v", i, got, want)
+		}
+	}
+}
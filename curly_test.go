@@ -117,11 +117,10 @@ var routeMatchers = []struct {
 
 // clear && go test -v -test.run Test_matchesRouteByPathTokens ...restful
 func Test_matchesRouteByPathTokens(t *testing.T) {
-	router := CurlyRouter{}
 	for i, each := range routeMatchers {
 		routeToks := tokenizePath(each.route)
 		reqToks := tokenizePath(each.path)
-		matches, pCount, sCount := router.matchesRouteByPathTokens(routeToks, reqToks)
+		matches, pCount, sCount := newRouteTemplate(routeToks).match(reqToks)
 		if matches != each.matches {
 			t.Fatalf("[%d] unexpected matches outcome route:%s, path:%s, matches:%v", i, each.route, each.path, matches)
 		}
@@ -0,0 +1,91 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide registers the value as the dependency of its type, replacing any previous value of that type.
+// WebServices typically resolve dependencies using Require in an OnAttach callback.
+//
+//	container.Provide(dbPool) // *sql.DB
+func (c *Container) Provide(value interface{}) {
+	if value == nil {
+		return
+	}
+	c.dependenciesLock.Lock()
+	defer c.dependenciesLock.Unlock()
+	if c.dependencies == nil {
+		c.dependencies = map[reflect.Type]interface{}{}
+	}
+	c.dependencies[reflect.TypeOf(value)] = value
+}
+
+// Require sets the variable that target points to with the provided dependency of its type.
+// If target points to an interface variable, the single provided value that implements it is used.
+// It returns an error if no or more than one value is provided.
+//
+//	var db *sql.DB
+//	if err := container.Require(&db); err != nil { ...
+func (c *Container) Require(target interface{}) error {
+	pointer := reflect.ValueOf(target)
+	if pointer.Kind() != reflect.Ptr || pointer.IsNil() {
+		return fmt.Errorf("Require needs a non-nil pointer, got %T", target)
+	}
+	wanted := pointer.Type().Elem()
+	c.dependenciesLock.RLock()
+	defer c.dependenciesLock.RUnlock()
+	if value, ok := c.dependencies[wanted]; ok {
+		pointer.Elem().Set(reflect.ValueOf(value))
+		return nil
+	}
+	if wanted.Kind() != reflect.Interface {
+		return fmt.Errorf("no dependency provided of type %v", wanted)
+	}
+	var found interface{}
+	for each, value := range c.dependencies {
+		if each.Implements(wanted) {
+			if found != nil {
+				return fmt.Errorf("more than one dependency provided that implements %v", wanted)
+			}
+			found = value
+		}
+	}
+	if found == nil {
+		return fmt.Errorf("no dependency provided that implements %v", wanted)
+	}
+	pointer.Elem().Set(reflect.ValueOf(found))
+	return nil
+}
+
+// OnAttach adds a callback that is called when the WebService is added to a Container.
+// It is called after the WebService is registered, so it may call Container methods such as Require.
+func (w *WebService) OnAttach(callback func(c *Container)) *WebService {
+	w.onAttach = append(w.onAttach, callback)
+	return w
+}
+
+// OnDetach adds a callback that is called when the WebService is removed from a Container,
+// e.g. to release resources acquired in an OnAttach callback.
+func (w *WebService) OnDetach(callback func(c *Container)) *WebService {
+	w.onDetach = append(w.onDetach, callback)
+	return w
+}
+
+// attached calls the OnAttach callbacks.
+func (w *WebService) attached(c *Container) {
+	for _, each := range w.onAttach {
+		each(c)
+	}
+}
+
+// detached calls the OnDetach callbacks.
+func (w *WebService) detached(c *Container) {
+	for _, each := range w.onDetach {
+		each(c)
+	}
+}
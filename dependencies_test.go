@@ -0,0 +1,66 @@
+package restful
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// go test -v -test.run TestProvideRequire ...restful
+func TestProvideRequire(t *testing.T) {
+	container := NewContainer()
+	buffer := new(bytes.Buffer)
+	container.Provide(buffer)
+
+	var got *bytes.Buffer
+	if err := container.Require(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got != buffer {
+		t.Error("expected provided buffer")
+	}
+	var writer io.Writer
+	if err := container.Require(&writer); err != nil {
+		t.Fatal(err)
+	}
+	if writer != buffer {
+		t.Error("expected provided buffer as io.Writer")
+	}
+	var missing *Container
+	if err := container.Require(&missing); err == nil {
+		t.Error("expected error for missing dependency")
+	}
+	container.Provide(new(bytes.Reader))
+	var reader io.Reader // implemented by both
+	if err := container.Require(&reader); err == nil {
+		t.Error("expected error for ambiguous dependency")
+	}
+	if err := container.Require(got); err == nil {
+		t.Error("expected error for non-pointer to variable")
+	}
+}
+
+// go test -v -test.run TestOnAttachDetach ...restful
+func TestOnAttachDetach(t *testing.T) {
+	container := NewContainer()
+	container.Provide(new(bytes.Buffer))
+	var buffer *bytes.Buffer
+	detached := false
+	ws := new(WebService).Path("/attach")
+	ws.OnAttach(func(c *Container) {
+		if err := c.Require(&buffer); err != nil {
+			t.Error(err)
+		}
+	}).OnDetach(func(c *Container) {
+		detached = true
+	})
+	ws.Route(ws.GET("").To(dummy))
+	container.Add(ws)
+	if buffer == nil {
+		t.Error("expected dependency to be resolved on attach")
+	}
+	container.Remove(ws)
+	if !detached {
+		t.Error("expected OnDetach to be called")
+	}
+}
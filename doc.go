@@ -28,6 +28,10 @@ This package has the logic to find the best matching Route and if found, call it
 
 The (*Request, *Response) arguments provide functions for reading information from the request and writing information back to the response.
 
+Besides the shortcuts (GET,PUT,POST,...), a Route can be defined for any HTTP method, including extension methods such as those of WebDAV.
+
+	ws.Route(ws.Method("PROPFIND").Path("/{user-id}").To(u.userProperties))
+
 See the example https://github.com/emicklei/go-restful/blob/master/examples/restful-user-resource.go with a full implementation.
 
 Regular expression matching Routes
@@ -155,7 +159,13 @@ The CurlyRouter implementation is more lightweight that also allows you to use w
 
 DoNotRecover controls whether panics will be caught to return HTTP 500.
 If set to true, Route functions are responsible for handling any error situation.
-Default value is false; it will recover from panics. This has performance implications.
+Default value is false; it will recover from panics.
+
+	restful.DefaultContainer.Recovery(restful.PropagatePanics)
+	ws.Route(ws.GET("/orders").To(listOrders).Recovery(restful.RecoverWith(writeUnavailable)))
+
+A RecoveryPolicy (RecoverAndLog, RecoverWith a handler, or PropagatePanics) can be set for the Container
+and overridden per Route, e.g. to let test servers crash loudly while production routes degrade gracefully.
 
 	restful.SetCacheReadEntity(false)
 
@@ -167,6 +177,29 @@ If you expect to read large amounts of payload data, and you do not use this fea
 If content encoding is enabled then the default strategy for getting new gzip/zlib writers and readers is to use a sync.Pool.
 Because writers are expensive structures, performance is even more improved when using a preloaded cache. You can also inject your own implementation.
 
+	restful.DefaultContainer.ContentEncodingThreshold(1400)
+
+With a threshold, response bodies are buffered up to that size before deciding to compress ; smaller ones are written
+as is, with a Content-Length, and do not acquire a writer from the CompressorProvider.
+
+	restful.SetNegotiationCacheSize(1024)
+
+The results of content negotiation are cached by the raw Accept and Content-Type headers in a bounded LRU.
+Services that see many distinct headers can increase the size ; 0 disables caching.
+
+	restful.DefaultContainer.EnableWrapperPooling(true)
+
+EnableWrapperPooling reuses the Request and Response of a dispatched request for a next one.
+Only enable it if no Route function or filter keeps a reference to either after it returns.
+The benchmarks package has benchmarks to measure the effect of these options.
+
+Stores
+
+The RateLimiter, ResponseCache and Idempotency filters keep their state in a store (RateLimitStore,
+ResponseCacheStore and IdempotencyStore) that is called by concurrent requests ; implementations must be safe for concurrent use.
+The stores of this package (NewMemoryRateLimitStore, NewLRUResponseCacheStore and NewMemoryIdempotencyStore) are per process ;
+a store that is shared by all instances, e.g. backed by Redis, is needed for the filters to work across a cluster.
+
 Trouble shooting
 
 This package has the means to produce detail logging of the complete Http request matching process and filter invocation.
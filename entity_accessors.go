@@ -27,17 +27,21 @@ type EntityReaderWriter interface {
 var entityAccessRegistry = &entityReaderWriters{
 	protection: new(sync.RWMutex),
 	accessors:  map[string]EntityReaderWriter{},
+	cache:      newLRUCache[EntityReaderWriter](DefaultNegotiationCacheSize),
 }
 
 // entityReaderWriters associates MIME to an EntityReaderWriter
 type entityReaderWriters struct {
 	protection *sync.RWMutex
 	accessors  map[string]EntityReaderWriter
+	cache      *lruCache[EntityReaderWriter] // of reverse lookups ; nil if none matched
 }
 
 func init() {
 	RegisterEntityAccessor(MIME_JSON, entityJSONAccess{ContentType: MIME_JSON})
 	RegisterEntityAccessor(MIME_XML, entityXMLAccess{ContentType: MIME_XML})
+	RegisterEntityAccessor(MIME_GRPC_WEB, entityGRPCWebAccess{ContentType: MIME_GRPC_WEB})
+	RegisterEntityAccessor(MIME_GRPC_WEB_PROTO, entityGRPCWebAccess{ContentType: MIME_GRPC_WEB_PROTO})
 }
 
 // RegisterEntityAccessor add/overrides the ReaderWriter for encoding content with this MIME type.
@@ -45,6 +49,7 @@ func RegisterEntityAccessor(mime string, erw EntityReaderWriter) {
 	entityAccessRegistry.protection.Lock()
 	defer entityAccessRegistry.protection.Unlock()
 	entityAccessRegistry.accessors[mime] = erw
+	entityAccessRegistry.cache.clear()
 }
 
 // AccessorAt returns the registered ReaderWriter for this MIME type.
@@ -53,17 +58,36 @@ func (r *entityReaderWriters) AccessorAt(mime string) (EntityReaderWriter, bool)
 	defer r.protection.RUnlock()
 	er, ok := r.accessors[mime]
 	if !ok {
+		if cached, found := r.cache.get(mime); found {
+			return cached, cached != nil
+		}
 		// retry with reverse lookup
 		// more expensive but we are in an exceptional situation anyway
 		for k, v := range r.accessors {
 			if strings.Contains(mime, k) {
+				r.cache.set(mime, v)
 				return v, true
 			}
 		}
+		r.cache.set(mime, nil)
 	}
 	return er, ok
 }
 
+// missingEntityWriters returns the MIME types for which no EntityReaderWriter is registered.
+// The wildcard */* is never missing.
+func missingEntityWriters(mimeTypes []string) (missing []string) {
+	for _, each := range mimeTypes {
+		if each == "*/*" {
+			continue
+		}
+		if _, ok := entityAccessRegistry.AccessorAt(each); !ok {
+			missing = append(missing, each)
+		}
+	}
+	return missing
+}
+
 // entityXMLAccess is a EntityReaderWriter for XML encoding
 type entityXMLAccess struct {
 	// This is used for setting the Content-Type header when writing
@@ -87,7 +111,7 @@ func writeXML(resp *Response, status int, contentType string, v interface{}) err
 		// do not write a nil representation
 		return nil
 	}
-	if resp.prettyPrint {
+	if resp.renderOptions.PrettyPrint {
 		// pretty output must be created and written explicitly
 		output, err := xml.MarshalIndent(v, " ", " ")
 		if err != nil {
@@ -116,6 +140,9 @@ type entityJSONAccess struct {
 
 // Read unmarshalls the value from JSON
 func (e entityJSONAccess) Read(req *Request, v interface{}) error {
+	if req.isStrictJSON() {
+		return readStrictJSON(req, v)
+	}
 	decoder := json.NewDecoder(req.Request.Body)
 	decoder.UseNumber()
 	return decoder.Decode(v)
@@ -128,12 +155,22 @@ func (e entityJSONAccess) Write(resp *Response, status int, v interface{}) error
 
 // write marshalls the value to JSON and set the Content-Type Header.
 func writeJSON(resp *Response, status int, contentType string, v interface{}) error {
+	if resp.renderOptions.CanonicalJSON {
+		return writeCanonicalJSON(resp, status, contentType, v)
+	}
 	if v == nil {
 		resp.WriteHeader(status)
 		// do not write a nil representation
 		return nil
 	}
-	if resp.prettyPrint {
+	if fields := resp.sparseFields(status); len(fields) > 0 {
+		pruned, err := pruneJSON(v, fields)
+		if err != nil {
+			return err
+		}
+		v = pruned
+	}
+	if resp.renderOptions.PrettyPrint {
 		// pretty output must be created and written explicitly
 		output, err := json.MarshalIndent(v, " ", " ")
 		if err != nil {
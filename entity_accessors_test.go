@@ -49,7 +49,7 @@ func TestKeyValueEncoding(t *testing.T) {
 	// Write
 	httpWriter := httptest.NewRecorder()
 	//								Accept									Produces
-	resp := Response{httpWriter, "application/kv,*/*;q=0.8", []string{"application/kv"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/kv,*/*;q=0.8", routeProduces: []string{"application/kv"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteEntity(b)
 	t.Log(string(httpWriter.Body.Bytes()))
 	if !kv.writeCalled {
@@ -67,3 +67,10 @@ func TestKeyValueEncoding(t *testing.T) {
 		t.Error("Read never called")
 	}
 }
+
+func TestMissingEntityWriters(t *testing.T) {
+	missing := missingEntityWriters([]string{MIME_JSON, "*/*", "text/csv"})
+	if len(missing) != 1 || missing[0] != "text/csv" {
+		t.Errorf("unexpected missing writers %v", missing)
+	}
+}
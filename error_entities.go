@@ -0,0 +1,43 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "net/http"
+
+// WriteErrorEntity writes the status and the entity, describing the error, using the EntityReaderWriter
+// negotiated by the Accept header, such that error payloads have the same representation as other entities.
+// If no EntityReaderWriter matches, the error string is written instead (see WriteErrorString).
+// For a 5xx status, the entity is replaced by a ServiceError with the status text if the Response must be terse
+// (see Container.ErrorVerbosity).
+func (r *Response) WriteErrorEntity(httpStatus int, err error, entity interface{}) error {
+	r.err = err
+	if r.terseError(httpStatus) {
+		entity = NewError(httpStatus, http.StatusText(httpStatus))
+	}
+	writer, ok := r.EntityWriter()
+	if !ok || entity == nil {
+		return r.WriteErrorString(httpStatus, err.Error())
+	}
+	err = writer.Write(r, httpStatus, entity)
+	r.entityWritten(entity, err)
+	return err
+}
+
+// ServiceErrorEntityHandler returns a ServiceErrorHandleFunction that writes ServiceErrors using WriteErrorEntity.
+// The shape function returns the entity for an error ; if nil then the ServiceError itself is the entity.
+// Use it to have errors detected by the Container (e.g. 404, 405, 406, 415) rendered like all other error payloads.
+//
+//	restful.DefaultContainer.ServiceErrorHandler(restful.ServiceErrorEntityHandler(func(err restful.ServiceError, req *restful.Request) interface{} {
+//		return ErrorPayload{Code: err.Code, Reason: err.Message}
+//	}))
+func ServiceErrorEntityHandler(shape func(err ServiceError, req *Request) interface{}) ServiceErrorHandleFunction {
+	return func(err ServiceError, req *Request, resp *Response) {
+		var entity interface{} = err
+		if shape != nil {
+			entity = shape(err, req)
+		}
+		resp.WriteErrorEntity(err.Code, err, entity)
+	}
+}
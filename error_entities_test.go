@@ -0,0 +1,64 @@
+package restful
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type errorPayload struct {
+	Code   int    `json:"code" xml:"code"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+// go test -v -test.run TestWriteErrorEntity ...restful
+func TestWriteErrorEntity(t *testing.T) {
+	for _, each := range []struct {
+		accept, contentType, body string
+	}{
+		{MIME_JSON, MIME_JSON, `"reason": "out of stock"`},
+		{MIME_XML, MIME_XML, `<reason>out of stock</reason>`},
+		{"text/csv", "", `out of stock`},
+	} {
+		recorder := httptest.NewRecorder()
+		resp := NewResponse(recorder)
+		resp.SetRequestAccepts(each.accept)
+		resp.routeProduces = []string{MIME_JSON, MIME_XML}
+		resp.WriteErrorEntity(http.StatusConflict, errors.New("out of stock"), errorPayload{Code: 1, Reason: "out of stock"})
+		if got, want := recorder.Code, http.StatusConflict; got != want {
+			t.Errorf("%s: got %v want %v", each.accept, got, want)
+		}
+		if got, want := recorder.Header().Get(HEADER_ContentType), each.contentType; got != want {
+			t.Errorf("%s: got %v want %v", each.accept, got, want)
+		}
+		if got, want := recorder.Body.String(), each.body; !strings.Contains(got, want) {
+			t.Errorf("%s: got %v want it to contain %v", each.accept, got, want)
+		}
+		if resp.Error() == nil {
+			t.Errorf("%s: expected error to be kept", each.accept)
+		}
+	}
+}
+
+// go test -v -test.run TestServiceErrorEntityHandler ...restful
+func TestServiceErrorEntityHandler(t *testing.T) {
+	c := NewContainer()
+	c.ServiceErrorHandler(ServiceErrorEntityHandler(func(err ServiceError, req *Request) interface{} {
+		return errorPayload{Code: err.Code, Reason: err.Message}
+	}))
+	ws := new(WebService).Path("/shaped").Produces(MIME_JSON)
+	ws.Route(ws.GET("").To(dummy))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("DELETE", "/shaped", nil)
+	httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httpRequest)
+	if got, want := recorder.Code, http.StatusMethodNotAllowed; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := recorder.Body.String(), `"code": 405`; !strings.Contains(got, want) {
+		t.Errorf("got %v want it to contain %v", got, want)
+	}
+}
@@ -0,0 +1,137 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrorVerbosity decides, per request, whether the body of a 5xx response may include
+// diagnostics (messages, stack, request attributes) or must be terse.
+// Diagnostics are allowed for requests from an internal network or with a valid signed debug header.
+// See Container.ErrorVerbosity.
+type ErrorVerbosity struct {
+	// InternalNetworks lists the networks (e.g. 10.0.0.0/8) of clients that get diagnostics.
+	InternalNetworks []*net.IPNet
+	// DebugHeader is the name of the header with a signed debug token ; default is "X-Debug-Token".
+	// Its value is "<unix seconds>:<hex HMAC-SHA256 of the unix seconds using Secret>" ; see SignDebugToken.
+	DebugHeader string
+	// Secret is the key of the debug token signature. If empty, debug tokens are not accepted.
+	Secret []byte
+	// MaxAge is the maximum age of a debug token ; default is 5 minutes.
+	MaxAge time.Duration
+}
+
+// errorDetailLevel is the verbosity of 5xx response bodies.
+type errorDetailLevel int
+
+const (
+	defaultErrorDetail errorDetailLevel = iota // no ErrorVerbosity configured ; messages as given, no diagnostics
+	terseErrorDetail                           // status text only
+	verboseErrorDetail                         // messages and diagnostics
+)
+
+// ErrorVerbosity makes the bodies of 5xx responses terse unless the policy allows diagnostics for the request.
+// Use Response.VerboseErrors in a RouteFunction to decide whether to include details.
+func (c *Container) ErrorVerbosity(policy ErrorVerbosity) {
+	c.errorVerbosity = &policy
+}
+
+// ParseNetworks returns the networks of the CIDR notations, e.g. "10.0.0.0/8".
+func ParseNetworks(cidrs ...string) ([]*net.IPNet, error) {
+	networks := []*net.IPNet{}
+	for _, each := range cidrs {
+		_, network, err := net.ParseCIDR(each)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// SignDebugToken returns the value of a debug header that is valid for MaxAge after the time.
+func SignDebugToken(secret []byte, at time.Time) string {
+	seconds := strconv.FormatInt(at.Unix(), 10)
+	return seconds + ":" + debugTokenSignature(secret, seconds)
+}
+
+func debugTokenSignature(secret []byte, seconds string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(seconds))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verbose returns whether the request may get diagnostics in 5xx responses.
+func (v ErrorVerbosity) Verbose(httpRequest *http.Request) bool {
+	if len(v.InternalNetworks) > 0 {
+		host, _, err := net.SplitHostPort(httpRequest.RemoteAddr)
+		if err != nil {
+			host = httpRequest.RemoteAddr
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			for _, each := range v.InternalNetworks {
+				if each.Contains(ip) {
+					return true
+				}
+			}
+		}
+	}
+	if len(v.Secret) == 0 {
+		return false
+	}
+	headerName := v.DebugHeader
+	if len(headerName) == 0 {
+		headerName = "X-Debug-Token"
+	}
+	token := httpRequest.Header.Get(headerName)
+	colon := strings.Index(token, ":")
+	if colon == -1 {
+		return false
+	}
+	seconds, signature := token[:colon], token[colon+1:]
+	if !hmac.Equal([]byte(signature), []byte(debugTokenSignature(v.Secret, seconds))) {
+		return false
+	}
+	signedAt, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return false
+	}
+	maxAge := v.MaxAge
+	if maxAge == 0 {
+		maxAge = 5 * time.Minute
+	}
+	age := time.Since(time.Unix(signedAt, 0))
+	return age >= -time.Minute && age <= maxAge
+}
+
+// errorDetailLevel returns the errorDetailLevel for the request as configured by the Container.
+func (c *Container) errorDetailLevel(httpRequest *http.Request) errorDetailLevel {
+	if c.errorVerbosity == nil {
+		return defaultErrorDetail
+	}
+	if c.errorVerbosity.Verbose(httpRequest) {
+		return verboseErrorDetail
+	}
+	return terseErrorDetail
+}
+
+// VerboseErrors returns whether the body of a 5xx response for this request may include diagnostics.
+// It is true only if the Container has an ErrorVerbosity that allows it for the request.
+func (r *Response) VerboseErrors() bool {
+	return r.errorDetail == verboseErrorDetail
+}
+
+// terseError returns whether the body of a response with this status must not include details.
+func (r *Response) terseError(httpStatus int) bool {
+	return httpStatus >= 500 && r.errorDetail == terseErrorDetail
+}
@@ -0,0 +1,86 @@
+package restful
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestErrorVerbosity ...restful
+func TestErrorVerbosity(t *testing.T) {
+	networks, err := ParseNetworks("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("secret")
+	container := NewContainer()
+	container.ErrorVerbosity(ErrorVerbosity{InternalNetworks: networks, Secret: secret})
+	ws := new(WebService).Path("/fail")
+	ws.Route(ws.GET("/error").To(func(req *Request, resp *Response) {
+		resp.WriteError(http.StatusInternalServerError, errors.New("database password is wrong"))
+	}))
+	ws.Route(ws.GET("/notfound").To(func(req *Request, resp *Response) {
+		resp.WriteErrorString(http.StatusNotFound, "no such user")
+	}))
+	container.Add(ws)
+
+	tests := []struct {
+		path, remoteAddr, token, body string
+	}{
+		{"/fail/error", "192.168.1.1:80", "", "Internal Server Error"},
+		{"/fail/error", "10.1.2.3:80", "", "database password is wrong"},
+		{"/fail/error", "192.168.1.1:80", SignDebugToken(secret, time.Now()), "database password is wrong"},
+		{"/fail/error", "192.168.1.1:80", SignDebugToken([]byte("guess"), time.Now()), "Internal Server Error"},
+		{"/fail/error", "192.168.1.1:80", SignDebugToken(secret, time.Now().Add(-time.Hour)), "Internal Server Error"},
+		{"/fail/notfound", "192.168.1.1:80", "", "no such user"},
+	}
+	for i, each := range tests {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+each.path, nil)
+		httpRequest.RemoteAddr = each.remoteAddr
+		if len(each.token) > 0 {
+			httpRequest.Header.Set("X-Debug-Token", each.token)
+		}
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Body.String(), each.body; got != want {
+			t.Errorf("%d: got %q want %q", i, got, want)
+		}
+	}
+}
+
+func TestErrorVerbosity_PanicRecovery(t *testing.T) {
+	networks, _ := ParseNetworks("10.0.0.0/8")
+	container := NewContainer()
+	container.ErrorVerbosity(ErrorVerbosity{InternalNetworks: networks})
+	container.Filter(PanicRecovery{Logger: testLogger{t}}.Filter)
+	ws := new(WebService).Path("/panic")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		req.SetAttribute("user", "joe")
+		panic("boom")
+	}))
+	container.Add(ws)
+
+	for _, remoteAddr := range []string{"10.0.0.1:80", "192.168.1.1:80"} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/panic", nil)
+		httpRequest.RemoteAddr = remoteAddr
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		var problem ProblemDocument
+		if err := json.Unmarshal(httpWriter.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		internal := remoteAddr == "10.0.0.1:80"
+		if got, want := problem.Detail == "boom", internal; got != want {
+			t.Errorf("%s: detail %q", remoteAddr, problem.Detail)
+		}
+		if got, want := problem.Diagnostics != nil, internal; got != want {
+			t.Errorf("%s: diagnostics %v", remoteAddr, problem.Diagnostics)
+		}
+		if internal && problem.Diagnostics["attributes"].(map[string]interface{})["user"] != "joe" {
+			t.Errorf("missing attribute in %v", problem.Diagnostics)
+		}
+	}
+}
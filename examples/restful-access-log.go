@@ -0,0 +1,53 @@
+package main
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+)
+
+// This example shows how to send the entries of the AccessLog filter
+// to logrus and to zap. Use restful.SlogAccessLogSink for log/slog.
+//
+// GET http://localhost:8080/ping
+
+func logrusSink(logger *logrus.Logger) restful.AccessLogSink {
+	return restful.AccessLogSinkFunc(func(entry restful.AccessLogEntry) {
+		logger.WithFields(logrus.Fields{
+			"method":  entry.Method,
+			"route":   entry.RoutePath,
+			"status":  entry.Status,
+			"bytes":   entry.ContentLength,
+			"latency": entry.Latency,
+		}).Info("access")
+	})
+}
+
+func zapSink(logger *zap.Logger) restful.AccessLogSink {
+	return restful.AccessLogSinkFunc(func(entry restful.AccessLogEntry) {
+		logger.Info("access",
+			zap.String("method", entry.Method),
+			zap.String("route", entry.RoutePath),
+			zap.Int("status", entry.Status),
+			zap.Int("bytes", entry.ContentLength),
+			zap.Duration("latency", entry.Latency))
+	})
+}
+
+func main() {
+	zapLogger, _ := zap.NewProduction()
+	restful.Filter(restful.AccessLog{Sink: zapSink(zapLogger), RequestHeaders: []string{"User-Agent"}}.Filter)
+
+	ws := new(restful.WebService)
+	ws.Filter(restful.AccessLog{Sink: logrusSink(logrus.New())}.Filter)
+	ws.Route(ws.GET("/ping").To(hello))
+	restful.Add(ws)
+	http.ListenAndServe(":8080", nil)
+}
+
+func hello(req *restful.Request, resp *restful.Response) {
+	io.WriteString(resp, "pong")
+}
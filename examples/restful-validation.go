@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-playground/validator/v10"
+)
+
+// This example shows how to validate entities and parameters using github.com/go-playground/validator.
+// The Validator is set once on the container and runs after ReadEntity and BindParameters.
+//
+// POST http://localhost:8080/users with {"name":"","email":"nope"}
+// -> 422 with errors for name and email
+//
+// GET http://localhost:8080/users?limit=1000
+// -> 400 with an error for limit
+
+// playgroundValidator adapts a validator.Validate to the restful.Validator interface.
+type playgroundValidator struct {
+	validate *validator.Validate
+}
+
+func (p playgroundValidator) Validate(value interface{}) error {
+	err := p.validate.Struct(value)
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err // nil or not about fields
+	}
+	fields := []restful.FieldError{}
+	for _, each := range invalid {
+		fields = append(fields, restful.FieldError{Field: each.Namespace(), Message: "failed on the '" + each.Tag() + "' rule"})
+	}
+	return restful.ValidationError{Fields: fields}
+}
+
+type User struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+type listParameters struct {
+	Limit int `query:"limit" validate:"min=1,max=100"`
+}
+
+func createUser(req *restful.Request, resp *restful.Response) {
+	user := new(User)
+	if err := req.ReadEntity(user); err != nil {
+		resp.WriteValidationError(err)
+		return
+	}
+	resp.WriteHeaderAndEntity(http.StatusCreated, user)
+}
+
+func listUsers(req *restful.Request, resp *restful.Response) {
+	params := listParameters{Limit: 10}
+	if err := req.BindParameters(&params); err != nil {
+		resp.WriteValidationError(err)
+		return
+	}
+	resp.WriteEntity([]User{})
+}
+
+func main() {
+	restful.DefaultContainer.Validator(playgroundValidator{validate: validator.New()})
+
+	ws := new(restful.WebService).Path("/users").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.POST("").To(createUser))
+	ws.Route(ws.GET("").To(listUsers))
+	restful.Add(ws)
+	http.ListenAndServe(":8080", nil)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+)
+
+// This example shows how to serve a WebSocket endpoint on the same WebService as regular routes,
+// protected by the same basic authentication filter, using github.com/gorilla/websocket.
+//
+// GET http://localhost:8080/chat/messages (user:admin, password:secret)
+// -> 426 Upgrade Required
+//
+// ws://admin:secret@localhost:8080/chat/messages
+// -> echoes each message
+
+var upgrader = websocket.Upgrader{}
+
+func basicAuthenticate(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	if user, password, ok := req.Request.BasicAuth(); !ok || user != "admin" || password != "secret" {
+		resp.Header().Set("WWW-Authenticate", "Basic realm=chat")
+		resp.WriteErrorString(http.StatusUnauthorized, "401: Not Authorized")
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+func echo(w http.ResponseWriter, r *http.Request) {
+	// the restful.Response is passed as w and implements http.Hijacker
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Print("upgrade failed: ", err)
+		return
+	}
+	defer conn.Close()
+	for {
+		kind, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(kind, message); err != nil {
+			return
+		}
+	}
+}
+
+func main() {
+	ws := new(restful.WebService).Path("/chat").Filter(basicAuthenticate)
+	ws.Route(ws.GET("/messages").To(restful.WebSocket(http.HandlerFunc(echo))))
+	restful.Add(ws)
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
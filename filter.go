@@ -16,8 +16,15 @@ type FilterChain struct {
 func (f *FilterChain) ProcessFilter(request *Request, response *Response) {
 	if f.Index < len(f.Filters) {
 		f.Index++
-		f.Filters[f.Index-1](request, response, f)
+		filter := f.Filters[f.Index-1]
+		if request != nil && request.tracer != nil {
+			defer traceCall(request.tracer, "filter", filter)()
+		}
+		filter(request, response, f)
 	} else {
+		if request != nil && request.tracer != nil {
+			defer traceCall(request.tracer, "route function", f.Target)()
+		}
 		f.Target(request, response)
 	}
 }
@@ -0,0 +1,68 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "strings"
+
+// FilterPredicate decides whether a conditional FilterFunction applies to a Request.
+type FilterPredicate func(req *Request) bool
+
+// When returns a FilterFunction that calls the filter only if the predicate holds for the request.
+// Otherwise the request is passed on to the next filter in the chain.
+func (f FilterFunction) When(predicate FilterPredicate) FilterFunction {
+	return func(req *Request, resp *Response, chain *FilterChain) {
+		if predicate(req) {
+			f(req, resp, chain)
+			return
+		}
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// Unless returns a FilterFunction that skips the filter if the predicate holds for the request.
+//
+//	container.Filter(accessLog.Filter.Unless(PathPrefix("/health")))
+func (f FilterFunction) Unless(predicate FilterPredicate) FilterFunction {
+	return f.When(func(req *Request) bool { return !predicate(req) })
+}
+
+// PathPrefix returns a FilterPredicate that holds if the URL path of the request starts with the prefix.
+func PathPrefix(prefix string) FilterPredicate {
+	return func(req *Request) bool {
+		return strings.HasPrefix(req.Request.URL.Path, prefix)
+	}
+}
+
+// HasMetadata returns a FilterPredicate that holds if the selected Route has a Metadata value for the key.
+// It never holds for container filters that run when no Route was selected.
+func HasMetadata(key string) FilterPredicate {
+	return func(req *Request) bool {
+		route := req.SelectedRoute()
+		if route == nil {
+			return false
+		}
+		_, ok := route.Metadata[key]
+		return ok
+	}
+}
+
+// DefaultFilterPriority is the priority of filters added using Filter.
+const DefaultFilterPriority = 0
+
+// insertFilter inserts the filter, with its priority, after all filters with a lower or equal priority.
+// Both slices are kept in ascending order of priority ; filters with equal priority keep the order in which they were added.
+func insertFilter(filters []FilterFunction, priorities []int, filter FilterFunction, priority int) ([]FilterFunction, []int) {
+	at := len(filters)
+	for at > 0 && priorities[at-1] > priority {
+		at--
+	}
+	filters = append(filters, nil)
+	copy(filters[at+1:], filters[at:])
+	filters[at] = filter
+	priorities = append(priorities, 0)
+	copy(priorities[at+1:], priorities[at:])
+	priorities[at] = priority
+	return filters, priorities
+}
@@ -0,0 +1,67 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func recordingFilter(name string, calls *[]string) FilterFunction {
+	return func(req *Request, resp *Response, chain *FilterChain) {
+		*calls = append(*calls, name)
+		chain.ProcessFilter(req, resp)
+	}
+}
+
+// go test -v -test.run TestFilterWithPriority ...restful
+func TestFilterWithPriority(t *testing.T) {
+	var calls []string
+	c := NewContainer()
+	c.Filter(recordingFilter("logging", &calls))
+	c.FilterWithPriority(-10, recordingFilter("auth", &calls))
+	c.FilterWithPriority(10, recordingFilter("late", &calls))
+	c.Filter(recordingFilter("metrics", &calls))
+	ws := new(WebService).Path("/prio")
+	ws.Filter(recordingFilter("ws", &calls))
+	ws.FilterWithPriority(-1, recordingFilter("ws-first", &calls))
+	ws.Route(ws.GET("").
+		Filter(recordingFilter("route", &calls)).
+		FilterWithPriority(-1, recordingFilter("route-first", &calls)).
+		To(dummy))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/prio", nil)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got, want := strings.Join(calls, ","), "auth,logging,metrics,late,ws-first,ws,route-first,route"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestConditionalFilter ...restful
+func TestConditionalFilter(t *testing.T) {
+	var calls []string
+	c := NewContainer()
+	c.Filter(recordingFilter("log", &calls).Unless(PathPrefix("/cond/health")))
+	c.Filter(recordingFilter("audit", &calls).When(HasMetadata("audit")))
+	ws := new(WebService).Path("/cond")
+	ws.Route(ws.GET("/health").To(dummy))
+	ws.Route(ws.GET("/orders").Metadata("audit", true).To(dummy))
+	c.Add(ws)
+	for _, each := range []struct {
+		path, want string
+	}{
+		{"/cond/health", ""},
+		{"/cond/orders", "log,audit"},
+	} {
+		calls = calls[:0]
+		httpRequest, _ := http.NewRequest("GET", each.path, nil)
+		recorder := httptest.NewRecorder()
+		c.ServeHTTP(recorder, httpRequest)
+		if got := strings.Join(calls, ","); got != each.want {
+			t.Errorf("%s: got %v want %v", each.path, got, each.want)
+		}
+		if recorder.Code != http.StatusOK {
+			t.Errorf("%s: got status %d want 200", each.path, recorder.Code)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIME_GRPC_WEB       = "application/grpc-web"       // gRPC-web binary wire format ; messages are protobuf encoded
+	MIME_GRPC_WEB_PROTO = "application/grpc-web+proto" // same as MIME_GRPC_WEB
+
+	// MaxGRPCWebMessageSize is the maximum size of a message that is read from a gRPC-web request.
+	MaxGRPCWebMessageSize = 4 << 20
+
+	grpcWebTrailerFlag    = 0x80
+	grpcWebCompressedFlag = 0x01
+)
+
+// GRPCWebMessage is implemented by (generated) protobuf messages that can marshal themselves.
+// Values implementing encoding.BinaryMarshaler and encoding.BinaryUnmarshaler, and raw []byte, are supported too.
+type GRPCWebMessage interface {
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+// NewGRPCWebEntityAccessor returns an EntityReaderWriter for unary gRPC-web calls:
+// the request body is a single length-prefixed message and the response body is a single message
+// followed by a trailer frame with grpc-status 0.
+// Accessors for MIME_GRPC_WEB and MIME_GRPC_WEB_PROTO are registered by default.
+// The text variant (application/grpc-web-text) and compressed messages are not supported.
+//
+//	ws.Route(ws.POST("/helloworld.Greeter/SayHello").Consumes(restful.MIME_GRPC_WEB).Produces(restful.MIME_GRPC_WEB).To(sayHello))
+//
+// Use NewGRPCWebStream for streaming calls and to return a non-zero grpc-status.
+func NewGRPCWebEntityAccessor(contentType string) EntityReaderWriter {
+	return entityGRPCWebAccess{ContentType: contentType}
+}
+
+// entityGRPCWebAccess is a EntityReaderWriter for the gRPC-web wire format
+type entityGRPCWebAccess struct {
+	// This is used for setting the Content-Type header when writing
+	ContentType string
+}
+
+// Read unmarshals the first message of the request body ; a request without a message is a bad request.
+func (e entityGRPCWebAccess) Read(req *Request, v interface{}) error {
+	err := NewGRPCWebStream(req, nil).Receive(v)
+	if err == io.EOF {
+		return NewError(http.StatusBadRequest, "missing gRPC-web message")
+	}
+	return err
+}
+
+// Write marshals the value as one message, followed by the trailer, and sets the Content-Type Header.
+func (e entityGRPCWebAccess) Write(resp *Response, status int, v interface{}) error {
+	stream := &GRPCWebStream{resp: resp, contentType: e.ContentType, status: status}
+	if v != nil {
+		if err := stream.Send(v); err != nil {
+			return err
+		}
+	}
+	return stream.Close(0, "", nil)
+}
+
+// GRPCWebStream reads and writes the length-prefixed messages of a gRPC-web call.
+// Messages are flushed as they are sent ; over HTTP/2 a RouteFunction can interleave
+// receiving and sending, e.g. to echo messages as they arrive.
+//
+//	stream := restful.NewGRPCWebStream(req, resp)
+//	for {
+//		msg := new(pb.Echo)
+//		if err := stream.Receive(msg); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			stream.Close(3, err.Error(), nil) // INVALID_ARGUMENT
+//			return
+//		}
+//		stream.Send(msg)
+//	}
+//	stream.Close(0, "", nil)
+type GRPCWebStream struct {
+	req         *Request
+	resp        *Response
+	contentType string
+	status      int
+	wroteHeader bool
+	closed      bool
+}
+
+// NewGRPCWebStream returns a GRPCWebStream for the request and response.
+func NewGRPCWebStream(req *Request, resp *Response) *GRPCWebStream {
+	contentType := MIME_GRPC_WEB
+	if req != nil {
+		if requested := req.Request.Header.Get(HEADER_ContentType); strings.HasPrefix(requested, MIME_GRPC_WEB) {
+			contentType = requested
+		}
+	}
+	return &GRPCWebStream{req: req, resp: resp, contentType: contentType, status: http.StatusOK}
+}
+
+// Receive reads the next message into v. It returns io.EOF if the request has no more messages.
+func (s *GRPCWebStream) Receive(v interface{}) error {
+	if contentType := s.req.Request.Header.Get(HEADER_ContentType); strings.HasPrefix(contentType, MIME_GRPC_WEB+"-text") {
+		return NewError(http.StatusUnsupportedMediaType, "gRPC-web text format is not supported")
+	}
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(s.req.Request.Body, prefix); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return NewError(http.StatusBadRequest, "incomplete gRPC-web frame")
+		}
+		return err
+	}
+	if prefix[0]&grpcWebCompressedFlag != 0 {
+		return NewError(http.StatusBadRequest, "compressed gRPC-web messages are not supported")
+	}
+	size := binary.BigEndian.Uint32(prefix[1:])
+	if size > MaxGRPCWebMessageSize {
+		return NewError(http.StatusRequestEntityTooLarge, fmt.Sprintf("gRPC-web message of %d bytes exceeds %d", size, MaxGRPCWebMessageSize))
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.req.Request.Body, data); err != nil {
+		return NewError(http.StatusBadRequest, "incomplete gRPC-web frame")
+	}
+	switch target := v.(type) {
+	case GRPCWebMessage:
+		return target.Unmarshal(data)
+	case encoding.BinaryUnmarshaler:
+		return target.UnmarshalBinary(data)
+	case *[]byte:
+		*target = data
+		return nil
+	}
+	return fmt.Errorf("cannot unmarshal gRPC-web message into %T", v)
+}
+
+// Send writes the value as a message and flushes it.
+func (s *GRPCWebStream) Send(v interface{}) error {
+	var data []byte
+	var err error
+	switch source := v.(type) {
+	case GRPCWebMessage:
+		data, err = source.Marshal()
+	case encoding.BinaryMarshaler:
+		data, err = source.MarshalBinary()
+	case []byte:
+		data = source
+	default:
+		err = fmt.Errorf("cannot marshal %T as gRPC-web message", v)
+	}
+	if err != nil {
+		return err
+	}
+	return s.writeFrame(0, data)
+}
+
+// Close writes the trailer frame with the gRPC status code, its message and additional metadata.
+// Further messages cannot be sent. The message is percent-encoded ; metadata with a name that is not
+// a valid header field name, or a value with a control character such as CR or LF, is rejected
+// with an error and nothing is written.
+func (s *GRPCWebStream) Close(code int, message string, trailer http.Header) error {
+	if s.closed {
+		return errors.New("gRPC-web stream is already closed")
+	}
+	for k, values := range trailer {
+		if !validHeaderFieldName(k) {
+			return fmt.Errorf("invalid gRPC-web trailer name %q", k)
+		}
+		for _, v := range values {
+			if !validHeaderFieldValue(v) {
+				return fmt.Errorf("invalid gRPC-web trailer value for %q", k)
+			}
+		}
+	}
+	var buffer bytes.Buffer
+	buffer.WriteString("grpc-status: " + strconv.Itoa(code) + "\r\n")
+	if len(message) > 0 {
+		buffer.WriteString("grpc-message: " + grpcPercentEncode(message) + "\r\n")
+	}
+	keys := make([]string, 0, len(trailer))
+	for k := range trailer {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range trailer[k] {
+			buffer.WriteString(strings.ToLower(k) + ": " + v + "\r\n")
+		}
+	}
+	err := s.writeFrame(grpcWebTrailerFlag, buffer.Bytes())
+	s.closed = true
+	return err
+}
+
+// writeFrame writes the header (once), the length-prefixed data and flushes.
+func (s *GRPCWebStream) writeFrame(flags byte, data []byte) error {
+	if s.closed {
+		return errors.New("gRPC-web stream is already closed")
+	}
+	if !s.wroteHeader {
+		// frames must not be compressed as a whole
+		s.resp.renderOptions.Encoding = ""
+		s.resp.Header().Set(HEADER_ContentType, s.contentType)
+		s.resp.WriteHeader(s.status)
+		s.wroteHeader = true
+	}
+	prefix := make([]byte, 5)
+	prefix[0] = flags
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(data)))
+	if _, err := s.resp.Write(prefix); err != nil {
+		return err
+	}
+	if _, err := s.resp.Write(data); err != nil {
+		return err
+	}
+	s.resp.Flush()
+	return nil
+}
+
+// validHeaderFieldName returns whether the name is a token (RFC 9110, 5.1).
+func validHeaderFieldName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c >= 0x7F || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) != -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// validHeaderFieldValue returns whether the value has no control characters other than horizontal tab (RFC 9110, 5.5).
+func validHeaderFieldValue(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; (c < ' ' && c != '\t') || c == 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+// grpcPercentEncode encodes a grpc-message value as required by the gRPC protocol.
+func grpcPercentEncode(message string) string {
+	var buffer strings.Builder
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c >= 0x20 && c <= 0x7E && c != '%' {
+			buffer.WriteByte(c)
+		} else {
+			fmt.Fprintf(&buffer, "%%%02X", c)
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,146 @@
+package restful
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type greeting struct {
+	text string
+}
+
+func (g greeting) MarshalBinary() ([]byte, error) { return []byte(g.text), nil }
+
+func (g *greeting) UnmarshalBinary(data []byte) error {
+	g.text = string(data)
+	return nil
+}
+
+func grpcWebFrame(flags byte, data []byte) []byte {
+	frame := make([]byte, 5, 5+len(data))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+	return append(frame, data...)
+}
+
+// readGRPCWebFrames returns the payload of each frame ; trailers are prefixed with "trailer:"
+func readGRPCWebFrames(t *testing.T, body []byte) (frames []string) {
+	for len(body) > 0 {
+		if len(body) < 5 {
+			t.Fatalf("incomplete frame: %v", body)
+		}
+		size := binary.BigEndian.Uint32(body[1:5])
+		payload := string(body[5 : 5+size])
+		if body[0]&grpcWebTrailerFlag != 0 {
+			payload = "trailer:" + payload
+		}
+		frames = append(frames, payload)
+		body = body[5+size:]
+	}
+	return
+}
+
+// go test -v -test.run TestGRPCWebUnary ...restful
+func TestGRPCWebUnary(t *testing.T) {
+	c := NewContainer()
+	ws := new(WebService).Path("/greeter").Consumes(MIME_GRPC_WEB).Produces(MIME_GRPC_WEB)
+	ws.Route(ws.POST("/SayHello").To(func(req *Request, resp *Response) {
+		in := new(greeting)
+		if err := req.ReadEntity(in); err != nil {
+			resp.WriteError(http.StatusBadRequest, err)
+			return
+		}
+		resp.WriteEntity(greeting{text: "hello " + in.text})
+	}))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("POST", "/greeter/SayHello", bytes.NewReader(grpcWebFrame(0, []byte("world"))))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_GRPC_WEB)
+	httpRequest.Header.Set(HEADER_Accept, MIME_GRPC_WEB)
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httpRequest)
+	if got, want := recorder.Header().Get(HEADER_ContentType), MIME_GRPC_WEB; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	frames := readGRPCWebFrames(t, recorder.Body.Bytes())
+	if got, want := strings.Join(frames, "|"), "hello world|trailer:grpc-status: 0\r\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestGRPCWebStreamEcho ...restful
+func TestGRPCWebStreamEcho(t *testing.T) {
+	var body bytes.Buffer
+	body.Write(grpcWebFrame(0, []byte("one")))
+	body.Write(grpcWebFrame(0, []byte("two")))
+	httpRequest, _ := http.NewRequest("POST", "/echo", &body)
+	httpRequest.Header.Set(HEADER_ContentType, MIME_GRPC_WEB_PROTO)
+	recorder := httptest.NewRecorder()
+	stream := NewGRPCWebStream(NewRequest(httpRequest), NewResponse(recorder))
+	for {
+		var message []byte
+		err := stream.Receive(&message)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.Send(message)
+	}
+	stream.Close(3, "bad 100%", http.Header{"Echo-Count": {"2"}})
+	if got, want := recorder.Header().Get(HEADER_ContentType), MIME_GRPC_WEB_PROTO; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	frames := readGRPCWebFrames(t, recorder.Body.Bytes())
+	if got, want := strings.Join(frames, "|"), "one|two|trailer:grpc-status: 3\r\ngrpc-message: bad 100%25\r\necho-count: 2\r\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if err := stream.Send([]byte("late")); err == nil {
+		t.Error("expected error sending after close")
+	}
+}
+
+// go test -v -test.run TestGRPCWebIncompleteFrame ...restful
+func TestGRPCWebIncompleteFrame(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/echo", bytes.NewReader(grpcWebFrame(0, []byte("truncated"))[:8]))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_GRPC_WEB)
+	var message []byte
+	err := NewGRPCWebStream(NewRequest(httpRequest), nil).Receive(&message)
+	if serviceError, ok := err.(ServiceError); !ok || serviceError.Code != http.StatusBadRequest {
+		t.Errorf("got %v want 400 ServiceError", err)
+	}
+}
+
+// go test -v -test.run TestGRPCWebEmptyBody ...restful
+func TestGRPCWebEmptyBody(t *testing.T) {
+	httpRequest, _ := http.NewRequest("POST", "/echo", bytes.NewReader(nil))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_GRPC_WEB)
+	var message []byte
+	err := NewGRPCWebEntityAccessor(MIME_GRPC_WEB).Read(NewRequest(httpRequest), &message)
+	if serviceError, ok := err.(ServiceError); !ok || serviceError.Code != http.StatusBadRequest {
+		t.Errorf("got %v want 400 ServiceError", err)
+	}
+}
+
+// go test -v -test.run TestGRPCWebInvalidTrailer ...restful
+func TestGRPCWebInvalidTrailer(t *testing.T) {
+	for _, trailer := range []http.Header{
+		{"Echo-Count": {"2\r\ngrpc-status: 0"}},
+		{"Echo\r\nCount": {"2"}},
+		{"": {"2"}},
+	} {
+		httpWriter := httptest.NewRecorder()
+		stream := &GRPCWebStream{resp: NewResponse(httpWriter), contentType: MIME_GRPC_WEB, status: http.StatusOK}
+		if err := stream.Close(13, "injected", trailer); err == nil {
+			t.Errorf("%v: expected error", trailer)
+		}
+		if got := httpWriter.Body.Len(); got != 0 {
+			t.Errorf("%v: got %d bytes written want none", trailer, got)
+		}
+	}
+}
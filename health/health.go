@@ -0,0 +1,171 @@
+// Package health provides liveness and readiness endpoints that aggregate named checks.
+//
+//	checks := health.New()
+//	checks.AddReadinessCheck("database", func(ctx context.Context) error { return db.PingContext(ctx) })
+//	checks.Register(restful.DefaultContainer) // GET /healthz and /readyz
+//
+// Both endpoints answer 200 (OK) if all their checks pass and 503 (Service Unavailable) otherwise,
+// with a JSON document that has the status and latency of each check:
+//
+//	{"status":"fail","checks":[{"name":"database","status":"fail","error":"connection refused","latency":"1.2ms"}]}
+//
+// The endpoints are excluded from access logs and API documentation unless Logged or Documented is set.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Status values
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+)
+
+// Checker checks a dependency or part of the process ; it returns an error if it is not healthy.
+// A Checker must return when the context is done.
+type Checker func(ctx context.Context) error
+
+// Report is the document written by the health endpoints.
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult is the outcome of one Checker.
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Latency string `json:"latency"`
+}
+
+// Checks holds the named Checkers and the settings of the endpoints.
+type Checks struct {
+	// LivenessPath is the path of the liveness endpoint ; default is /healthz.
+	LivenessPath string
+	// ReadinessPath is the path of the readiness endpoint ; default is /readyz.
+	ReadinessPath string
+	// Timeout limits the time of each Checker ; default is 5 seconds.
+	Timeout time.Duration
+	// Documented includes the endpoints in API documentation such as Swagger.
+	Documented bool
+	// Logged includes the requests in the AccessLog.
+	Logged bool
+
+	lock      sync.RWMutex
+	liveness  []namedChecker
+	readiness []namedChecker
+}
+
+type namedChecker struct {
+	name  string
+	check Checker
+}
+
+// New returns Checks with default settings.
+func New() *Checks {
+	return &Checks{LivenessPath: "/healthz", ReadinessPath: "/readyz", Timeout: 5 * time.Second}
+}
+
+// AddLivenessCheck adds a Checker that tells whether the process works at all, e.g. detects a deadlock.
+// Liveness checks are part of both endpoints: a process that is not live is not ready either.
+func (c *Checks) AddLivenessCheck(name string, check Checker) *Checks {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.liveness = append(c.liveness, namedChecker{name, check})
+	return c
+}
+
+// AddReadinessCheck adds a Checker that tells whether the process can handle requests, e.g. reaches its database.
+func (c *Checks) AddReadinessCheck(name string, check Checker) *Checks {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.readiness = append(c.readiness, namedChecker{name, check})
+	return c
+}
+
+// Liveness runs the liveness checks.
+func (c *Checks) Liveness(ctx context.Context) Report {
+	c.lock.RLock()
+	checkers := append([]namedChecker{}, c.liveness...)
+	c.lock.RUnlock()
+	return c.run(ctx, checkers)
+}
+
+// Readiness runs the liveness and readiness checks.
+func (c *Checks) Readiness(ctx context.Context) Report {
+	c.lock.RLock()
+	checkers := append(append([]namedChecker{}, c.liveness...), c.readiness...)
+	c.lock.RUnlock()
+	return c.run(ctx, checkers)
+}
+
+// run runs the checkers concurrently, each with the timeout, and reports the results in their order.
+func (c *Checks) run(ctx context.Context, checkers []namedChecker) Report {
+	report := Report{Status: StatusPass, Checks: make([]CheckResult, len(checkers))}
+	var wait sync.WaitGroup
+	for i, each := range checkers {
+		wait.Add(1)
+		go func(i int, each namedChecker) {
+			defer wait.Done()
+			report.Checks[i] = c.runOne(ctx, each)
+		}(i, each)
+	}
+	wait.Wait()
+	for _, each := range report.Checks {
+		if each.Status != StatusPass {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func (c *Checks) runOne(ctx context.Context, checker namedChecker) CheckResult {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- checker.check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done(): // the Checker does not respect the context
+		err = ctx.Err()
+	}
+	result := CheckResult{Name: checker.name, Status: StatusPass, Latency: time.Since(start).String()}
+	if err != nil {
+		result.Status, result.Error = StatusFail, err.Error()
+	}
+	return result
+}
+
+// Register adds a WebService for each of the liveness and readiness endpoints to the container.
+func (c *Checks) Register(container *restful.Container) {
+	container.Add(c.webService(c.LivenessPath, "liveness", c.Liveness))
+	container.Add(c.webService(c.ReadinessPath, "readiness", c.Readiness))
+}
+
+func (c *Checks) webService(path, kind string, report func(ctx context.Context) Report) *restful.WebService {
+	ws := new(restful.WebService).Path(path).Produces(restful.MIME_JSON)
+	ws.Metadata(restful.KeyExcludeFromDocumentation, !c.Documented)
+	ws.Metadata(restful.KeyExcludeFromAccessLog, !c.Logged)
+	ws.Route(ws.GET("").To(func(req *restful.Request, resp *restful.Response) {
+		result := report(req.Context())
+		resp.Header().Set(restful.HEADER_CacheControl, "no-store")
+		status := http.StatusOK
+		if result.Status != StatusPass {
+			status = http.StatusServiceUnavailable
+		}
+		resp.WriteHeaderAndEntity(status, result)
+	}).Doc("Reports the " + kind + " of the service").Operation(kind).Writes(Report{}))
+	return ws
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+func get(container *restful.Container, path string) (int, Report) {
+	httpRequest, _ := http.NewRequest("GET", path, nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	var report Report
+	json.Unmarshal(httpWriter.Body.Bytes(), &report)
+	return httpWriter.Code, report
+}
+
+// go test -v -test.run TestHealthEndpoints ...health
+func TestHealthEndpoints(t *testing.T) {
+	checks := New()
+	checks.AddLivenessCheck("goroutines", func(ctx context.Context) error { return nil })
+	checks.AddReadinessCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	container := restful.NewContainer()
+	checks.Register(container)
+
+	status, report := get(container, "/healthz")
+	if got, want := status, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(report.Checks), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	status, report = get(container, "/readyz")
+	if got, want := status, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := report.Status, StatusFail; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := report.Checks[1], (CheckResult{Name: "database", Status: StatusFail, Error: "connection refused", Latency: report.Checks[1].Latency}); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	checks := New()
+	checks.Timeout = 10 * time.Millisecond
+	checks.AddReadinessCheck("stuck", func(ctx context.Context) error {
+		time.Sleep(time.Second) // does not respect the context
+		return nil
+	})
+	report := checks.Readiness(context.Background())
+	if got, want := report.Checks[0].Error, context.DeadlineExceeded.Error(); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestExcludedFromAccessLogAndDocumentation(t *testing.T) {
+	logged := 0
+	container := restful.NewContainer()
+	container.Filter(restful.AccessLog{Sink: restful.AccessLogSinkFunc(func(e restful.AccessLogEntry) { logged++ })}.Filter)
+	New().Register(container)
+	get(container, "/healthz")
+	if got, want := logged, 0; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for _, each := range container.RegisteredWebServices() {
+		if exclude, _ := each.Routes()[0].Metadata[restful.KeyExcludeFromDocumentation].(bool); !exclude {
+			t.Errorf("%s should be excluded from documentation", each.RootPath())
+		}
+	}
+}
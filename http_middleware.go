@@ -0,0 +1,136 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+)
+
+// HttpMiddlewareHandler is the common signature of net/http middleware.
+type HttpMiddlewareHandler func(http.Handler) http.Handler
+
+// requestContextKey is the key of the *Request stored in the context of a http.Request by the middleware adapters.
+type requestContextKey struct{}
+
+// RequestFromContext returns the restful Request that is stored in the context by HttpMiddlewareHandlerToFilter.
+// Use it in net/http middleware to access path parameters and the selected route.
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	req, ok := ctx.Value(requestContextKey{}).(*Request)
+	return req, ok
+}
+
+// HttpMiddlewareHandlerToFilter converts a net/http middleware into a FilterFunction.
+// The middleware can change (wrap) the http.ResponseWriter and replace the http.Request (e.g. with a new context) ;
+// both changes are visible to the next filters and the RouteFunction.
+// If the middleware does not call its next handler then the filter chain is not continued ;
+// what the middleware writes itself, e.g. a 401 (Unauthorized), is written through the Response.
+// A wrapped writer is only used until the middleware returns ; a compressor that the Response installed
+// on top of it is closed before that, and the Response writes to its original writer again afterwards.
+func HttpMiddlewareHandlerToFilter(middleware HttpMiddlewareHandler) FilterFunction {
+	return func(req *Request, resp *Response, chain *FilterChain) {
+		writer := &middlewareWriter{resp: resp, original: resp.ResponseWriter}
+		defer func() {
+			// the middleware may have finalized its wrapper, e.g. a gzip or buffering writer
+			resp.ResponseWriter = writer.original
+			writer.wrapped = false
+		}()
+		next := http.HandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+			req.Request = httpRequest
+			if httpWriter != http.ResponseWriter(writer) {
+				// the wrapper writes to the original writer, the Response writes to the wrapper
+				writer.wrapped = true
+				resp.ResponseWriter = httpWriter
+			}
+			chain.ProcessFilter(req, resp)
+			if writer.wrapped {
+				resp.closeCompressor()
+			}
+		})
+		httpRequest := req.Request.WithContext(context.WithValue(req.Request.Context(), requestContextKey{}, req))
+		middleware(next).ServeHTTP(writer, httpRequest)
+	}
+}
+
+// middlewareWriter is the http.ResponseWriter that HttpMiddlewareHandlerToFilter gives to a middleware.
+// It writes through the Response, such that status, length, compression and hooks account for what the
+// middleware writes, until the middleware has wrapped it for the next handler.
+type middlewareWriter struct {
+	resp     *Response
+	original http.ResponseWriter // of the Response when the filter was called
+	wrapped  bool                // true if the Response writes to a wrapper of this writer
+}
+
+// Header is part of http.ResponseWriter
+func (w *middlewareWriter) Header() http.Header {
+	if w.wrapped {
+		return w.original.Header()
+	}
+	return w.resp.Header()
+}
+
+// Write is part of http.ResponseWriter
+func (w *middlewareWriter) Write(bytes []byte) (int, error) {
+	if w.wrapped {
+		return w.original.Write(bytes)
+	}
+	return w.resp.Write(bytes)
+}
+
+// WriteHeader is part of http.ResponseWriter
+func (w *middlewareWriter) WriteHeader(status int) {
+	if w.wrapped {
+		w.original.WriteHeader(status)
+		return
+	}
+	w.resp.WriteHeader(status)
+}
+
+// Flush is part of http.Flusher
+func (w *middlewareWriter) Flush() {
+	if !w.wrapped {
+		w.resp.Flush()
+		return
+	}
+	if flusher, ok := w.original.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack is part of http.Hijacker ; it lets WebSocket Routes take over the connection behind the middleware.
+func (w *middlewareWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if !w.wrapped {
+		return w.resp.Hijack()
+	}
+	if hijacker, ok := w.original.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the original ResponseWriter for http.ResponseController.
+func (w *middlewareWriter) Unwrap() http.ResponseWriter {
+	return w.original
+}
+
+// FilterToHttpMiddlewareHandler converts a FilterFunction into a net/http middleware.
+// If the middleware is used inside a filter chain (see HttpMiddlewareHandlerToFilter) then the filter
+// receives the original Request, including its path parameters and attributes.
+func FilterToHttpMiddlewareHandler(filter FilterFunction) HttpMiddlewareHandler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+			req, ok := RequestFromContext(httpRequest.Context())
+			if !ok {
+				req = NewRequest(httpRequest)
+			}
+			chain := FilterChain{Filters: []FilterFunction{filter}, Target: func(req *Request, resp *Response) {
+				next.ServeHTTP(resp, req.Request)
+			}}
+			chain.ProcessFilter(req, NewResponse(httpWriter))
+		})
+	}
+}
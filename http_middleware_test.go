@@ -0,0 +1,178 @@
+package restful
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type headerKey struct{}
+
+func tagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Tag", "middleware")
+		if req, ok := RequestFromContext(r.Context()); ok {
+			w.Header().Set("X-Path-Id", req.PathParameter("id"))
+			w.Header().Set("X-Route", req.SelectedRoutePath())
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), headerKey{}, "value")))
+	})
+}
+
+// go test -v -test.run TestHttpMiddlewareHandlerToFilter ...restful
+func TestHttpMiddlewareHandlerToFilter(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("/{id}").Filter(HttpMiddlewareHandlerToFilter(tagMiddleware)).To(func(req *Request, resp *Response) {
+		io.WriteString(resp, req.Request.Context().Value(headerKey{}).(string))
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/items/7", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get("X-Tag"), "middleware"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get("X-Path-Id"), "7"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get("X-Route"), "/items/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Body.String(), "value"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestHttpMiddlewareHandlerToFilterShortCircuit ...restful
+func TestHttpMiddlewareHandlerToFilterShortCircuit(t *testing.T) {
+	reject := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, "denied")
+		})
+	}
+	status, length := 0, 0
+	container := NewContainer()
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		chain.ProcessFilter(req, resp)
+		status, length = resp.StatusCode(), resp.ContentLength()
+	})
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("").Filter(HttpMiddlewareHandlerToFilter(reject)).To(dummy))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/items", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusUnauthorized; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := status, http.StatusUnauthorized; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := length, len("denied"); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+type upperWriter struct{ http.ResponseWriter }
+
+func (w upperWriter) Write(data []byte) (int, error) {
+	return w.ResponseWriter.Write(bytes.ToUpper(data))
+}
+
+// go test -v -test.run TestHttpMiddlewareHandlerToFilterWrapsWriter ...restful
+func TestHttpMiddlewareHandlerToFilterWrapsWriter(t *testing.T) {
+	upper := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(upperWriter{w}, r)
+		})
+	}
+	after := func(req *Request, resp *Response, chain *FilterChain) {
+		chain.ProcessFilter(req, resp)
+		io.WriteString(resp, " done") // not through the wrapper of the middleware, which has returned
+	}
+	container := NewContainer()
+	ws := new(WebService).Path("/items")
+	ws.Route(ws.GET("").Filter(after).Filter(HttpMiddlewareHandlerToFilter(upper)).To(func(req *Request, resp *Response) {
+		io.WriteString(resp, "shout")
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/items", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "SHOUT done"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// hijackingWriter is a wrapper of a middleware that supports hijacking if the writer it wraps does.
+type hijackingWriter struct {
+	http.ResponseWriter
+}
+
+func (w hijackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// go test -v -test.run TestHttpMiddlewareHandlerToFilterHijack ...restful
+func TestHttpMiddlewareHandlerToFilterHijack(t *testing.T) {
+	wrap := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := w.(http.Hijacker); !ok {
+				t.Error("writer of the middleware is not a http.Hijacker")
+				return
+			}
+			next.ServeHTTP(hijackingWriter{w}, r)
+		})
+	}
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+	})
+	ws := new(WebService).Path("/chat")
+	ws.Route(ws.GET("").Filter(HttpMiddlewareHandlerToFilter(wrap)).To(WebSocket(echo)))
+	server := httptest.NewServer(NewContainer().Add(ws))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /chat HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n"))
+	response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := response.StatusCode, http.StatusSwitchingProtocols; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestFilterToHttpMiddlewareHandler(t *testing.T) {
+	handler := FilterToHttpMiddlewareHandler(globalFilter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "handler")
+	}))
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpWriter := httptest.NewRecorder()
+	handler.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "global-handler"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
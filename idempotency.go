@@ -0,0 +1,238 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StoredResponse is the response of the first request with an Idempotency-Key as kept by an IdempotencyStore.
+// Values are shared between requests and must not be changed.
+type StoredResponse struct {
+	Status      int
+	Header      http.Header
+	Body        []byte // not compressed ; the Response compresses it if the request asks for it
+	Fingerprint string // of the request body ; a retry must send the same body
+}
+
+// IdempotencyStore keeps the responses of requests by their Idempotency-Key. Reserve must be atomic such that
+// only one of concurrent retries is processed. See Stores in the package documentation.
+type IdempotencyStore interface {
+	// Reserve marks the key as in progress if it is unknown and then returns true.
+	// Otherwise it returns false with the stored response, or nil if the first request is still in progress.
+	Reserve(key string, ttl time.Duration) (stored *StoredResponse, reserved bool)
+	// Complete keeps the response for a reserved key during ttl.
+	Complete(key string, response *StoredResponse, ttl time.Duration)
+	// Release forgets a reserved key such that a retry is processed again, e.g. after a server error.
+	Release(key string)
+}
+
+// Idempotency is used to create a Filter that implements the Idempotency-Key header for unsafe methods.
+// The response of the first request with a key is stored and replayed, with an Idempotent-Replayed header,
+// for retries with that key within the TTL. A retry while the first request is in progress gets a 409 (Conflict) ;
+// a retry with a different body gets a 422 (Unprocessable Entity). Server errors (5xx) are not stored.
+// A replayed response has no Set-Cookie header.
+//
+// Keys are separated per client by the Scope, by default the Authorization header. Without a Scope that
+// identifies the client, e.g. for anonymous requests, a client that guesses or reuses the key of another
+// client for the same path gets the response of that client.
+//
+//	idempotency := restful.Idempotency{Store: restful.NewMemoryIdempotencyStore(), TTL: 24 * time.Hour, Scope: sessionID}
+//	ws.Route(ws.POST("/payments").Filter(idempotency.Filter).To(createPayment))
+type Idempotency struct {
+	Store IdempotencyStore
+	TTL   time.Duration // how long a response is replayed ; default is 24 hours
+	// Methods that are subject to idempotency keys ; default is POST and PATCH.
+	Methods []string
+	// Required rejects a request without an Idempotency-Key header with a 400 (Bad Request).
+	Required bool
+	// Scope returns the part of the store key that separates clients, e.g. a user or session identifier ;
+	// default is a hash of the Authorization header. The method and path of the request are always part of the store key.
+	Scope func(req *Request) string
+}
+
+// Filter replays the stored response for a retry or stores the response of the rest of the chain.
+func (i Idempotency) Filter(req *Request, resp *Response, chain *FilterChain) {
+	idempotencyKey := req.Request.Header.Get(HEADER_IdempotencyKey)
+	if !i.appliesTo(req.Request.Method) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if len(idempotencyKey) == 0 {
+		if i.Required {
+			resp.WriteProblem(NewProblemDocument(http.StatusBadRequest, HEADER_IdempotencyKey+" header required"))
+			return
+		}
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	fingerprint, err := i.fingerprint(req)
+	if err != nil {
+		resp.WriteProblem(NewProblemDocument(http.StatusBadRequest, "unable to read request body: "+err.Error()))
+		return
+	}
+	scope := i.Scope
+	if scope == nil {
+		scope = authorizationScope
+	}
+	key := scope(req) + " " + req.Request.Method + " " + req.Request.URL.Path + " " + idempotencyKey
+	ttl := i.TTL
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	stored, reserved := i.Store.Reserve(key, ttl)
+	if !reserved {
+		switch {
+		case stored == nil:
+			resp.WriteProblem(NewProblemDocument(http.StatusConflict, "a request with this "+HEADER_IdempotencyKey+" is in progress"))
+		case stored.Fingerprint != fingerprint:
+			resp.WriteProblem(NewProblemDocument(http.StatusUnprocessableEntity, HEADER_IdempotencyKey+" was used for a different request"))
+		default:
+			stored.writeTo(resp)
+		}
+		return
+	}
+	completed := false
+	defer func() {
+		if !completed { // e.g. a panic
+			i.Store.Release(key)
+		}
+	}()
+	// capture the uncompressed response of the chain
+	buffer := &timeoutWriter{header: http.Header{}}
+	shadow := *resp
+	shadow.ResponseWriter = buffer
+	shadow.renderOptions.Encoding = ""
+	chain.ProcessFilter(req, &shadow)
+	shadow.closeCompressor()
+	resp.err = shadow.err
+	status := buffer.code
+	if !buffer.wroteHeader {
+		status = http.StatusOK
+	}
+	if status >= 500 {
+		buffer.copyTo(resp)
+		return
+	}
+	completed = true
+	response := &StoredResponse{Status: status, Header: buffer.header, Body: buffer.buffer.Bytes(), Fingerprint: fingerprint}
+	i.Store.Complete(key, response, ttl)
+	buffer.copyTo(resp)
+}
+
+func (i Idempotency) appliesTo(method string) bool {
+	methods := i.Methods
+	if len(methods) == 0 {
+		methods = []string{"POST", "PATCH"}
+	}
+	for _, each := range methods {
+		if each == method {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizationScope is the default Scope ; the credentials are hashed such that a store does not keep them.
+func authorizationScope(req *Request) string {
+	authorization := req.Request.Header.Get("Authorization")
+	if len(authorization) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(authorization))
+	return hex.EncodeToString(sum[:16])
+}
+
+// fingerprint returns a hash of the request body ; the body is restored for the rest of the chain.
+func (i Idempotency) fingerprint(req *Request) (string, error) {
+	if req.Request.Body == nil {
+		return "", nil
+	}
+	body, err := io.ReadAll(req.Request.Body)
+	if err != nil {
+		return "", err
+	}
+	req.Request.Body.Close()
+	req.Request.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// writeTo replays the stored response ; cookies that were set for the first request are not set again.
+func (s *StoredResponse) writeTo(resp *Response) {
+	header := resp.Header()
+	for k, v := range s.Header {
+		if k == HEADER_ContentLength && len(resp.renderOptions.Encoding) > 0 || k == "Set-Cookie" {
+			continue
+		}
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set(HEADER_IdempotentReplayed, "true")
+	resp.WriteHeader(s.Status)
+	resp.Write(s.Body)
+}
+
+// memoryIdempotencyStore is an IdempotencyStore that keeps responses in memory.
+type memoryIdempotencyStore struct {
+	lock      sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+}
+
+type idempotencyEntry struct {
+	response *StoredResponse // nil while in progress
+	expires  time.Time
+}
+
+// NewMemoryIdempotencyStore returns an IdempotencyStore that keeps responses in memory ; expired ones are removed periodically.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{entries: map[string]*idempotencyEntry{}}
+}
+
+// Reserve is part of IdempotencyStore
+func (s *memoryIdempotencyStore) Reserve(key string, ttl time.Duration) (*StoredResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expires) {
+		return entry.response, false
+	}
+	s.entries[key] = &idempotencyEntry{expires: now.Add(ttl)}
+	return nil, true
+}
+
+// Complete is part of IdempotencyStore
+func (s *memoryIdempotencyStore) Complete(key string, response *StoredResponse, ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.entries[key] = &idempotencyEntry{response: response, expires: time.Now().Add(ttl)}
+}
+
+// Release is part of IdempotencyStore
+func (s *memoryIdempotencyStore) Release(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.entries, key)
+}
+
+// sweep removes expired entries at most once a minute.
+func (s *memoryIdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, each := range s.entries {
+		if !now.Before(each.expires) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newIdempotencyContainer(idempotency Idempotency, handler RouteFunction) *Container {
+	container := NewContainer()
+	ws := new(WebService).Path("/payments")
+	ws.Route(ws.POST("").Filter(idempotency.Filter).To(handler))
+	ws.Route(ws.GET("").Filter(idempotency.Filter).To(handler))
+	container.Add(ws)
+	return container
+}
+
+func sendIdempotent(container *Container, method, key, body string) *httptest.ResponseRecorder {
+	httpRequest, _ := http.NewRequest(method, "/payments", strings.NewReader(body))
+	if len(key) > 0 {
+		httpRequest.Header.Set(HEADER_IdempotencyKey, key)
+	}
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	return httpWriter
+}
+
+// go test -v -test.run TestIdempotencyReplaysResponse ...restful
+func TestIdempotencyReplaysResponse(t *testing.T) {
+	calls := 0
+	container := newIdempotencyContainer(Idempotency{Store: NewMemoryIdempotencyStore()}, func(req *Request, resp *Response) {
+		calls++
+		resp.Header().Set("Location", "/payments/1")
+		http.SetCookie(resp, &http.Cookie{Name: "session", Value: "42"})
+		resp.WriteHeader(http.StatusCreated)
+		resp.Write([]byte("created"))
+	})
+	first := sendIdempotent(container, "POST", "k1", "{}")
+	retry := sendIdempotent(container, "POST", "k1", "{}")
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %d calls want %d", got, want)
+	}
+	if got, want := retry.Code, first.Code; got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+	if got, want := retry.Body.String(), "created"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := retry.Header().Get("Location"), "/payments/1"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := retry.Header().Get(HEADER_IdempotentReplayed), "true"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got := retry.Header().Get("Set-Cookie"); got != "" {
+		t.Errorf("got replayed cookie %q", got)
+	}
+	if first.Header().Get(HEADER_IdempotentReplayed) != "" {
+		t.Error("first response marked as replayed")
+	}
+	if got, want := sendIdempotent(container, "POST", "k1", `{"amount":2}`).Code, http.StatusUnprocessableEntity; got != want {
+		t.Errorf("got %d want %d for a different body", got, want)
+	}
+	sendIdempotent(container, "POST", "k2", "{}")
+	sendIdempotent(container, "POST", "", "{}")
+	sendIdempotent(container, "GET", "k1", "")
+	if got, want := calls, 4; got != want {
+		t.Errorf("got %d calls want %d", got, want)
+	}
+}
+
+// go test -v -test.run TestIdempotencyScopedByAuthorization ...restful
+func TestIdempotencyScopedByAuthorization(t *testing.T) {
+	calls := 0
+	container := newIdempotencyContainer(Idempotency{Store: NewMemoryIdempotencyStore()}, func(req *Request, resp *Response) {
+		calls++
+		resp.Write([]byte(req.Request.Header.Get("Authorization")))
+	})
+	for _, authorization := range []string{"Bearer ann", "Bearer bob", "Bearer ann"} {
+		httpRequest, _ := http.NewRequest("POST", "/payments", strings.NewReader("{}"))
+		httpRequest.Header.Set(HEADER_IdempotencyKey, "k1")
+		httpRequest.Header.Set("Authorization", authorization)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Body.String(), authorization; got != want {
+			t.Errorf("got %q want %q", got, want)
+		}
+	}
+	if got, want := calls, 2; got != want {
+		t.Errorf("got %d calls want %d", got, want)
+	}
+}
+
+// go test -v -test.run TestIdempotencyConflictAndServerError ...restful
+func TestIdempotencyConflictAndServerError(t *testing.T) {
+	inProgress := make(chan bool)
+	proceed := make(chan bool)
+	fail := true
+	container := newIdempotencyContainer(Idempotency{Store: NewMemoryIdempotencyStore(), Required: true}, func(req *Request, resp *Response) {
+		if fail {
+			resp.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		inProgress <- true
+		<-proceed
+		resp.WriteHeader(http.StatusCreated)
+	})
+	if got, want := sendIdempotent(container, "POST", "", "{}").Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %d want %d without key", got, want)
+	}
+	if got, want := sendIdempotent(container, "POST", "k", "{}").Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+	fail = false
+	done := make(chan int)
+	go func() { done <- sendIdempotent(container, "POST", "k", "{}").Code }()
+	<-inProgress
+	if got, want := sendIdempotent(container, "POST", "k", "{}").Code, http.StatusConflict; got != want {
+		t.Errorf("got %d want %d while in progress", got, want)
+	}
+	proceed <- true
+	if got, want := <-done, http.StatusCreated; got != want {
+		t.Errorf("got %d want %d after a server error", got, want)
+	}
+}
+
+// go test -v -test.run TestMemoryIdempotencyStoreExpires ...restful
+func TestMemoryIdempotencyStoreExpires(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	store.Reserve("k", time.Millisecond)
+	store.Complete("k", &StoredResponse{Status: 201}, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	if _, reserved := store.Reserve("k", time.Minute); !reserved {
+		t.Error("expired key not reserved")
+	}
+}
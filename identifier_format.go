@@ -0,0 +1,168 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IdentifierFormat is a policy for the values of identifier parameters (e.g. {user-id}).
+// It is used to validate values (see Request.PathParameterIdentifier) and to document
+// parameters that are marked as identifier (see Parameter.Identifier).
+type IdentifierFormat struct {
+	DataType   string // for documentation, e.g. "string"
+	DataFormat string // for documentation, e.g. "uuid"
+	// IsValid returns whether the value conforms to the format.
+	IsValid func(value string) bool
+	// Generate returns a new unique value ; can be nil if the format has no generator.
+	Generate func() string
+}
+
+var (
+	// UUIDFormat accepts RFC 4122 textual representations such as "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	UUIDFormat = IdentifierFormat{DataType: "string", DataFormat: "uuid", IsValid: isUUID, Generate: newUUID}
+	// ULIDFormat accepts 26 character, Crockford base32 encoded, ULIDs such as "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+	ULIDFormat = IdentifierFormat{DataType: "string", DataFormat: "ulid", IsValid: isULID, Generate: newULID}
+	// NumericFormat accepts non-negative 64-bit integers. It has no generator ; typically a database sequence is used.
+	NumericFormat = IdentifierFormat{DataType: "integer", DataFormat: "int64", IsValid: isNumeric}
+)
+
+// IdentifierFormat sets the format for identifier parameters of all WebServices added hereafter.
+// Default is UUIDFormat.
+func (c *Container) IdentifierFormat(format IdentifierFormat) {
+	c.identifierFormat = format
+}
+
+// NewIdentifier returns a new value using the generator of the IdentifierFormat of the Container.
+// It panics if that format has no generator.
+func (c *Container) NewIdentifier() string {
+	if c.identifierFormat.Generate == nil {
+		panic("[restful] identifier format " + c.identifierFormat.DataFormat + " has no generator")
+	}
+	return c.identifierFormat.Generate()
+}
+
+// documentIdentifiers sets the DataType and DataFormat of all parameters marked as identifier.
+func (c *Container) documentIdentifiers(service *WebService) {
+	document := func(each *Parameter) {
+		if each.data.identifier {
+			each.data.DataType = c.identifierFormat.DataType
+			each.data.DataFormat = c.identifierFormat.DataFormat
+		}
+	}
+	for _, each := range service.pathParameters {
+		document(each)
+	}
+	for _, route := range service.Routes() {
+		for _, each := range route.ParameterDocs {
+			document(each)
+		}
+	}
+}
+
+// PathParameterIdentifier returns the value of the Path parameter if it conforms to the IdentifierFormat of the Container.
+// Otherwise it returns a ServiceError with code 400 (Bad Request).
+func (r *Request) PathParameterIdentifier(name string) (string, error) {
+	format := r.identifierFormat
+	if format.IsValid == nil {
+		format = UUIDFormat
+	}
+	return r.pathParameterWithFormat(name, format)
+}
+
+// PathParameterUUID returns the value of the Path parameter if it is a valid UUID.
+// Otherwise it returns a ServiceError with code 400 (Bad Request).
+func (r *Request) PathParameterUUID(name string) (string, error) {
+	return r.pathParameterWithFormat(name, UUIDFormat)
+}
+
+func (r *Request) pathParameterWithFormat(name string, format IdentifierFormat) (string, error) {
+	value := r.PathParameter(name)
+	if !format.IsValid(value) {
+		return "", NewError(http.StatusBadRequest, "invalid "+format.DataFormat+" for path parameter:"+name)
+	}
+	return value, nil
+}
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant RFC 4122
+	h := hex.EncodeToString(b[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// newULID returns a ULID composed of the current time in milliseconds and 80 random bits.
+func newULID() string {
+	var b [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		panic(err.Error())
+	}
+	// encode 128 bits as 26 characters of 5 bits, the first character having 3 bits
+	hi := uint64(b[0])<<56 | uint64(b[1])<<48 | uint64(b[2])<<40 | uint64(b[3])<<32 | uint64(b[4])<<24 | uint64(b[5])<<16 | uint64(b[6])<<8 | uint64(b[7])
+	lo := uint64(b[8])<<56 | uint64(b[9])<<48 | uint64(b[10])<<40 | uint64(b[11])<<32 | uint64(b[12])<<24 | uint64(b[13])<<16 | uint64(b[14])<<8 | uint64(b[15])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out)
+}
+
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+	for i, each := range value {
+		switch i {
+		case 8, 13, 18, 23:
+			if each != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", each) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isULID(value string) bool {
+	if len(value) != 26 || value[0] > '7' {
+		return false
+	}
+	for _, each := range strings.ToUpper(value) {
+		if !strings.ContainsRune(crockfordBase32, each) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(value string) bool {
+	if len(value) == 0 || value[0] == '+' || value[0] == '-' {
+		return false
+	}
+	_, err := strconv.ParseInt(value, 10, 64)
+	return err == nil
+}
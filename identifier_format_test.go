@@ -0,0 +1,86 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIdentifierFormats(t *testing.T) {
+	for _, each := range []struct {
+		format IdentifierFormat
+		value  string
+		valid  bool
+	}{
+		{UUIDFormat, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", true},
+		{UUIDFormat, "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", true},
+		{UUIDFormat, "6ba7b8109dad11d180b400c04fd430c8", false},
+		{UUIDFormat, "6ba7b810-9dad-11d1-80b4-00c04fd430cg", false},
+		{ULIDFormat, "01ARZ3NDEKTSV4RRFFQ69G5FAV", true},
+		{ULIDFormat, "01arz3ndektsv4rrffq69g5fav", true},
+		{ULIDFormat, "81ARZ3NDEKTSV4RRFFQ69G5FAV", false},
+		{ULIDFormat, "01ARZ3NDEKTSV4RRFFQ69G5FAU", false},
+		{NumericFormat, "42", true},
+		{NumericFormat, "-42", false},
+		{NumericFormat, "", false},
+		{NumericFormat, "99999999999999999999", false},
+	} {
+		if got := each.format.IsValid(each.value); got != each.valid {
+			t.Errorf("%s %q: got %v want %v", each.format.DataFormat, each.value, got, each.valid)
+		}
+	}
+}
+
+// go test -v -test.run TestPathParameterIdentifier ...restful
+func TestPathParameterIdentifier(t *testing.T) {
+	container := NewContainer()
+	container.IdentifierFormat(NumericFormat)
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		id, err := req.PathParameterIdentifier("id")
+		if err != nil {
+			resp.WriteError(http.StatusBadRequest, err)
+			return
+		}
+		resp.Write([]byte(id))
+	}).Param(ws.PathParameter("id", "order identifier").Identifier()))
+	container.Add(ws)
+
+	if got, want := ws.Routes()[0].ParameterDocs[0].Data().DataType, "integer"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpRequest, _ := http.NewRequest("GET", "/orders/12", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "12"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpRequest, _ = http.NewRequest("GET", "/orders/abc", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestPathParameterUUID(t *testing.T) {
+	req := NewRequest(nil)
+	req.PathParameters()["id"] = "not-a-uuid"
+	if _, err := req.PathParameterUUID("id"); err == nil {
+		t.Error("error expected")
+	}
+}
+
+func TestGeneratedIdentifiersAreValid(t *testing.T) {
+	for _, each := range []IdentifierFormat{UUIDFormat, ULIDFormat} {
+		container := NewContainer()
+		container.IdentifierFormat(each)
+		id := container.NewIdentifier()
+		if !each.IsValid(id) {
+			t.Errorf("invalid generated %s: %s", each.DataFormat, id)
+		}
+		if id == container.NewIdentifier() {
+			t.Errorf("duplicate generated %s: %s", each.DataFormat, id)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContainerDescription is a structured view on the WebServices, Routes and Filters of a Container.
+// It can be used for debugging or for generating e.g. gateway configuration.
+type ContainerDescription struct {
+	Filters     []string                `json:"filters,omitempty"`
+	WebServices []WebServiceDescription `json:"webServices"`
+}
+
+// WebServiceDescription is a structured view on a WebService.
+type WebServiceDescription struct {
+	RootPath       string                 `json:"rootPath"`
+	Documentation  string                 `json:"documentation,omitempty"`
+	ApiVersion     string                 `json:"apiVersion,omitempty"`
+	Filters        []string               `json:"filters,omitempty"`
+	PathParameters []ParameterDescription `json:"pathParameters,omitempty"`
+	Routes         []RouteDescription     `json:"routes"`
+}
+
+// RouteDescription is a structured view on a Route.
+type RouteDescription struct {
+	Method     string                 `json:"method"`
+	Path       string                 `json:"path"`
+	Operation  string                 `json:"operation,omitempty"`
+	Doc        string                 `json:"doc,omitempty"`
+	Consumes   []string               `json:"consumes,omitempty"`
+	Produces   []string               `json:"produces,omitempty"`
+	Filters    []string               `json:"filters,omitempty"`
+	Parameters []ParameterDescription `json:"parameters,omitempty"`
+}
+
+// ParameterDescription is a structured view on a Parameter.
+type ParameterDescription struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind"`
+	DataType    string `json:"dataType,omitempty"`
+	DataFormat  string `json:"dataFormat,omitempty"`
+	Required    bool   `json:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// Describe returns a structured view on all registered WebServices, their Routes and Filters.
+func (c *Container) Describe() ContainerDescription {
+	description := ContainerDescription{
+		Filters:     namesOfFilters(c.containerFilters),
+		WebServices: []WebServiceDescription{}}
+	for _, ws := range c.RegisteredWebServices() {
+		wsd := WebServiceDescription{
+			RootPath:       ws.RootPath(),
+			Documentation:  ws.Documentation(),
+			ApiVersion:     ws.Version(),
+			Filters:        namesOfFilters(ws.filters),
+			PathParameters: describeParameters(ws.PathParameters()),
+			Routes:         []RouteDescription{}}
+		for _, route := range ws.Routes() {
+			wsd.Routes = append(wsd.Routes, describeRoute(route))
+		}
+		description.WebServices = append(description.WebServices, wsd)
+	}
+	return description
+}
+
+// IntrospectionHandler returns a http.Handler that writes, as JSON, the descriptions of the given Containers.
+// It is not registered by default ; use e.g. container.Handle("/debug/restful", IntrospectionHandler(container))
+func IntrospectionHandler(containers ...*Container) http.Handler {
+	return http.HandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+		descriptions := []ContainerDescription{}
+		for _, each := range containers {
+			descriptions = append(descriptions, each.Describe())
+		}
+		output, err := json.MarshalIndent(descriptions, "", " ")
+		if err != nil {
+			http.Error(httpWriter, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		httpWriter.Header().Set(HEADER_ContentType, MIME_JSON)
+		httpWriter.Write(output)
+	})
+}
+
+func describeRoute(route Route) RouteDescription {
+	return RouteDescription{
+		Method:     route.Method,
+		Path:       route.Path,
+		Operation:  route.Operation,
+		Doc:        route.Doc,
+		Consumes:   route.Consumes,
+		Produces:   route.Produces,
+		Filters:    namesOfFilters(route.Filters),
+		Parameters: describeParameters(route.ParameterDocs)}
+}
+
+func namesOfFilters(filters []FilterFunction) (names []string) {
+	for _, each := range filters {
+		names = append(names, qualifiedNameOfFunction(each))
+	}
+	return names
+}
+
+func describeParameters(parameters []*Parameter) (descriptions []ParameterDescription) {
+	for _, each := range parameters {
+		data := each.Data()
+		descriptions = append(descriptions, ParameterDescription{
+			Name:        data.Name,
+			Kind:        nameOfParameterKind(data.Kind),
+			DataType:    data.DataType,
+			DataFormat:  data.DataFormat,
+			Required:    data.Required,
+			Description: data.Description})
+	}
+	return descriptions
+}
+
+func nameOfParameterKind(kind int) string {
+	switch kind {
+	case PathParameterKind:
+		return "path"
+	case QueryParameterKind:
+		return "query"
+	case BodyParameterKind:
+		return "body"
+	case HeaderParameterKind:
+		return "header"
+	case FormParameterKind:
+		return "form"
+	}
+	return "unknown"
+}
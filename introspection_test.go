@@ -0,0 +1,59 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestContainer_Describe ...restful
+func TestContainer_Describe(t *testing.T) {
+	container := NewContainer()
+	container.Filter(globalFilter)
+	ws := new(WebService).Path("/users").Doc("users").Consumes(MIME_JSON).Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(dummy).Param(ws.PathParameter("id", "identifier")).Filter(routeFilter))
+	ws.Route(ws.PUT("/{id}").To(dummy))
+	container.Add(ws)
+
+	description := container.Describe()
+	if got, want := len(description.Filters), 1; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(description.WebServices), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	routes := description.WebServices[0].Routes
+	if got, want := len(routes), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	first := routes[0]
+	if first.Method != "GET" || first.Path != "/users/{id}" || first.Operation != "dummy" {
+		t.Errorf("unexpected route description %#v", first)
+	}
+	if got, want := first.Parameters[0].Kind, "path"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := first.Produces[0], MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestIntrospectionHandler(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("").To(dummy))
+	container.Add(ws)
+	container.Handle("/debug/restful", IntrospectionHandler(container))
+
+	httpRequest, _ := http.NewRequest("GET", "/debug/restful", nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	var descriptions []ContainerDescription
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &descriptions); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := descriptions[0].WebServices[0].RootPath, "/users"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
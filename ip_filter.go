@@ -0,0 +1,38 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net"
+	"net/http"
+)
+
+// IPFilter is used to create a Filter that rejects requests, with 403 (Forbidden), based on the IP address of the client.
+// The address is found using Request.ClientIP ; use Container.TrustProxies if requests pass through proxies.
+// Deny is checked first. If Allow is not empty then only clients from those networks are accepted.
+//
+//	internal, _ := restful.ParseNetworks("10.0.0.0/8", "192.168.0.0/16")
+//	adminService.Filter(restful.IPFilter{Allow: internal}.Filter)
+type IPFilter struct {
+	Allow []*net.IPNet
+	Deny  []*net.IPNet
+}
+
+// Filter rejects the request if its client is not allowed.
+func (f IPFilter) Filter(req *Request, resp *Response, chain *FilterChain) {
+	if !f.Allows(req.ClientIP()) {
+		resp.WriteProblem(NewProblemDocument(http.StatusForbidden, "client address is not allowed"))
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// Allows returns whether a client with this IP address is accepted. A nil address is never accepted.
+func (f IPFilter) Allows(ip net.IP) bool {
+	if ip == nil || containsIP(f.Deny, ip) {
+		return false
+	}
+	return len(f.Allow) == 0 || containsIP(f.Allow, ip)
+}
@@ -0,0 +1,41 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestIPFilter ...restful
+func TestIPFilter(t *testing.T) {
+	allow, _ := ParseNetworks("192.168.0.0/16")
+	deny, _ := ParseNetworks("192.168.66.0/24")
+	proxies, _ := ParseNetworks("10.0.0.0/8")
+	c := NewContainer()
+	c.TrustProxies(ClientIPPolicy{TrustedProxies: proxies})
+	ws := new(WebService).Path("/admin").Filter(IPFilter{Allow: allow, Deny: deny}.Filter)
+	ws.Route(ws.GET("").To(dummy))
+	c.Add(ws)
+	for _, each := range []struct {
+		remoteAddr, forwardedFor string
+		want                     int
+	}{
+		{"192.168.1.1:80", "", http.StatusOK},
+		{"192.168.66.1:80", "", http.StatusForbidden},
+		{"172.16.0.1:80", "", http.StatusForbidden},
+		{"10.0.0.1:80", "192.168.1.1", http.StatusOK},
+		{"10.0.0.1:80", "172.16.0.1", http.StatusForbidden},
+		{"172.16.0.1:80", "192.168.1.1", http.StatusForbidden},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "/admin", nil)
+		httpRequest.RemoteAddr = each.remoteAddr
+		if len(each.forwardedFor) > 0 {
+			httpRequest.Header.Set(HEADER_XForwardedFor, each.forwardedFor)
+		}
+		recorder := httptest.NewRecorder()
+		c.ServeHTTP(recorder, httpRequest)
+		if got := recorder.Code; got != each.want {
+			t.Errorf("%s (%s): got %v want %v", each.remoteAddr, each.forwardedFor, got, each.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	MIME_JSON_PATCH  = "application/json-patch+json"  // RFC 6902, used in Consumes() of a PATCH Route
+	MIME_MERGE_PATCH = "application/merge-patch+json" // RFC 7396, used in Consumes() of a PATCH Route
+)
+
+// patchOperation is an element of a JSON Patch document.
+type patchOperation struct {
+	Op    string           `json:"op"`
+	Path  *string          `json:"path"`
+	From  *string          `json:"from"`
+	Value *json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies the patch in the request body to the target, a pointer to the current entity.
+// The Content-Type of the request decides the format: MIME_JSON_PATCH (RFC 6902) or MIME_MERGE_PATCH (RFC 7396) ;
+// other types result in a ServiceError with status 415. A malformed patch results in status 400 and
+// a patch that cannot be applied (e.g. a missing path or failing test operation) in status 422.
+// The patched value is then checked by the Validator of the Container, if any. The target is only changed
+// if the patch is applied successfully.
+//
+//	ws.Route(ws.PATCH("/{id}").Consumes(restful.MIME_JSON_PATCH, restful.MIME_MERGE_PATCH).To(patchUser))
+//
+//	func patchUser(req *restful.Request, resp *restful.Response) {
+//		user := findUser(req.PathParameter("id"))
+//		if err := req.ApplyPatch(&user); err != nil {
+//			resp.WriteValidationError(err)
+//			return
+//		}
+//		...
+func (r *Request) ApplyPatch(target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("cannot apply patch to non-pointer or nil target:%T", target)
+	}
+	contentType := strings.TrimSpace(strings.Split(r.Request.Header.Get(HEADER_ContentType), ";")[0])
+	if contentType != MIME_JSON_PATCH && contentType != MIME_MERGE_PATCH {
+		return NewError(http.StatusUnsupportedMediaType, "Unable to apply patch of type:"+contentType)
+	}
+	patch, err := r.BodyBytes()
+	if err != nil {
+		return err
+	}
+	current, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := decodeJSONNumbers(current, &doc); err != nil {
+		return err
+	}
+	if contentType == MIME_JSON_PATCH {
+		doc, err = applyJSONPatch(doc, patch)
+	} else {
+		doc, err = applyMergePatch(doc, patch)
+	}
+	if err != nil {
+		return err
+	}
+	patched, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	// decode into a new value such that removed members do not keep their current value
+	// and the target is unchanged if the patched entity is invalid
+	fresh := reflect.New(targetValue.Elem().Type())
+	if err := json.Unmarshal(patched, fresh.Interface()); err != nil {
+		return NewError(http.StatusUnprocessableEntity, "patched entity is invalid: "+err.Error())
+	}
+	if err := r.validate(fresh.Interface(), http.StatusUnprocessableEntity); err != nil {
+		return err
+	}
+	targetValue.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// decodeJSONNumbers decodes the data keeping numbers as is, e.g. large identifiers.
+func decodeJSONNumbers(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// applyMergePatch applies a JSON Merge Patch document to the doc.
+func applyMergePatch(doc interface{}, patch []byte) (interface{}, error) {
+	var merge interface{}
+	if err := decodeJSONNumbers(patch, &merge); err != nil {
+		return nil, NewError(http.StatusBadRequest, "malformed merge patch: "+err.Error())
+	}
+	return mergePatch(doc, merge), nil
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = map[string]interface{}{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+		} else {
+			object[name] = mergePatch(object[name], value)
+		}
+	}
+	return object
+}
+
+// applyJSONPatch applies the operations of a JSON Patch document to the doc ; all or nothing.
+func applyJSONPatch(doc interface{}, patch []byte) (interface{}, error) {
+	operations := []patchOperation{}
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, NewError(http.StatusBadRequest, "malformed JSON patch: "+err.Error())
+	}
+	for i, each := range operations {
+		if err := each.check(); err != nil {
+			return nil, NewError(http.StatusBadRequest, fmt.Sprintf("malformed JSON patch operation %d: %v", i, err))
+		}
+	}
+	for i, each := range operations {
+		var err error
+		if doc, err = each.apply(doc); err != nil {
+			return nil, NewError(http.StatusUnprocessableEntity, fmt.Sprintf("unable to apply JSON patch operation %d (%s %s): %v", i, each.Op, *each.Path, err))
+		}
+	}
+	return doc, nil
+}
+
+// check returns an error if a member required by the operation is missing.
+func (o patchOperation) check() error {
+	switch o.Op {
+	case "add", "replace", "test":
+		if o.Value == nil {
+			return fmt.Errorf("missing value for %q", o.Op)
+		}
+	case "move", "copy":
+		if o.From == nil {
+			return fmt.Errorf("missing from for %q", o.Op)
+		}
+		if _, err := pointerTokens(*o.From); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("unknown op %q", o.Op)
+	}
+	if o.Path == nil {
+		return fmt.Errorf("missing path for %q", o.Op)
+	}
+	_, err := pointerTokens(*o.Path)
+	return err
+}
+
+func (o patchOperation) apply(doc interface{}) (interface{}, error) {
+	path, _ := pointerTokens(*o.Path)
+	switch o.Op {
+	case "add", "replace":
+		var value interface{}
+		if err := decodeJSONNumbers(*o.Value, &value); err != nil {
+			return nil, err
+		}
+		return setPointer(doc, path, value, o.Op == "add")
+	case "remove":
+		return removePointer(doc, path)
+	case "test":
+		var value interface{}
+		if err := decodeJSONNumbers(*o.Value, &value); err != nil {
+			return nil, err
+		}
+		current, err := getPointer(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !equalJSON(current, value) {
+			return nil, fmt.Errorf("value is %v", current)
+		}
+		return doc, nil
+	}
+	// move or copy
+	from, _ := pointerTokens(*o.From)
+	value, err := getPointer(doc, from)
+	if err != nil {
+		return nil, err
+	}
+	if o.Op == "move" {
+		if *o.Path == *o.From {
+			return doc, nil
+		}
+		if strings.HasPrefix(*o.Path, *o.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into itself", *o.From)
+		}
+		if doc, err = removePointer(doc, from); err != nil {
+			return nil, err
+		}
+	} else if value, err = deepCopyJSON(value); err != nil {
+		return nil, err
+	}
+	return setPointer(doc, path, value, true)
+}
+
+// equalJSON returns whether the decoded JSON values are equal ; numbers are compared by value, e.g. 1 equals 1.0.
+func equalJSON(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, _, errA := big.ParseFloat(a.String(), 10, 256, big.ToNearestEven)
+		y, _, errB := big.ParseFloat(b.String(), 10, 256, big.ToNearestEven)
+		if errA != nil || errB != nil {
+			return a == b
+		}
+		return x.Cmp(y) == 0
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, each := range a {
+			other, ok := b[key]
+			if !ok || !equalJSON(each, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalJSON(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func deepCopyJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	err = decodeJSONNumbers(data, &copied)
+	return copied, err
+}
+
+// pointerTokens returns the unescaped reference tokens of a JSON Pointer (RFC 6901).
+func pointerTokens(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return []string{}, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, each := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(each, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// arrayIndex returns the index of the token in an array of the size ; "-" is the index after the last element.
+func arrayIndex(token string, size int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return size, nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (token != "0" && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > size || (index == size && !allowEnd) {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+func getPointer(doc interface{}, tokens []string) (interface{}, error) {
+	for _, each := range tokens {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[each]
+			if !ok {
+				return nil, fmt.Errorf("member %q not found", each)
+			}
+			doc = value
+		case []interface{}:
+			index, err := arrayIndex(each, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[index]
+		default:
+			return nil, fmt.Errorf("cannot find %q in a value", each)
+		}
+	}
+	return doc, nil
+}
+
+// setPointer returns the doc in which the value is added (insert) or replaced at the location of the tokens.
+func setPointer(doc interface{}, tokens []string, value interface{}, insert bool) (interface{}, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	token, rest := tokens[0], tokens[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok && (len(rest) > 0 || !insert) {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+		if len(rest) == 0 {
+			node[token] = value
+			return node, nil
+		}
+		child, err := setPointer(child, rest, value, insert)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node), insert && len(rest) == 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			child, err := setPointer(node[index], rest, value, insert)
+			if err != nil {
+				return nil, err
+			}
+			node[index] = child
+			return node, nil
+		}
+		if !insert {
+			node[index] = value
+			return node, nil
+		}
+		node = append(node, nil)
+		copy(node[index+1:], node[index:])
+		node[index] = value
+		return node, nil
+	}
+	return nil, fmt.Errorf("cannot set %q in a value", token)
+}
+
+// removePointer returns the doc from which the value at the location of the tokens is removed.
+func removePointer(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the whole document")
+	}
+	token, rest := tokens[0], tokens[1:]
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		child, ok := node[token]
+		if !ok {
+			return nil, fmt.Errorf("member %q not found", token)
+		}
+		if len(rest) == 0 {
+			delete(node, token)
+			return node, nil
+		}
+		child, err := removePointer(child, rest)
+		if err != nil {
+			return nil, err
+		}
+		node[token] = child
+		return node, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(node), false)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) == 0 {
+			return append(node[:index], node[index+1:]...), nil
+		}
+		child, err := removePointer(node[index], rest)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = child
+		return node, nil
+	}
+	return nil, fmt.Errorf("cannot remove %q from a value", token)
+}
@@ -0,0 +1,129 @@
+package restful
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type patchedUser struct {
+	ID    int64             `json:"id"`
+	Name  string            `json:"name"`
+	Email string            `json:"email,omitempty"`
+	Tags  []string          `json:"tags"`
+	Props map[string]string `json:"props,omitempty"`
+}
+
+func newPatchRequest(contentType, body string) *Request {
+	httpRequest, _ := http.NewRequest("PATCH", "/users/1", strings.NewReader(body))
+	httpRequest.Header.Set(HEADER_ContentType, contentType)
+	return NewRequest(httpRequest)
+}
+
+// go test -v -test.run TestApplyJSONPatch ...restful
+func TestApplyJSONPatch(t *testing.T) {
+	user := patchedUser{ID: 9007199254740993, Name: "ann", Email: "ann@example.com", Tags: []string{"a", "c"}}
+	req := newPatchRequest(MIME_JSON_PATCH, `[
+		{"op":"test", "path":"/name", "value":"ann"},
+		{"op":"test", "path":"/id", "value":9.007199254740993e15},
+		{"op":"replace", "path":"/name", "value":"bob"},
+		{"op":"add", "path":"/tags/1", "value":"b"},
+		{"op":"add", "path":"/tags/-", "value":"d"},
+		{"op":"remove", "path":"/email"},
+		{"op":"add", "path":"/props", "value":{}},
+		{"op":"copy", "from":"/name", "path":"/props/a~1b"}
+	]`)
+	if err := req.ApplyPatch(&user); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := user.ID, int64(9007199254740993); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := user.Name, "bob"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := strings.Join(user.Tags, ","), "a,b,c,d"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := user.Email, ""; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := user.Props["a/b"], "bob"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestApplyJSONPatchMove(t *testing.T) {
+	user := patchedUser{Name: "ann", Tags: []string{"a", "b"}}
+	req := newPatchRequest(MIME_JSON_PATCH, `[{"op":"move", "from":"/tags/0", "path":"/email"}]`)
+	if err := req.ApplyPatch(&user); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := user.Email+" "+strings.Join(user.Tags, ","), "a b"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestApplyPatchErrors ...restful
+func TestApplyPatchErrors(t *testing.T) {
+	for _, each := range []struct {
+		contentType string
+		body        string
+		status      int
+	}{
+		{MIME_JSON, `{}`, http.StatusUnsupportedMediaType},
+		{MIME_JSON_PATCH, `{"op":"add"}`, http.StatusBadRequest},
+		{MIME_JSON_PATCH, `[{"op":"jump", "path":"/name"}]`, http.StatusBadRequest},
+		{MIME_JSON_PATCH, `[{"op":"add", "path":"/name"}]`, http.StatusBadRequest},
+		{MIME_JSON_PATCH, `[{"op":"replace", "path":"name", "value":"bob"}]`, http.StatusBadRequest},
+		{MIME_JSON_PATCH, `[{"op":"replace", "path":"/missing", "value":"bob"}]`, http.StatusUnprocessableEntity},
+		{MIME_JSON_PATCH, `[{"op":"add", "path":"/tags/5", "value":"x"}]`, http.StatusUnprocessableEntity},
+		{MIME_JSON_PATCH, `[{"op":"test", "path":"/name", "value":"bob"}]`, http.StatusUnprocessableEntity},
+		{MIME_JSON_PATCH, `[{"op":"test", "path":"/tags", "value":["a", 1.0]}]`, http.StatusUnprocessableEntity},
+		{MIME_JSON_PATCH, `[{"op":"replace", "path":"/name", "value":42}]`, http.StatusUnprocessableEntity},
+		{MIME_MERGE_PATCH, `{"name":`, http.StatusBadRequest},
+	} {
+		user := patchedUser{Name: "ann", Tags: []string{"a"}}
+		err := newPatchRequest(each.contentType, each.body).ApplyPatch(&user)
+		serviceError, ok := err.(ServiceError)
+		if !ok || serviceError.Code != each.status {
+			t.Errorf("%s: got %v want status %d", each.body, err, each.status)
+		}
+		if user.Name != "ann" {
+			t.Errorf("%s: target changed to %v", each.body, user.Name)
+		}
+	}
+}
+
+// go test -v -test.run TestApplyMergePatch ...restful
+func TestApplyMergePatch(t *testing.T) {
+	user := patchedUser{Name: "ann", Email: "ann@example.com", Props: map[string]string{"a": "1", "b": "2"}}
+	req := newPatchRequest(MIME_MERGE_PATCH+"; charset=utf-8", `{"email":null, "props":{"a":null, "c":"3"}, "tags":["x"]}`)
+	if err := req.ApplyPatch(&user); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := user.Name+"|"+user.Email+"|"+user.Props["b"]+user.Props["c"]+"|"+user.Tags[0], "ann||23|x"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if _, ok := user.Props["a"]; ok {
+		t.Error("a should be removed")
+	}
+}
+
+func TestApplyPatchValidates(t *testing.T) {
+	req := newPatchRequest(MIME_MERGE_PATCH, `{"name":""}`)
+	req.validator = ValidatorFunc(func(value interface{}) error {
+		if value.(*patchedUser).Name == "" {
+			return ValidationError{Fields: []FieldError{{Field: "name", Message: "required"}}}
+		}
+		return nil
+	})
+	user := patchedUser{Name: "ann"}
+	err := req.ApplyPatch(&user)
+	if verr, ok := err.(ValidationError); !ok || verr.Status != http.StatusUnprocessableEntity {
+		t.Errorf("got %v", err)
+	}
+	if got, want := user.Name, "ann"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -93,9 +93,17 @@ func (r RouterJSR311) detectRoute(routes []Route, httpRequest *http.Request) (*R
 
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2
 // n/m > n/* > */*
+// The Route that produces the MIME type with the highest quality in the Accept header wins ; on equal quality
+// the Route whose MIME type matches the most specific media range, then the first Route.
 func (r RouterJSR311) bestMatchByMedia(routes []Route, contentType string, accept string) *Route {
-	// TODO
-	return &routes[0]
+	ranges := ParseAccept(accept)
+	best, quality, specificity := 0, 0.0, 0
+	for i, each := range routes {
+		if q, s := each.acceptPreference(ranges); morePreferred(q, s, quality, specificity) {
+			best, quality, specificity = i, q, s
+		}
+	}
+	return &routes[best]
 }
 
 // http://jsr311.java.net/nonav/releases/1.1/spec/spec3.html#x3-360003.7.2  (step 2)
@@ -0,0 +1,46 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful/log"
+)
+
+// LateWritePolicy determines what happens if a Filter writes to the Response after
+// the RouteFunction has returned having written (part of) the body.
+// Such writes are appended to the body which, for chunked or compressed responses, silently corrupts it.
+type LateWritePolicy int
+
+const (
+	// LogLateWrites logs the first late write of a Response ; this is the default
+	LogLateWrites LateWritePolicy = iota
+	// PanicOnLateWrites panics on a late write ; use this during development
+	PanicOnLateWrites
+	// IgnoreLateWrites does not detect late writes
+	IgnoreLateWrites
+)
+
+// LateWrites sets the policy for writes by a Filter after the RouteFunction completed the response body.
+func (c *Container) LateWrites(policy LateWritePolicy) {
+	c.lateWritePolicy = policy
+}
+
+// checkLateWrite reports a write of size bytes if the RouteFunction has already completed the body.
+func (r *Response) checkLateWrite(size int) {
+	if !r.routeFunctionDone || r.contentLength == 0 || r.lateWriteReported {
+		return
+	}
+	message := fmt.Sprintf("[restful] filter writes %d bytes after the RouteFunction completed a response body of %d bytes", size, r.contentLength)
+	switch r.lateWritePolicy {
+	case LogLateWrites:
+		r.lateWriteReported = true
+		log.Print(message)
+	case PanicOnLateWrites:
+		r.lateWriteReported = true
+		panic(message)
+	}
+}
@@ -0,0 +1,52 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestLateWrites ...restful
+func TestLateWrites(t *testing.T) {
+	container := NewContainer()
+	container.LateWrites(PanicOnLateWrites)
+	container.DoNotRecover(false)
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		chain.ProcessFilter(req, resp)
+		resp.Write([]byte("trailer"))
+	})
+	ws := new(WebService).Path("/late")
+	ws.Route(ws.GET("/body").To(writeFood).Produces(MIME_JSON))
+	ws.Route(ws.GET("/nobody").To(func(req *Request, resp *Response) {}))
+	container.Add(ws)
+
+	panicked := false
+	container.RecoverHandler(func(reason interface{}, w http.ResponseWriter) { panicked = true })
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/late/body", nil)
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if !panicked {
+		t.Error("expected panic on late write")
+	}
+
+	panicked = false
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/late/nobody", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if panicked {
+		t.Error("unexpected panic if the RouteFunction wrote no body")
+	}
+	if got, want := httpWriter.Body.String(), "trailer"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestLateWrites_Ignore(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	resp.lateWritePolicy = IgnoreLateWrites
+	resp.Write([]byte("body"))
+	resp.routeFunctionDone = true
+	resp.Write([]byte("trailer"))
+	if resp.lateWriteReported {
+		t.Error("late write should be ignored")
+	}
+}
@@ -0,0 +1,82 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "time"
+
+// LifecycleEvent is passed to the lifecycle hooks of a Container.
+type LifecycleEvent struct {
+	Request  *Request
+	Response *Response
+	Start    time.Time     // time at which the Container received the request
+	Elapsed  time.Duration // time since Start
+	Entity   interface{}   // the value written ; only set for OnEntityWritten
+	Err      error         // error writing the entity or selecting the route (e.g. a 404 ServiceError) ; can be nil
+}
+
+// LifecycleHook is a function that is called at a moment in the handling of a request.
+// Hooks are called synchronously ; they must be fast and must not write to the Response.
+type LifecycleHook func(event LifecycleEvent)
+
+// lifecycleHooks holds the hooks of a Container.
+type lifecycleHooks struct {
+	requestStart  []LifecycleHook
+	routeSelected []LifecycleHook
+	entityWritten []LifecycleHook
+	requestEnd    []LifecycleHook
+}
+
+func (h lifecycleHooks) isEmpty() bool {
+	return len(h.requestStart)+len(h.routeSelected)+len(h.entityWritten)+len(h.requestEnd) == 0
+}
+
+// requestLifecycle is the state for calling the hooks while handling one request.
+type requestLifecycle struct {
+	hooks   *lifecycleHooks
+	request *Request
+	start   time.Time
+	err     error // of selecting the route
+}
+
+func (l *requestLifecycle) fire(hooks []LifecycleHook, resp *Response, entity interface{}, err error) {
+	if len(hooks) == 0 {
+		return
+	}
+	event := LifecycleEvent{Request: l.request, Response: resp, Start: l.start, Elapsed: time.Since(l.start), Entity: entity, Err: err}
+	for _, each := range hooks {
+		each(event)
+	}
+}
+
+// entityWritten calls the OnEntityWritten hooks, if any.
+func (r *Response) entityWritten(entity interface{}, err error) {
+	if r.lifecycle != nil {
+		r.lifecycle.fire(r.lifecycle.hooks.entityWritten, r, entity, err)
+	}
+}
+
+// OnRequestStart adds a hook that is called when a request is received, before any filter.
+// Request.SelectedRoute can be inspected to know whether a Route matched.
+func (c *Container) OnRequestStart(hook LifecycleHook) {
+	c.lifecycleHooks.requestStart = append(c.lifecycleHooks.requestStart, hook)
+}
+
+// OnRouteSelected adds a hook that is called after the Route for a request is selected, before any filter.
+// It is not called if no Route matches ; the Err of the OnRequestEnd event then tells why.
+func (c *Container) OnRouteSelected(hook LifecycleHook) {
+	c.lifecycleHooks.routeSelected = append(c.lifecycleHooks.routeSelected, hook)
+}
+
+// OnEntityWritten adds a hook that is called each time an entity is written using WriteEntity, WriteHeaderAndEntity
+// or WriteErrorEntity.
+func (c *Container) OnEntityWritten(hook LifecycleHook) {
+	c.lifecycleHooks.entityWritten = append(c.lifecycleHooks.entityWritten, hook)
+}
+
+// OnRequestEnd adds a hook that is called after the response is completed, including after recovering from a panic.
+// The Response then has the final status and byte counts.
+func (c *Container) OnRequestEnd(hook LifecycleHook) {
+	c.lifecycleHooks.requestEnd = append(c.lifecycleHooks.requestEnd, hook)
+}
@@ -0,0 +1,93 @@
+package restful
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestLifecycleHooks ...restful
+func TestLifecycleHooks(t *testing.T) {
+	var events []string
+	var end LifecycleEvent
+	c := NewContainer()
+	c.OnRequestStart(func(e LifecycleEvent) { events = append(events, "start") })
+	c.OnRouteSelected(func(e LifecycleEvent) { events = append(events, "route:"+e.Request.SelectedRoutePath()) })
+	c.OnEntityWritten(func(e LifecycleEvent) { events = append(events, "entity:"+e.Entity.(food).Kind) })
+	c.OnRequestEnd(func(e LifecycleEvent) { events = append(events, "end"); end = e })
+	ws := new(WebService).Path("/foods").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{kind}").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(food{Kind: req.PathParameter("kind")})
+	}))
+	c.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/foods/apple", nil)
+	httpRequest.Header.Set("Accept", MIME_JSON)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+
+	if got, want := len(events), 4; got != want {
+		t.Fatalf("got %v want %v:%v", got, want, events)
+	}
+	for i, want := range []string{"start", "route:/foods/{kind}", "entity:apple", "end"} {
+		if got := events[i]; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+	if got, want := end.Response.StatusCode(), 200; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if end.Start.IsZero() || end.Elapsed < 0 {
+		t.Errorf("got start %v elapsed %v", end.Start, end.Elapsed)
+	}
+	if end.Err != nil {
+		t.Errorf("got %v want nil", end.Err)
+	}
+}
+
+// go test -v -test.run TestLifecycleHooksNotFound ...restful
+func TestLifecycleHooksNotFound(t *testing.T) {
+	var selected bool
+	var end LifecycleEvent
+	c := NewContainer()
+	c.OnRouteSelected(func(e LifecycleEvent) { selected = true })
+	c.OnRequestEnd(func(e LifecycleEvent) { end = e })
+	ws := new(WebService).Path("/foods")
+	ws.Route(ws.GET("").To(dummy))
+	c.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/foods/unknown/path", nil)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+
+	if selected {
+		t.Error("route selected hook called for unknown path")
+	}
+	var serviceError ServiceError
+	if !errors.As(end.Err, &serviceError) || serviceError.Code != 404 {
+		t.Errorf("got %v want 404 ServiceError", end.Err)
+	}
+	if got, want := end.Response.StatusCode(), 404; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestLifecycleHooksAfterPanic ...restful
+func TestLifecycleHooksAfterPanic(t *testing.T) {
+	var end LifecycleEvent
+	c := NewContainer()
+	c.DoNotRecover(false)
+	c.OnRequestEnd(func(e LifecycleEvent) { end = e })
+	ws := new(WebService).Path("/panic")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { panic("boom") }))
+	c.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/panic", nil)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+
+	if end.Response == nil {
+		t.Fatal("request end hook not called")
+	}
+	if got, want := end.Response.StatusCode(), 500; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Keys of the messages of the built-in error responses that a MessageCatalog can localize.
+const (
+	MessageNotFound             = "restful.notFound"             // 404 if no Route matches the path
+	MessageMethodNotAllowed     = "restful.methodNotAllowed"     // 405 if no Route matches the method
+	MessageNotAcceptable        = "restful.notAcceptable"        // 406 if no Route produces an accepted MIME type
+	MessageUnsupportedMediaType = "restful.unsupportedMediaType" // 415 if no Route consumes the Content-Type
+	MessageValidationFailed     = "restful.validationFailed"     // title of a ProblemDocument for a ValidationError
+	MessageInvalidParameters    = "restful.invalidParameters"    // title of a ProblemDocument for ParameterErrors
+)
+
+// MessageCatalog provides localized messages for the error responses written by the Container
+// and by Response.WriteValidationError.
+type MessageCatalog interface {
+	// Languages returns the language tags (e.g. "en", "nl-BE") for which the catalog has messages, preferred first.
+	Languages() []string
+	// Message returns the message for the key in the language, if the catalog has one.
+	Message(language, key string) (string, bool)
+}
+
+// MapMessageCatalog is a MessageCatalog that maps a language tag to the messages for that language.
+//
+//	restful.DefaultContainer.MessageCatalog(restful.MapMessageCatalog{
+//		"en": {restful.MessageNotFound: "Not found"},
+//		"nl": {restful.MessageNotFound: "Niet gevonden"},
+//	})
+type MapMessageCatalog map[string]map[string]string
+
+// Languages is part of MessageCatalog ; the tags are sorted because a map has no order.
+func (m MapMessageCatalog) Languages() []string {
+	tags := make([]string, 0, len(m))
+	for each := range m {
+		tags = append(tags, each)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// Message is part of MessageCatalog
+func (m MapMessageCatalog) Message(language, key string) (string, bool) {
+	message, ok := m[language][key]
+	return message, ok
+}
+
+// MessageCatalog sets the catalog used to localize error responses and to negotiate the language of requests.
+// Default is nil ; messages are written in English.
+func (c *Container) MessageCatalog(catalog MessageCatalog) {
+	c.messageCatalog = catalog
+}
+
+// MessageCatalog sets the catalog with the messages of this WebService, e.g. for its own error codes,
+// such that independently developed services keep their translations local.
+// When the WebService is added to a Container, it is merged with the catalog of the Container:
+// for requests dispatched to this WebService, a message is looked up in this catalog first and then in that of the Container.
+//
+//	ws.MessageCatalog(restful.MapMessageCatalog{
+//		"nl": {"orders.outOfStock": "Niet op voorraad"},
+//	})
+func (w *WebService) MessageCatalog(catalog MessageCatalog) *WebService {
+	w.messageCatalog = catalog
+	return w
+}
+
+// serviceMessageCatalog is the MessageCatalog of a WebService merged with the current one of its Container.
+type serviceMessageCatalog struct {
+	service   MessageCatalog
+	container *Container
+}
+
+// Languages is part of MessageCatalog ; those of the WebService come first.
+func (s serviceMessageCatalog) Languages() []string {
+	tags := s.service.Languages()
+	if s.container.messageCatalog == nil {
+		return tags
+	}
+	seen := map[string]bool{}
+	for _, each := range tags {
+		seen[strings.ToLower(each)] = true
+	}
+	for _, each := range s.container.messageCatalog.Languages() {
+		if !seen[strings.ToLower(each)] {
+			tags = append(tags, each)
+		}
+	}
+	return tags
+}
+
+// Message is part of MessageCatalog
+func (s serviceMessageCatalog) Message(language, key string) (string, bool) {
+	if message, ok := s.service.Message(language, key); ok {
+		return message, true
+	}
+	if s.container.messageCatalog == nil {
+		return "", false
+	}
+	return s.container.messageCatalog.Message(language, key)
+}
+
+// messageCatalogFor returns the catalog for requests dispatched to the WebService, which can be nil.
+func (c *Container) messageCatalogFor(service *WebService) MessageCatalog {
+	if service == nil || service.mergedCatalog == nil {
+		return c.messageCatalog
+	}
+	return service.mergedCatalog
+}
+
+// LanguageRange is an element of an Accept-Language header, e.g. "nl-BE;q=0.8".
+type LanguageRange struct {
+	Tag     string  // lowercase, e.g. "nl-be" or "*"
+	Quality float64 // 0..1 ; 0 means not acceptable
+}
+
+// ParseAcceptLanguage returns the language ranges of an Accept-Language header, highest quality first.
+// Ranges with equal quality keep the order given. Invalid elements are skipped.
+func ParseAcceptLanguage(header string) []LanguageRange {
+	ranges := []LanguageRange{}
+	for _, each := range strings.Split(header, ",") {
+		params := strings.Split(each, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if len(tag) == 0 {
+			continue
+		}
+		languageRange := LanguageRange{Tag: tag, Quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+					languageRange.Quality = q
+				}
+			}
+		}
+		ranges = append(ranges, languageRange)
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].Quality > ranges[j].Quality })
+	return ranges
+}
+
+// NegotiatedLanguage returns the language tag that best matches the Accept-Language header of the request.
+// The candidates are the supported tags or, if none are given, the languages of the MessageCatalog of the WebService and Container.
+// Without candidates, it returns the most preferred tag of the header.
+// A range matches a tag if it is equal, a prefix of it (e.g. "nl" matches "nl-BE") or the range
+// truncated to such a prefix (e.g. "nl-NL" matches "nl"). It returns an empty string if nothing matches.
+func (r *Request) NegotiatedLanguage(supported ...string) string {
+	if len(supported) == 0 && r.messageCatalog != nil {
+		supported = r.messageCatalog.Languages()
+	}
+	return negotiateLanguage(r.Request.Header.Get(HEADER_AcceptLanguage), supported)
+}
+
+func negotiateLanguage(header string, supported []string) string {
+	for _, each := range ParseAcceptLanguage(header) {
+		if each.Quality == 0 {
+			continue
+		}
+		if len(supported) == 0 {
+			if each.Tag != "*" {
+				return each.Tag
+			}
+			continue
+		}
+		if each.Tag == "*" {
+			return supported[0]
+		}
+		if tag, ok := matchLanguage(each.Tag, supported); ok {
+			return tag
+		}
+	}
+	return ""
+}
+
+// matchLanguage returns the supported tag that is equal to or starts with the range, or else
+// the one that equals the range with subtags removed.
+func matchLanguage(languageRange string, supported []string) (string, bool) {
+	for _, each := range supported {
+		tag := strings.ToLower(each)
+		if tag == languageRange || strings.HasPrefix(tag, languageRange+"-") {
+			return each, true
+		}
+	}
+	for dash := strings.LastIndex(languageRange, "-"); dash > 0; dash = strings.LastIndex(languageRange, "-") {
+		languageRange = languageRange[:dash]
+		for _, each := range supported {
+			if strings.ToLower(each) == languageRange {
+				return each, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LocalizedMessage returns the message for the key in the language negotiated with the MessageCatalog
+// of the WebService and Container, or else the fallback.
+// If the message is localized, the language becomes the Locale of the response unless one is set.
+func (r *Response) LocalizedMessage(key, fallback string) string {
+	if r.messageCatalog == nil {
+		return fallback
+	}
+	language := negotiateLanguage(r.requestAcceptLanguage, r.messageCatalog.Languages())
+	if len(language) == 0 {
+		return fallback
+	}
+	message, ok := r.messageCatalog.Message(language, key)
+	if !ok {
+		return fallback
+	}
+	if !r.committed && len(r.renderOptions.Locale) == 0 {
+		r.renderOptions.Locale = language
+	}
+	return message
+}
+
+// serviceErrorMessageKeys maps the status of a ServiceError from route selection to the key of its message.
+var serviceErrorMessageKeys = map[int]string{
+	http.StatusNotFound:             MessageNotFound,
+	http.StatusMethodNotAllowed:     MessageMethodNotAllowed,
+	http.StatusNotAcceptable:        MessageNotAcceptable,
+	http.StatusUnsupportedMediaType: MessageUnsupportedMediaType,
+}
+
+// localizedServiceError returns the ServiceError with its message localized, if the catalog has one.
+func (r *Response) localizedServiceError(err ServiceError) ServiceError {
+	if key, ok := serviceErrorMessageKeys[err.Code]; ok {
+		err.Message = r.LocalizedMessage(key, err.Message)
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestParseAcceptLanguage ...restful
+func TestParseAcceptLanguage(t *testing.T) {
+	ranges := ParseAcceptLanguage("fr;q=0.5, nl-BE, en;q=0.8, ,de;q=0")
+	want := []LanguageRange{{"nl-be", 1}, {"en", 0.8}, {"fr", 0.5}, {"de", 0}}
+	if got, want := len(ranges), len(want); got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	for i, each := range want {
+		if got := ranges[i]; got != each {
+			t.Errorf("got %v want %v", got, each)
+		}
+	}
+}
+
+// go test -v -test.run TestNegotiatedLanguage ...restful
+func TestNegotiatedLanguage(t *testing.T) {
+	for _, each := range []struct {
+		header    string
+		supported []string
+		want      string
+	}{
+		{"", []string{"en"}, ""},
+		{"nl-BE, en;q=0.5", nil, "nl-be"},
+		{"*, nl;q=0.5", nil, "nl"},
+		{"nl-NL, en;q=0.5", []string{"en", "nl"}, "nl"},
+		{"nl, en;q=0.5", []string{"en", "nl-BE"}, "nl-BE"},
+		{"de, en;q=0.5", []string{"fr", "en"}, "en"},
+		{"de, *;q=0.1", []string{"fr", "en"}, "fr"},
+		{"en;q=0, fr;q=0.1", []string{"en"}, ""},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "/", nil)
+		httpRequest.Header.Set("Accept-Language", each.header)
+		if got := NewRequest(httpRequest).NegotiatedLanguage(each.supported...); got != each.want {
+			t.Errorf("%q %v: got %q want %q", each.header, each.supported, got, each.want)
+		}
+	}
+}
+
+// go test -v -test.run TestMessageCatalog ...restful
+func TestMessageCatalog(t *testing.T) {
+	c := NewContainer()
+	c.MessageCatalog(MapMessageCatalog{
+		"en": {MessageNotFound: "Not found"},
+		"nl": {MessageNotFound: "Niet gevonden", MessageMethodNotAllowed: "Methode niet toegestaan", MessageValidationFailed: "Validatie mislukt"},
+	})
+	c.Validator(ValidatorFunc(func(value interface{}) error {
+		return ValidationError{Fields: []FieldError{{Field: "kind", Message: "required"}}}
+	}))
+	var negotiated string
+	ws := new(WebService).Path("/foods").Consumes(MIME_JSON).Produces(MIME_JSON)
+	ws.Route(ws.POST("").To(func(req *Request, resp *Response) {
+		negotiated = req.NegotiatedLanguage()
+		var f food
+		resp.WriteValidationError(req.ReadEntity(&f))
+	}))
+	c.Add(ws)
+
+	for _, each := range []struct {
+		method, path, language string
+		code                   int
+		body, contentLanguage  string
+	}{
+		{"GET", "/foods/x/y", "nl-NL,en;q=0.5", 404, "Niet gevonden", "nl"},
+		{"GET", "/foods/x/y", "fr", 404, "404: Page Not Found", ""},
+		{"PUT", "/foods", "nl", 405, "Methode niet toegestaan", "nl"},
+		{"PUT", "/foods", "en", 405, "405: Method Not Allowed", ""},
+		{"POST", "/foods", "nl", 422, `"Validatie mislukt"`, "nl"},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, each.path, strings.NewReader(`{}`))
+		httpRequest.Header.Set("Content-Type", MIME_JSON)
+		httpRequest.Header.Set("Accept-Language", each.language)
+		httpWriter := httptest.NewRecorder()
+		c.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.code; got != want {
+			t.Errorf("%s %s: got %v want %v", each.method, each.language, got, want)
+		}
+		if got := httpWriter.Body.String(); !strings.Contains(got, each.body) {
+			t.Errorf("%s %s: got %v want %v", each.method, each.language, got, each.body)
+		}
+		if got, want := httpWriter.Header().Get("Content-Language"), each.contentLanguage; got != want {
+			t.Errorf("%s %s: got %v want %v", each.method, each.language, got, want)
+		}
+	}
+	if got, want := negotiated, "nl"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestWebServiceMessageCatalog ...restful
+func TestWebServiceMessageCatalog(t *testing.T) {
+	c := NewContainer()
+	c.MessageCatalog(MapMessageCatalog{
+		"nl": {MessageMethodNotAllowed: "Methode niet toegestaan", "orders.outOfStock": "Niet beschikbaar"},
+	})
+	ws := new(WebService).Path("/orders").Produces(MIME_JSON).MessageCatalog(MapMessageCatalog{
+		"nl": {"orders.outOfStock": "Niet op voorraad"},
+		"de": {"orders.outOfStock": "Nicht vorrätig"},
+	})
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteErrorString(http.StatusConflict, resp.LocalizedMessage("orders.outOfStock", "out of stock"))
+	}))
+	c.Add(ws)
+	other := new(WebService).Path("/users").Produces(MIME_JSON)
+	other.Route(other.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteErrorString(http.StatusConflict, resp.LocalizedMessage("orders.outOfStock", "out of stock"))
+	}))
+	c.Add(other)
+
+	for _, each := range []struct {
+		method, path, language string
+		body, contentLanguage  string
+	}{
+		{"GET", "/orders", "nl", "Niet op voorraad", "nl"},
+		{"GET", "/orders", "de", "Nicht vorrätig", "de"},
+		{"GET", "/orders", "fr", "out of stock", ""},
+		{"PUT", "/orders", "nl", "Methode niet toegestaan", "nl"},
+		{"GET", "/users", "nl", "Niet beschikbaar", "nl"},
+		{"GET", "/users", "de", "out of stock", ""},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, each.path, nil)
+		httpRequest.Header.Set("Accept-Language", each.language)
+		httpWriter := httptest.NewRecorder()
+		c.ServeHTTP(httpWriter, httpRequest)
+		if got := httpWriter.Body.String(); !strings.Contains(got, each.body) {
+			t.Errorf("%s %s %s: got %v want %v", each.method, each.path, each.language, got, each.body)
+		}
+		if got, want := httpWriter.Header().Get("Content-Language"), each.contentLanguage; got != want {
+			t.Errorf("%s %s %s: got %v want %v", each.method, each.path, each.language, got, want)
+		}
+	}
+}
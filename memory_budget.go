@@ -0,0 +1,162 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"math/rand"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+
+	"github.com/emicklei/go-restful/log"
+)
+
+// KeyMemoryBudget is the Route metadata key for the number of bytes (int or uint64) that
+// overrides the budget of the MemoryBudget filter for that Route.
+const KeyMemoryBudget = "restful.memoryBudget"
+
+// heapAllocsMetric is the cumulative number of bytes allocated on the heap by the process.
+const heapAllocsMetric = "/gc/heap/allocs:bytes"
+
+// MemoryReport describes a sampled request that allocated more than its budget.
+type MemoryReport struct {
+	Route     string // Operation or "METHOD path", see RouteVars
+	Allocated uint64 // bytes allocated by the process while the chain ran
+	Budget    uint64
+	// Shared is true if other sampled requests were in progress ; Allocated then includes their allocations.
+	Shared bool
+}
+
+// MemoryBudgetStats holds the counters of a Route.
+type MemoryBudgetStats struct {
+	Samples      uint64 `json:"samples"`
+	Exceeded     uint64 `json:"exceeded"`
+	MaxAllocated uint64 `json:"maxAllocated"`
+}
+
+// MemoryBudget is used to create a Filter that measures, for a sampled subset of requests, the heap allocations
+// while the rest of the chain runs and flags the Routes that exceed a budget.
+// The runtime only counts allocations per process, therefore measurements include the allocations of
+// any other goroutine ; they are an upper bound that is accurate for requests that run alone.
+// MemoryBudget is an expvar.Var ; publish it to serve its stats by /debug/vars.
+//
+//	budget := restful.NewMemoryBudget(1 << 20)
+//	budget.Rate = 0.01
+//	restful.Filter(budget.Filter)
+//	expvar.Publish("restful.memory", budget)
+type MemoryBudget struct {
+	Bytes uint64 // budget per request for Routes without KeyMemoryBudget metadata
+	// Rate is the fraction (0..1) of requests that is sampled. Ignored if Sample is set.
+	Rate float64
+	// Sample, if set, decides whether a request is sampled.
+	Sample func(req *Request) bool
+	// Report is called for each sampled request that exceeds its budget ; default logs the report.
+	Report func(report MemoryReport)
+
+	inFlight int64 // number of sampled requests in progress
+	lock     sync.Mutex
+	stats    map[string]*MemoryBudgetStats
+}
+
+// NewMemoryBudget returns a MemoryBudget that samples all requests ; set Rate or Sample to reduce overhead.
+func NewMemoryBudget(bytes uint64) *MemoryBudget {
+	return &MemoryBudget{Bytes: bytes, Rate: 1, stats: map[string]*MemoryBudgetStats{}}
+}
+
+// Filter measures the allocations of the rest of the chain if the request is sampled.
+func (m *MemoryBudget) Filter(req *Request, resp *Response, chain *FilterChain) {
+	route := req.SelectedRoute()
+	if route == nil || !m.sampled(req) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	budget := m.Bytes
+	switch override := route.Metadata[KeyMemoryBudget].(type) {
+	case int:
+		budget = uint64(override)
+	case uint64:
+		budget = override
+	}
+	allocated, shared := m.measure(req, resp, chain)
+
+	report := MemoryReport{Route: routeVarsKey(route), Allocated: allocated, Budget: budget, Shared: shared}
+	exceeded := budget > 0 && allocated > budget
+	m.count(report, exceeded)
+	if !exceeded {
+		return
+	}
+	if m.Report != nil {
+		m.Report(report)
+		return
+	}
+	log.Printf("[restful] %s allocated %d bytes, exceeding its budget of %d (shared=%v)", report.Route, allocated, budget, shared)
+}
+
+// measure processes the chain and returns the bytes allocated meanwhile and whether other sampled requests were in flight.
+// The in-flight count is also decremented if the chain panics.
+func (m *MemoryBudget) measure(req *Request, resp *Response, chain *FilterChain) (allocated uint64, shared bool) {
+	shared = atomic.AddInt64(&m.inFlight, 1) > 1
+	defer func() {
+		shared = atomic.AddInt64(&m.inFlight, -1) > 0 || shared
+	}()
+	before := heapAllocs()
+	chain.ProcessFilter(req, resp)
+	return heapAllocs() - before, shared
+}
+
+func (m *MemoryBudget) sampled(req *Request) bool {
+	if m.Sample != nil {
+		return m.Sample(req)
+	}
+	return m.Rate > 0 && (m.Rate >= 1 || rand.Float64() < m.Rate)
+}
+
+func (m *MemoryBudget) count(report MemoryReport, exceeded bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.stats == nil {
+		m.stats = map[string]*MemoryBudgetStats{}
+	}
+	stats, ok := m.stats[report.Route]
+	if !ok {
+		stats = new(MemoryBudgetStats)
+		m.stats[report.Route] = stats
+	}
+	stats.Samples++
+	if exceeded {
+		stats.Exceeded++
+	}
+	if report.Allocated > stats.MaxAllocated {
+		stats.MaxAllocated = report.Allocated
+	}
+}
+
+// Stats returns a copy of the counters per Route.
+func (m *MemoryBudget) Stats() map[string]MemoryBudgetStats {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	copied := map[string]MemoryBudgetStats{}
+	for k, v := range m.stats {
+		copied[k] = *v
+	}
+	return copied
+}
+
+// String returns the Stats as JSON ; it is part of the expvar.Var interface.
+func (m *MemoryBudget) String() string {
+	data, _ := json.Marshal(m.Stats())
+	return string(data)
+}
+
+// heapAllocs returns the cumulative number of bytes allocated by the process ; it does not stop the world.
+func heapAllocs() uint64 {
+	sample := []metrics.Sample{{Name: heapAllocsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
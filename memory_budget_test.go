@@ -0,0 +1,64 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var memoryBudgetSink []byte
+
+// go test -v -test.run TestMemoryBudget ...restful
+func TestMemoryBudget(t *testing.T) {
+	var reports []MemoryReport
+	budget := NewMemoryBudget(1 << 20)
+	budget.Report = func(report MemoryReport) { reports = append(reports, report) }
+	c := NewContainer()
+	c.Filter(budget.Filter)
+	ws := new(WebService).Path("/memory")
+	ws.Route(ws.GET("/big").Operation("big").To(func(req *Request, resp *Response) {
+		memoryBudgetSink = make([]byte, 4<<20)
+	}))
+	ws.Route(ws.GET("/small").Operation("small").To(dummy))
+	ws.Route(ws.GET("/allowed").Operation("allowed").Metadata(KeyMemoryBudget, 8<<20).To(func(req *Request, resp *Response) {
+		memoryBudgetSink = make([]byte, 4<<20)
+	}))
+	c.Add(ws)
+	for _, path := range []string{"/memory/big", "/memory/small", "/memory/allowed"} {
+		httpRequest, _ := http.NewRequest("GET", path, nil)
+		c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	}
+	if len(reports) != 1 || reports[0].Route != "big" {
+		t.Fatalf("got %v want one report for big", reports)
+	}
+	if got := reports[0].Allocated; got < 4<<20 {
+		t.Errorf("got %d allocated want at least %d", got, 4<<20)
+	}
+	stats := budget.Stats()
+	if got, want := stats["big"].Exceeded, uint64(1); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := stats["allowed"].Samples, uint64(1); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := budget.String(); !strings.Contains(got, `"small":{"samples":1`) {
+		t.Errorf("got %v", got)
+	}
+}
+
+// go test -v -test.run TestMemoryBudgetAfterPanic ...restful
+func TestMemoryBudgetAfterPanic(t *testing.T) {
+	budget := NewMemoryBudget(1 << 20)
+	c := NewContainer()
+	c.Filter(budget.Filter)
+	ws := new(WebService).Path("/memory")
+	ws.Route(ws.GET("/panic").To(func(req *Request, resp *Response) { panic("boom") }))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/memory/panic", nil)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got, want := atomic.LoadInt64(&budget.inFlight), int64(0); got != want {
+		t.Errorf("got %v in flight want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"math"
+	"strings"
+)
+
+// Reroot returns a copy of the WebService with the prefix added to its root path and the paths of its Routes,
+// e.g. to serve the WebService of a library under /api/v1. Routes added to the WebService later are not copied.
+func (w *WebService) Reroot(prefix string) *WebService {
+	w.routesLock.RLock()
+	defer w.routesLock.RUnlock()
+	root := strings.TrimRight(concatPath(prefix, w.rootPath), "/")
+	if len(root) == 0 {
+		root = "/"
+	}
+	copied := &WebService{
+		produces:       w.produces,
+		consumes:       w.consumes,
+		pathParameters: w.pathParameters,
+		filters:        append([]FilterFunction{}, w.filters...),
+		priorities:     append([]int{}, w.priorities...),
+		documentation:  w.documentation,
+		apiVersion:     w.apiVersion,
+		dynamicRoutes:  w.dynamicRoutes,
+		onAttach:       w.onAttach,
+		onDetach:       w.onDetach,
+		metadata:       copyMetadata(w.metadata),
+		messageCatalog: w.messageCatalog,
+	}
+	copied.Path(root)
+	for _, each := range w.routes {
+		each.Path = concatPath(root, each.relativePath)
+		each.Metadata = copyMetadata(each.Metadata)
+		each.postBuild()
+		copied.routes = append(copied.routes, each)
+	}
+	return copied
+}
+
+// copyMetadata returns a shallow copy of the metadata, or nil if it is nil.
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if metadata == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(metadata))
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Mount adds the WebServices of the other Container, rerooted under the prefix, to this Container.
+// It allows an API that is built by a library to be embedded in a host application, e.g. under /api/v1.
+// Filters of the other Container are processed after those of this Container and before those of each WebService.
+// Other settings of the other Container, such as its ServiceErrorHandler, do not apply.
+//
+//	host.Mount("/api/v1", library.NewContainer())
+func (c *Container) Mount(prefix string, other *Container) *Container {
+	for _, each := range other.RegisteredWebServices() {
+		mounted := each.Reroot(prefix)
+		if len(other.containerFilters) > 0 {
+			mounted.filters, mounted.priorities = insertFilter(mounted.filters, mounted.priorities, other.processFilters, math.MinInt)
+		}
+		c.Add(mounted)
+	}
+	return c
+}
+
+// processFilters is a FilterFunction that passes the request through the container filters and then continues the chain.
+func (c *Container) processFilters(req *Request, resp *Response, chain *FilterChain) {
+	nested := FilterChain{Filters: c.containerFilters, Target: func(req *Request, resp *Response) {
+		chain.ProcessFilter(req, resp)
+	}}
+	nested.ProcessFilter(req, resp)
+}
@@ -0,0 +1,74 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReroot(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy).Metadata("owner", "users"))
+	rerooted := ws.Reroot("/api/v1/")
+	rerooted.Routes()[0].Metadata["owner"] = "api"
+	if got, want := ws.Routes()[0].Metadata["owner"], "users"; got != want {
+		t.Errorf("original metadata changed, got %v want %v", got, want)
+	}
+	if got, want := rerooted.RootPath(), "/api/v1/users"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := rerooted.Routes()[0].Path, "/api/v1/users/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := ws.Routes()[0].Path, "/users/{id}"; got != want {
+		t.Errorf("original changed, got %v want %v", got, want)
+	}
+	if got, want := new(WebService).Path("/").Reroot("/api").RootPath(), "/api"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestMount ...restful
+func TestMount(t *testing.T) {
+	trace := ""
+	library := NewContainer()
+	library.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		trace += "library,"
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService).Path("/users").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		trace += "webservice,"
+		chain.ProcessFilter(req, resp)
+	})
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		trace += "route:" + req.PathParameter("id") + ":" + req.SelectedRoutePath()
+	}))
+	library.Add(ws)
+
+	host := NewContainer()
+	host.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		trace += "host,"
+		chain.ProcessFilter(req, resp)
+	})
+	host.Mount("/api/v1", library)
+
+	for _, each := range []struct {
+		path   string
+		status int
+		trace  string
+	}{
+		{"/api/v1/users/42", http.StatusOK, "host,library,webservice,route:42:/api/v1/users/{id}"},
+		{"/users/42", http.StatusNotFound, ""},
+	} {
+		trace = ""
+		httpRequest, _ := http.NewRequest("GET", each.path, nil)
+		httpWriter := httptest.NewRecorder()
+		host.ServeHTTP(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.status; got != want {
+			t.Errorf("%s: got %v want %v", each.path, got, want)
+		}
+		if got, want := trace, each.trace; got != want {
+			t.Errorf("%s: got %v want %v", each.path, got, want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is an element of an Accept header, e.g. "application/*;q=0.8".
+type MediaRange struct {
+	Type    string  // e.g. "application" or "*"
+	Subtype string  // e.g. "json" or "*"
+	Quality float64 // 0..1 ; 0 means not acceptable
+}
+
+// ParseAccept returns the media ranges of an Accept header in the order given.
+// An empty header is the same as "*/*". Invalid elements are skipped.
+func ParseAccept(accept string) []MediaRange {
+	if len(strings.TrimSpace(accept)) == 0 {
+		return []MediaRange{{Type: "*", Subtype: "*", Quality: 1}}
+	}
+	ranges := []MediaRange{}
+	for _, each := range strings.Split(accept, ",") {
+		params := strings.Split(each, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		slash := strings.Index(mime, "/")
+		if slash <= 0 || slash == len(mime)-1 {
+			continue
+		}
+		mediaRange := MediaRange{Type: mime[:slash], Subtype: mime[slash+1:], Quality: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q >= 0 && q <= 1 {
+					mediaRange.Quality = q
+				}
+			}
+		}
+		ranges = append(ranges, mediaRange)
+	}
+	return ranges
+}
+
+// specificity returns how well the range matches the MIME type: 3 = exact, 2 = type/*, 1 = */*, 0 = no match.
+func (m MediaRange) specificity(mime string) int {
+	slash := strings.Index(mime, "/")
+	if slash == -1 {
+		return 0
+	}
+	mimeType, mimeSubtype := strings.ToLower(mime[:slash]), strings.ToLower(mime[slash+1:])
+	switch {
+	case m.Type == "*" && m.Subtype == "*":
+		return 1
+	case m.Type == mimeType && m.Subtype == "*":
+		return 2
+	case m.Type == mimeType && m.Subtype == mimeSubtype:
+		return 3
+	}
+	return 0
+}
+
+// qualityOf returns the quality of the most specific range that matches the MIME type ; an offered "*/*" gets the highest quality.
+func qualityOf(ranges []MediaRange, mime string) float64 {
+	quality, _ := preferenceOf(ranges, mime)
+	return quality
+}
+
+// preferenceOf returns the quality and the specificity of the most specific range that matches the MIME type.
+// Of two MIME types with equal quality, the one with the higher specificity is preferred (RFC 9110, 12.5.1).
+func preferenceOf(ranges []MediaRange, mime string) (quality float64, specificity int) {
+	if mime == "*/*" {
+		for _, each := range ranges {
+			if each.Quality > quality {
+				quality, specificity = each.Quality, 1
+			}
+		}
+		return quality, specificity
+	}
+	for _, each := range ranges {
+		if s := each.specificity(mime); s > specificity {
+			quality, specificity = each.Quality, s
+		}
+	}
+	return quality, specificity
+}
+
+// morePreferred returns whether quality q1 with specificity s1 is preferred over q2 with s2.
+func morePreferred(q1 float64, s1 int, q2 float64, s2 int) bool {
+	return q1 > q2 || (q1 == q2 && s1 > s2)
+}
+
+// negotiatedMediaTypes returns the offered MIME types that are acceptable, most preferred first.
+// Offers with equal quality are ordered by the specificity of the matching range, then keep their order,
+// i.e. the preference of the server.
+func negotiatedMediaTypes(accept string, offers []string) []string {
+	if len(offers) == 0 {
+		return nil
+	}
+	ranges := ParseAccept(accept)
+	type candidate struct {
+		mime        string
+		quality     float64
+		specificity int
+	}
+	candidates := []candidate{}
+	for _, each := range offers {
+		// offered MIME types can have parameters, e.g. text/plain; charset=utf-8
+		mime := strings.TrimSpace(strings.Split(each, ";")[0])
+		if q, s := preferenceOf(ranges, mime); q > 0 {
+			candidates = append(candidates, candidate{each, q, s})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return morePreferred(candidates[i].quality, candidates[i].specificity, candidates[j].quality, candidates[j].specificity)
+	})
+	mimes := make([]string, len(candidates))
+	for i, each := range candidates {
+		mimes[i] = each.mime
+	}
+	return mimes
+}
+
+// NegotiateMediaType returns the offered MIME type that is most preferred according to the Accept header,
+// taking quality factors and wildcards into account. It returns false if none of the offers is acceptable.
+//
+//	NegotiateMediaType("application/xml;q=0.9, application/json", []string{MIME_XML, MIME_JSON}) // MIME_JSON, true
+func NegotiateMediaType(accept string, offers []string) (string, bool) {
+	mimes := negotiatedMediaTypes(accept, offers)
+	if len(mimes) == 0 {
+		return "", false
+	}
+	return mimes[0], true
+}
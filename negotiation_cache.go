@@ -0,0 +1,107 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+)
+
+// DefaultNegotiationCacheSize is the number of results of content negotiation that are cached by default.
+const DefaultNegotiationCacheSize = 256
+
+// mediaTypesCache holds the MIME types negotiated for an Accept header and the offers of a Route.
+var mediaTypesCache = newLRUCache[[]string](DefaultNegotiationCacheSize)
+
+// SetNegotiationCacheSize changes the number of negotiated MIME types, and EntityReaderWriters found for
+// unregistered MIME types (e.g. with a charset), that are cached by the raw Accept or Content-Type header
+// such that requests with identical headers are not parsed again.
+// Default is DefaultNegotiationCacheSize ; 0 disables caching.
+func SetNegotiationCacheSize(size int) {
+	mediaTypesCache.resize(size)
+	entityAccessRegistry.cache.resize(size)
+}
+
+// cachedNegotiatedMediaTypes returns negotiatedMediaTypes from the cache ; the result must not be modified.
+func cachedNegotiatedMediaTypes(accept string, offers []string) []string {
+	if len(offers) == 0 {
+		return nil
+	}
+	key := accept + "\n" + strings.Join(offers, "\n")
+	if mimes, ok := mediaTypesCache.get(key); ok {
+		return mimes
+	}
+	mimes := negotiatedMediaTypes(accept, offers)
+	mediaTypesCache.set(key, mimes)
+	return mimes
+}
+
+// lruCache is a bounded map from string keys to values that evicts the least recently used entry.
+type lruCache[V any] struct {
+	lock     sync.Mutex
+	capacity int
+	order    *list.List // of *lruCacheEntry, most recently used first
+	entries  map[string]*list.Element
+}
+
+type lruCacheEntry[V any] struct {
+	key   string
+	value V
+}
+
+func newLRUCache[V any](capacity int) *lruCache[V] {
+	return &lruCache[V]{capacity: capacity, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *lruCache[V]) get(key string) (value V, ok bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return value, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruCacheEntry[V]).value, true
+}
+
+func (c *lruCache[V]) set(key string, value V) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.capacity <= 0 {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruCacheEntry[V]).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruCacheEntry[V]{key: key, value: value})
+	c.evict()
+}
+
+// resize changes the capacity and evicts entries that no longer fit.
+func (c *lruCache[V]) resize(capacity int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.capacity = capacity
+	c.evict()
+}
+
+// clear removes all entries, e.g. when the values they were computed from change.
+func (c *lruCache[V]) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.order.Init()
+	c.entries = map[string]*list.Element{}
+}
+
+func (c *lruCache[V]) evict() {
+	for c.order.Len() > c.capacity && c.order.Len() > 0 {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruCacheEntry[V]).key)
+	}
+}
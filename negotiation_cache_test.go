@@ -0,0 +1,59 @@
+package restful
+
+import (
+	"testing"
+)
+
+// go test -v -test.run TestLRUCacheEvictsLeastRecentlyUsed ...restful
+func TestLRUCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := newLRUCache[int](2)
+	cache.set("a", 1)
+	cache.set("b", 2)
+	cache.get("a")
+	cache.set("c", 3)
+	if _, ok := cache.get("b"); ok {
+		t.Error("b should be evicted")
+	}
+	if got, ok := cache.get("a"); !ok || got != 1 {
+		t.Errorf("got %v,%v want 1,true", got, ok)
+	}
+	cache.resize(0)
+	cache.set("d", 4)
+	if _, ok := cache.get("d"); ok {
+		t.Error("size 0 should not cache")
+	}
+}
+
+// go test -v -test.run TestCachedNegotiatedMediaTypes ...restful
+func TestCachedNegotiatedMediaTypes(t *testing.T) {
+	accept := "application/xml;q=0.5, application/json"
+	offers := []string{MIME_XML, MIME_JSON}
+	first := cachedNegotiatedMediaTypes(accept, offers)
+	if got, want := first[0], MIME_JSON; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if _, ok := mediaTypesCache.get(accept + "\n" + MIME_XML + "\n" + MIME_JSON); !ok {
+		t.Error("result not cached")
+	}
+	if got, want := cachedNegotiatedMediaTypes(accept, []string{MIME_XML})[0], MIME_XML; got != want {
+		t.Errorf("got %v want %v ; offers are part of the key", got, want)
+	}
+}
+
+// go test -v -test.run TestAccessorAtCacheClearedOnRegister ...restful
+func TestAccessorAtCacheClearedOnRegister(t *testing.T) {
+	mime := "application/x-cached; charset=utf-8"
+	if _, ok := entityAccessRegistry.AccessorAt(mime); ok {
+		t.Fatal("unexpected accessor")
+	}
+	RegisterEntityAccessor("application/x-cached", entityJSONAccess{ContentType: "application/x-cached"})
+	defer func() {
+		entityAccessRegistry.protection.Lock()
+		delete(entityAccessRegistry.accessors, "application/x-cached")
+		entityAccessRegistry.cache.clear()
+		entityAccessRegistry.protection.Unlock()
+	}()
+	if _, ok := entityAccessRegistry.AccessorAt(mime); !ok {
+		t.Error("cached miss not cleared by RegisterEntityAccessor")
+	}
+}
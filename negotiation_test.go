@@ -0,0 +1,96 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// go test -v -test.run TestNegotiateMediaType ...restful
+func TestNegotiateMediaType(t *testing.T) {
+	offers := []string{MIME_XML, MIME_JSON}
+	for _, each := range []struct {
+		accept string
+		want   string
+		ok     bool
+	}{
+		{"", MIME_XML, true},
+		{"*/*", MIME_XML, true},
+		{"application/xml;q=0.9, application/json;q=1.0", MIME_JSON, true},
+		{"application/json;q=0.5, application/*;q=0.8", MIME_XML, true},
+		{"application/*, application/xml;q=0", MIME_JSON, true},
+		{"text/html, */*;q=0.1", MIME_XML, true},
+		{"text/html", "", false},
+		{"*/*;q=0", "", false},
+	} {
+		got, ok := NegotiateMediaType(each.accept, offers)
+		if got != each.want || ok != each.ok {
+			t.Errorf("accept %q: got %q,%v want %q,%v", each.accept, got, ok, each.want, each.ok)
+		}
+	}
+}
+
+func TestParseAcceptSkipsInvalid(t *testing.T) {
+	ranges := ParseAccept("text/plain;q=0.3, garbage, Text/HTML;level=1")
+	if got, want := len(ranges), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := ranges[1], (MediaRange{"text", "html", 1}); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestMatchesAcceptExcludedByQualityZero(t *testing.T) {
+	r := Route{Produces: []string{MIME_JSON}}
+	if r.matchesAccept("application/json;q=0, */*") {
+		t.Error("json is not acceptable")
+	}
+	if !r.matchesAccept("application/*") {
+		t.Error("accept should match application/*")
+	}
+}
+
+// go test -v -test.run TestEntityWriterHonorsQuality ...restful
+func TestEntityWriterHonorsQuality(t *testing.T) {
+	resp := Response{ResponseWriter: httptest.NewRecorder(), requestAccept: "application/xml;q=0.9, application/json", routeProduces: []string{MIME_XML, MIME_JSON}}
+	resp.WriteEntity(food{"apple"})
+	if got, want := resp.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRouteSelectionHonorsQuality ...restful
+func TestRouteSelectionHonorsQuality(t *testing.T) {
+	ws := new(WebService).Path("/food")
+	ws.Route(ws.GET("").Produces(MIME_XML).To(func(req *Request, resp *Response) { resp.Write([]byte("xml")) }))
+	ws.Route(ws.GET("").Produces(MIME_JSON).To(func(req *Request, resp *Response) { resp.Write([]byte("json")) }))
+	c := NewContainer().Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/food", nil)
+	httpRequest.Header.Set(HEADER_Accept, "application/xml;q=0.5, application/json")
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "json"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRouteSelectionPrefersSpecificRange ...restful
+func TestRouteSelectionPrefersSpecificRange(t *testing.T) {
+	ws := new(WebService).Path("/food")
+	ws.Route(ws.GET("").Produces(MIME_JSON).To(func(req *Request, resp *Response) { resp.Write([]byte("json")) }))
+	ws.Route(ws.GET("").Produces(MIME_XML).To(func(req *Request, resp *Response) { resp.Write([]byte("xml")) }))
+	c := NewContainer().Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/food", nil)
+	httpRequest.Header.Set(HEADER_Accept, "*/*;q=0.5, application/xml;q=0.5")
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "xml"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := negotiatedMediaTypes("*/*;q=0.5, application/xml;q=0.5", []string{MIME_JSON, MIME_XML}), []string{MIME_XML, MIME_JSON}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,58 @@
+// Command restful-scaffold generates WebService registration code and a handler interface
+// from a Swagger 2.0 or OpenAPI 3.x document in JSON format.
+//
+//	restful-scaffold -package users -name Users -o users_api.go users.json
+//
+// or, in a Go source file:
+//
+//	//go:generate restful-scaffold -package users -o users_api.go users.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/emicklei/go-restful/openapi"
+)
+
+func main() {
+	pkg := flag.String("package", "api", "name of the package of the generated file")
+	name := flag.String("name", "", "prefix of the Handler interface and WebService constructor ; default is derived from the title")
+	output := flag.String("o", "", "file to write ; default is standard output")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: restful-scaffold [flags] document.json")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *pkg, *name, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "restful-scaffold:", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, pkg, name, output string) error {
+	file, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	doc, err := openapi.ReadDocument(file)
+	if err != nil {
+		return fmt.Errorf("%s: %v", input, err)
+	}
+	source, err := openapi.Scaffold(doc, openapi.ScaffoldOptions{Package: pkg, Name: name, Source: filepath.Base(input)})
+	if err != nil {
+		return err
+	}
+	if len(output) == 0 {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(output, source, 0644)
+}
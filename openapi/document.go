@@ -0,0 +1,161 @@
+// Package openapi builds restful WebServices from an existing OpenAPI (Swagger 2.0 or OpenAPI 3.x) document.
+//
+// This enables spec-first development: paths, parameters and media types are taken from the document
+// and each operation is bound to a RouteFunction that is looked up by its operationId.
+//
+//	doc, err := openapi.ReadDocument(file)
+//	...
+//	ws, err := openapi.BuildWebService(doc, openapi.HandlerMap{
+//		"getUser":    users.findUser,
+//		"createUser": users.createUser,
+//	}.Lookup)
+//	...
+//	restful.Add(ws)
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strings"
+)
+
+// Document is the subset of an OpenAPI document that is needed to build WebServices.
+// Both Swagger 2.0 (swagger, basePath, consumes, produces) and OpenAPI 3.x (openapi, servers) fields are supported.
+type Document struct {
+	Swagger  string              `json:"swagger,omitempty"`
+	OpenAPI  string              `json:"openapi,omitempty"`
+	Info     Info                `json:"info"`
+	BasePath string              `json:"basePath,omitempty"`
+	Servers  []Server            `json:"servers,omitempty"`
+	Consumes []string            `json:"consumes,omitempty"`
+	Produces []string            `json:"produces,omitempty"`
+	Paths    map[string]PathItem `json:"paths"`
+}
+
+// Info holds the title and version of the API.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is an OpenAPI 3.x server ; only the path of its URL is used.
+type Server struct {
+	URL string `json:"url"`
+}
+
+// PathItem holds the operations available on a single path.
+type PathItem struct {
+	Get        *Operation  `json:"get,omitempty"`
+	Put        *Operation  `json:"put,omitempty"`
+	Post       *Operation  `json:"post,omitempty"`
+	Delete     *Operation  `json:"delete,omitempty"`
+	Options    *Operation  `json:"options,omitempty"`
+	Head       *Operation  `json:"head,omitempty"`
+	Patch      *Operation  `json:"patch,omitempty"`
+	Parameters []Parameter `json:"parameters,omitempty"` // shared by all operations
+}
+
+// Operations returns the operations of the PathItem by their (uppercase) HTTP method, in a fixed order.
+func (p PathItem) Operations() (methods []string, operations []*Operation) {
+	for _, each := range []struct {
+		method    string
+		operation *Operation
+	}{
+		{"GET", p.Get}, {"PUT", p.Put}, {"POST", p.Post}, {"DELETE", p.Delete},
+		{"OPTIONS", p.Options}, {"HEAD", p.Head}, {"PATCH", p.Patch},
+	} {
+		if each.operation != nil {
+			methods = append(methods, each.method)
+			operations = append(operations, each.operation)
+		}
+	}
+	return methods, operations
+}
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Consumes    []string            `json:"consumes,omitempty"` // Swagger 2.0
+	Produces    []string            `json:"produces,omitempty"` // Swagger 2.0
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"` // OpenAPI 3.x
+	Responses   map[string]Response `json:"responses,omitempty"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header, formData or body
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Type        string  `json:"type,omitempty"`   // Swagger 2.0
+	Format      string  `json:"format,omitempty"` // Swagger 2.0
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// DataTypeAndFormat returns the type and format of the parameter, either specified directly or by its schema.
+func (p Parameter) DataTypeAndFormat() (string, string) {
+	if len(p.Type) > 0 || p.Schema == nil {
+		return p.Type, p.Format
+	}
+	return p.Schema.Type, p.Schema.Format
+}
+
+// Schema is the subset of a JSON schema that is used for documenting parameters.
+type Schema struct {
+	Ref    string `json:"$ref,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// RequestBody is an OpenAPI 3.x request body ; its content keys are the media types it consumes.
+type RequestBody struct {
+	Description string                     `json:"description,omitempty"`
+	Required    bool                       `json:"required,omitempty"`
+	Content     map[string]json.RawMessage `json:"content,omitempty"`
+}
+
+// Response describes a response of an operation ; its content keys (OpenAPI 3.x) are the media types it produces.
+type Response struct {
+	Description string                     `json:"description"`
+	Content     map[string]json.RawMessage `json:"content,omitempty"`
+}
+
+// ReadDocument decodes an OpenAPI document in JSON format.
+func ReadDocument(reader io.Reader) (*Document, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return ParseDocument(data)
+}
+
+// ParseDocument decodes an OpenAPI document in JSON format.
+func ParseDocument(data []byte) (*Document, error) {
+	doc := new(Document)
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// RootPath returns the path all document paths are relative to ; basePath (2.0) or the path of the first server (3.x).
+func (d Document) RootPath() string {
+	root := d.BasePath
+	if len(root) == 0 && len(d.Servers) > 0 {
+		if u, err := url.Parse(d.Servers[0].URL); err == nil {
+			root = u.Path
+		}
+	}
+	root = strings.TrimRight(root, "/")
+	if len(root) == 0 {
+		return "/"
+	}
+	return root
+}
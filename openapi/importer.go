@@ -0,0 +1,197 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/emicklei/go-restful/log"
+)
+
+// HandlerLookup returns the RouteFunction for an operation. The key is the operationId or,
+// if the operation has none, the HTTP method and path separated by a space (e.g. "GET /users/{id}").
+type HandlerLookup func(key string) (restful.RouteFunction, bool)
+
+// HandlerMap is a simple HandlerLookup backed by a map.
+type HandlerMap map[string]restful.RouteFunction
+
+// Lookup is a HandlerLookup
+func (m HandlerMap) Lookup(key string) (restful.RouteFunction, bool) {
+	f, ok := m[key]
+	return f, ok
+}
+
+// BuildWebService creates a WebService, rooted at the RootPath of the document, with a Route for each operation.
+// Operations for which the lookup has no RouteFunction are bound to a function that responds with 501 (Not Implemented) ;
+// these are logged.
+func BuildWebService(doc *Document, lookup HandlerLookup) (*restful.WebService, error) {
+	if len(doc.Paths) == 0 {
+		return nil, errors.New("openapi: document has no paths")
+	}
+	ws := new(restful.WebService)
+	ws.Path(doc.RootPath()).Doc(doc.Info.Title).ApiVersion(doc.Info.Version)
+
+	// sort paths to get a predictable order of Routes
+	paths := []string{}
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		methods, operations := item.Operations()
+		for i, method := range methods {
+			ws.Route(buildRoute(ws, doc, path, method, item.Parameters, operations[i], lookup))
+		}
+	}
+	return ws, nil
+}
+
+// OperationKey returns the key that is used to lookup the RouteFunction of an operation.
+func OperationKey(method, path string, operation *Operation) string {
+	if len(operation.OperationID) > 0 {
+		return operation.OperationID
+	}
+	return method + " " + path
+}
+
+func buildRoute(ws *restful.WebService, doc *Document, path, method string, shared []Parameter, operation *Operation, lookup HandlerLookup) *restful.RouteBuilder {
+	key := OperationKey(method, path, operation)
+	function, ok := lookup(key)
+	if !ok {
+		log.Printf("[restful/openapi] no function for operation %s ; responding with 501", key)
+		function = notImplemented
+	}
+	builder := ws.Method(method).Path(path).To(function).
+		Operation(key).
+		Doc(operation.Summary).
+		Notes(operation.Description)
+
+	if consumes := consumedMediaTypes(doc, operation); len(consumes) > 0 {
+		builder.Consumes(consumes...)
+	}
+	if produces := producedMediaTypes(doc, operation); len(produces) > 0 {
+		builder.Produces(produces...)
+	}
+	for _, each := range mergedParameters(shared, operation.Parameters) {
+		if param := asRestfulParameter(ws, each); param != nil {
+			builder.Param(param)
+		}
+	}
+	codes := []string{}
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil {
+			builder.Returns(status, operation.Responses[code].Description, nil)
+		}
+	}
+	return builder
+}
+
+// mergedParameters returns the parameters of the path item with those of the operation ;
+// an operation parameter overrides the path item parameter with the same name and location.
+func mergedParameters(shared, own []Parameter) []Parameter {
+	type location struct{ name, in string }
+	overrides := map[location]Parameter{}
+	for _, each := range own {
+		overrides[location{each.Name, each.In}] = each
+	}
+	merged := []Parameter{}
+	for _, each := range shared {
+		key := location{each.Name, each.In}
+		if override, ok := overrides[key]; ok {
+			merged = append(merged, override)
+			delete(overrides, key)
+			continue
+		}
+		merged = append(merged, each)
+	}
+	for _, each := range own {
+		if _, ok := overrides[location{each.Name, each.In}]; ok {
+			merged = append(merged, each)
+		}
+	}
+	return merged
+}
+
+func consumedMediaTypes(doc *Document, operation *Operation) []string {
+	if len(operation.Consumes) > 0 {
+		return operation.Consumes
+	}
+	if operation.RequestBody != nil {
+		return sortedKeys(operation.RequestBody.Content)
+	}
+	return doc.Consumes
+}
+
+func producedMediaTypes(doc *Document, operation *Operation) []string {
+	if len(operation.Produces) > 0 {
+		return operation.Produces
+	}
+	seen := map[string]bool{}
+	produces := []string{}
+	for code, response := range operation.Responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		for _, each := range sortedKeys(response.Content) {
+			if !seen[each] {
+				seen[each] = true
+				produces = append(produces, each)
+			}
+		}
+	}
+	if len(produces) > 0 {
+		sort.Strings(produces)
+		return produces
+	}
+	return doc.Produces
+}
+
+func asRestfulParameter(ws *restful.WebService, p Parameter) *restful.Parameter {
+	var param *restful.Parameter
+	switch p.In {
+	case "path":
+		param = ws.PathParameter(p.Name, p.Description)
+	case "query":
+		param = ws.QueryParameter(p.Name, p.Description)
+	case "header":
+		param = ws.HeaderParameter(p.Name, p.Description)
+	case "formData":
+		param = ws.FormParameter(p.Name, p.Description)
+	case "body":
+		param = ws.BodyParameter(p.Name, p.Description)
+	default:
+		// e.g. cookie parameters or unresolved references
+		return nil
+	}
+	dataType, dataFormat := p.DataTypeAndFormat()
+	if len(dataType) > 0 {
+		param.DataType(dataType)
+	}
+	if len(dataFormat) > 0 {
+		param.DataFormat(dataFormat)
+	}
+	// path parameters are always required
+	return param.Required(p.Required || p.In == "path")
+}
+
+func sortedKeys(content map[string]json.RawMessage) []string {
+	keys := []string{}
+	for each := range content {
+		keys = append(keys, each)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func notImplemented(req *restful.Request, resp *restful.Response) {
+	resp.WriteErrorString(http.StatusNotImplemented, "501: Not Implemented")
+}
@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+const petstore = `{
+	"openapi": "3.0.0",
+	"info": {"title": "Petstore", "version": "1.0"},
+	"servers": [{"url": "http://example.com/api/v1"}],
+	"paths": {
+		"/pets/{petId}": {
+			"parameters": [{"name": "petId", "in": "path", "schema": {"type": "string", "format": "uuid"}}],
+			"get": {
+				"operationId": "getPet",
+				"summary": "get a pet",
+				"parameters": [{"name": "fields", "in": "query", "schema": {"type": "string"}}],
+				"responses": {
+					"200": {"description": "ok", "content": {"application/json": {}}},
+					"404": {"description": "not found"}
+				}
+			},
+			"delete": {
+				"parameters": [{"name": "petId", "in": "path", "description": "the pet to delete", "schema": {"type": "string"}}],
+				"responses": {"204": {"description": "deleted"}}
+			}
+		},
+		"/pets": {
+			"post": {
+				"operationId": "createPet",
+				"requestBody": {"content": {"application/json": {}, "application/xml": {}}},
+				"responses": {"201": {"description": "created", "content": {"application/json": {}}}}
+			}
+		}
+	}
+}`
+
+// go test -v -test.run TestBuildWebService ...openapi
+func TestBuildWebService(t *testing.T) {
+	doc, err := ParseDocument([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws, err := BuildWebService(doc, HandlerMap{
+		"getPet": func(req *restful.Request, resp *restful.Response) {
+			io.WriteString(resp, "pet "+req.PathParameter("petId"))
+		},
+	}.Lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ws.RootPath(), "/api/v1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	routes := ws.Routes()
+	if got, want := len(routes), 3; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	post := routes[0]
+	if post.Method != "POST" || post.Operation != "createPet" || len(post.Consumes) != 2 {
+		t.Errorf("unexpected route %v consumes %v", post, post.Consumes)
+	}
+	get := routes[1]
+	if got, want := len(get.ParameterDocs), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if data := get.ParameterDocs[0].Data(); !data.Required || data.DataFormat != "uuid" {
+		t.Errorf("unexpected parameter %#v", data)
+	}
+	if got, want := routes[2].Operation, "DELETE /pets/{petId}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(routes[2].ParameterDocs), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if data := routes[2].ParameterDocs[0].Data(); data.Description != "the pet to delete" || len(data.DataFormat) > 0 {
+		t.Errorf("unexpected parameter %#v", data)
+	}
+
+	container := restful.NewContainer()
+	container.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/api/v1/pets/p1", nil)
+	httpRequest.Header.Set("Accept", "application/json")
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "pet p1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpRequest, _ = http.NewRequest("DELETE", "/api/v1/pets/p1", nil)
+	httpWriter = httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusNotImplemented; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestRootPathOfSwagger2(t *testing.T) {
+	doc, _ := ParseDocument([]byte(`{"swagger":"2.0","basePath":"/v2/","paths":{}}`))
+	if got, want := doc.RootPath(), "/v2"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if _, err := BuildWebService(doc, HandlerMap{}.Lookup); err == nil {
+		t.Error("error expected for document without paths")
+	}
+}
@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ScaffoldOptions control the Go source that is generated by Scaffold.
+type ScaffoldOptions struct {
+	// Package is the name of the package of the generated file ; default is "api".
+	Package string
+	// Name prefixes the generated Handler interface and WebService constructor ; default is derived from the title.
+	Name string
+	// Source is mentioned in the header of the generated file, e.g. the name of the document file.
+	Source string
+}
+
+// Scaffold returns Go source code, for a spec-first service, that declares:
+//
+//	type {Name}Handler interface // a method for each operation of the document
+//	func New{Name}WebService(handler {Name}Handler) *restful.WebService
+//
+// The WebService has the same Routes that BuildWebService creates at runtime, but written out in RouteBuilder
+// calls that are checked by the compiler: an operation added to the document breaks the build until it is implemented.
+// Methods are named after the operationId or, if absent, the HTTP method and path (e.g. DeletePetsPetId).
+func Scaffold(doc *Document, options ScaffoldOptions) ([]byte, error) {
+	if len(doc.Paths) == 0 {
+		return nil, errors.New("openapi: document has no paths")
+	}
+	if len(options.Package) == 0 {
+		options.Package = "api"
+	}
+	if len(options.Name) == 0 {
+		options.Name = goIdentifier(doc.Info.Title)
+	}
+	paths := []string{}
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	type scaffoldOperation struct {
+		method, path, goName string
+		shared               []Parameter
+		operation            *Operation
+	}
+	operations := []scaffoldOperation{}
+	used := map[string]bool{}
+	for _, path := range paths {
+		item := doc.Paths[path]
+		methods, pathOperations := item.Operations()
+		for i, method := range methods {
+			name := pathOperations[i].OperationID
+			if len(name) == 0 {
+				name = strings.ToLower(method) + " " + path
+			}
+			goName := goIdentifier(name)
+			for suffix := 2; used[goName]; suffix++ {
+				goName = goIdentifier(name) + strconv.Itoa(suffix)
+			}
+			used[goName] = true
+			operations = append(operations, scaffoldOperation{method, path, goName, item.Parameters, pathOperations[i]})
+		}
+	}
+
+	var out bytes.Buffer
+	source := ""
+	if len(options.Source) > 0 {
+		source = " from " + options.Source
+	}
+	fmt.Fprintf(&out, "// Code generated by openapi.Scaffold%s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&out, "package %s\n\n", options.Package)
+	fmt.Fprintf(&out, "import \"github.com/emicklei/go-restful\"\n\n")
+
+	fmt.Fprintf(&out, "// %sHandler has a RouteFunction for each operation of %s %s.\n", options.Name, oneLine(doc.Info.Title), doc.Info.Version)
+	fmt.Fprintf(&out, "type %sHandler interface {\n", options.Name)
+	for _, each := range operations {
+		comment := fmt.Sprintf("%s handles %s %s", each.goName, each.method, each.path)
+		if summary := oneLine(each.operation.Summary); len(summary) > 0 {
+			comment += ": " + summary
+		}
+		fmt.Fprintf(&out, "// %s\n", comment)
+		fmt.Fprintf(&out, "%s(req *restful.Request, resp *restful.Response)\n", each.goName)
+	}
+	fmt.Fprintf(&out, "}\n\n")
+
+	fmt.Fprintf(&out, "// New%sWebService returns a WebService with a Route for each operation, bound to the handler.\n", options.Name)
+	fmt.Fprintf(&out, "func New%sWebService(handler %sHandler) *restful.WebService {\n", options.Name, options.Name)
+	fmt.Fprintf(&out, "ws := new(restful.WebService)\n")
+	fmt.Fprintf(&out, "ws.Path(%q).Doc(%q).ApiVersion(%q)\n", doc.RootPath(), doc.Info.Title, doc.Info.Version)
+	for _, each := range operations {
+		writeRouteBuilder(&out, doc, each.method, each.path, each.goName, each.shared, each.operation)
+	}
+	fmt.Fprintf(&out, "return ws\n}\n")
+	return format.Source(out.Bytes())
+}
+
+// writeRouteBuilder writes the Route registration of an operation as BuildWebService would create it.
+func writeRouteBuilder(out *bytes.Buffer, doc *Document, method, path, goName string, shared []Parameter, operation *Operation) {
+	switch method {
+	case "GET", "POST", "PUT", "PATCH", "DELETE", "HEAD":
+		fmt.Fprintf(out, "ws.Route(ws.%s(%q).To(handler.%s).\n", method, path, goName)
+	default:
+		fmt.Fprintf(out, "ws.Route(ws.Method(%q).Path(%q).To(handler.%s).\n", method, path, goName)
+	}
+	fmt.Fprintf(out, "Operation(%q)", OperationKey(method, path, operation))
+	if len(operation.Summary) > 0 {
+		fmt.Fprintf(out, ".\nDoc(%q)", operation.Summary)
+	}
+	if len(operation.Description) > 0 {
+		fmt.Fprintf(out, ".\nNotes(%q)", operation.Description)
+	}
+	if consumes := consumedMediaTypes(doc, operation); len(consumes) > 0 {
+		fmt.Fprintf(out, ".\nConsumes(%s)", quotedList(consumes))
+	}
+	if produces := producedMediaTypes(doc, operation); len(produces) > 0 {
+		fmt.Fprintf(out, ".\nProduces(%s)", quotedList(produces))
+	}
+	for _, each := range append(append([]Parameter{}, shared...), operation.Parameters...) {
+		constructor := map[string]string{
+			"path":     "PathParameter",
+			"query":    "QueryParameter",
+			"header":   "HeaderParameter",
+			"formData": "FormParameter",
+			"body":     "BodyParameter",
+		}[each.In]
+		if len(constructor) == 0 {
+			continue // same as asRestfulParameter
+		}
+		fmt.Fprintf(out, ".\nParam(ws.%s(%q, %q)", constructor, each.Name, each.Description)
+		dataType, dataFormat := each.DataTypeAndFormat()
+		if len(dataType) > 0 {
+			fmt.Fprintf(out, ".DataType(%q)", dataType)
+		}
+		if len(dataFormat) > 0 {
+			fmt.Fprintf(out, ".DataFormat(%q)", dataFormat)
+		}
+		fmt.Fprintf(out, ".Required(%v))", each.Required || each.In == "path")
+	}
+	codes := []string{}
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil {
+			fmt.Fprintf(out, ".\nReturns(%d, %q, nil)", status, operation.Responses[code].Description)
+		}
+	}
+	fmt.Fprintf(out, ")\n")
+}
+
+// goIdentifier returns an exported Go identifier for a text, e.g. "getPet" -> "GetPet", "get /pets/{id}" -> "GetPetsId".
+func goIdentifier(text string) string {
+	var identifier strings.Builder
+	upper := true
+	for _, each := range text {
+		if !unicode.IsLetter(each) && !unicode.IsDigit(each) {
+			upper = true
+			continue
+		}
+		if identifier.Len() == 0 && unicode.IsDigit(each) {
+			identifier.WriteRune('N')
+		}
+		if upper {
+			each = unicode.ToUpper(each)
+			upper = false
+		}
+		identifier.WriteRune(each)
+	}
+	if identifier.Len() == 0 {
+		return "API"
+	}
+	return identifier.String()
+}
+
+func quotedList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, each := range values {
+		quoted[i] = strconv.Quote(each)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,63 @@
+package openapi
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestScaffold ...openapi
+func TestScaffold(t *testing.T) {
+	doc, err := ParseDocument([]byte(petstore))
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, err := Scaffold(doc, ScaffoldOptions{Package: "pets", Source: "petstore.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "pets.go", source, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, source)
+	}
+	if got, want := file.Name.Name, "pets"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	for _, want := range []string{
+		"// Code generated by openapi.Scaffold from petstore.json; DO NOT EDIT.",
+		"type PetstoreHandler interface {",
+		"// GetPet handles GET /pets/{petId}: get a pet",
+		"GetPet(req *restful.Request, resp *restful.Response)",
+		"DeletePetsPetId(req *restful.Request, resp *restful.Response)",
+		"func NewPetstoreWebService(handler PetstoreHandler) *restful.WebService {",
+		`ws.Path("/api/v1").Doc("Petstore").ApiVersion("1.0")`,
+		`ws.Route(ws.GET("/pets/{petId}").To(handler.GetPet).`,
+		`Operation("getPet").`,
+		`Param(ws.PathParameter("petId", "").DataType("string").DataFormat("uuid").Required(true)).`,
+		`Param(ws.QueryParameter("fields", "").DataType("string").Required(false)).`,
+		`Returns(404, "not found", nil))`,
+		`Operation("DELETE /pets/{petId}").`,
+		`Consumes("application/json", "application/xml").`,
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("missing %q in\n%s", want, source)
+		}
+	}
+}
+
+// go test -v -test.run TestGoIdentifier ...openapi
+func TestGoIdentifier(t *testing.T) {
+	for text, want := range map[string]string{
+		"getPet":              "GetPet",
+		"get /pets/{pet_id}":  "GetPetsPetId",
+		"list-all pets":       "ListAllPets",
+		"2fa":                 "N2fa",
+		"":                    "API",
+		"Swagger Petstore v2": "SwaggerPetstoreV2",
+	} {
+		if got := goIdentifier(text); got != want {
+			t.Errorf("%q: got %v want %v", text, got, want)
+		}
+	}
+}
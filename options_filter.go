@@ -1,6 +1,9 @@
 package restful
 
-import "strings"
+import (
+	"net/http"
+	"strings"
+)
 
 // Copyright 2013 Ernest Micklei. All rights reserved.
 // Use of this source code is governed by a license
@@ -24,3 +27,47 @@ func (c *Container) OPTIONSFilter(req *Request, resp *Response, chain *FilterCha
 func OPTIONSFilter() FilterFunction {
 	return DefaultContainer.OPTIONSFilter
 }
+
+// ResourceDescription is the body of the response of the OPTIONSDiscoveryFilter: the operations
+// that are available for the path of a resource.
+type ResourceDescription struct {
+	Path       string             `json:"path"`
+	Operations []RouteDescription `json:"operations"`
+}
+
+// OPTIONSDiscoveryFilter is a filter function that, like OPTIONSFilter, answers OPTIONS requests with the
+// allowed methods and, in addition, writes a JSON ResourceDescription of the Routes for the request URL Path:
+// their methods, parameters and media types. It is a lightweight alternative to a Swagger document for clients
+// that discover a single resource. Routes with KeyExcludeFromDocumentation metadata are left out of the body.
+//
+//	container.Filter(container.OPTIONSDiscoveryFilter)
+func (c *Container) OPTIONSDiscoveryFilter(req *Request, resp *Response, chain *FilterChain) {
+	if "OPTIONS" != req.Request.Method {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	routes := c.routesForPath(req.Request.URL.Path)
+	if len(routes) == 0 {
+		resp.WriteProblem(NewProblemDocument(http.StatusNotFound, "no operations for this path"))
+		return
+	}
+	methods := []string{}
+	description := ResourceDescription{Path: req.Request.URL.Path, Operations: []RouteDescription{}}
+	for _, each := range routes {
+		methods = append(methods, each.Method)
+		if each.ExcludedFromDocumentation() {
+			continue
+		}
+		operation := describeRoute(each)
+		operation.Filters = nil // implementation detail
+		description.Operations = append(description.Operations, operation)
+	}
+	resp.AddHeader(HEADER_Allow, strings.Join(methods, ","))
+	resp.WriteHeaderAndJson(http.StatusOK, description, MIME_JSON)
+}
+
+// OPTIONSDiscoveryFilter is a filter function that answers OPTIONS requests with the allowed methods
+// and a JSON description of the Routes for the request URL Path, using the DefaultContainer.
+func OPTIONSDiscoveryFilter() FilterFunction {
+	return DefaultContainer.OPTIONSDiscoveryFilter
+}
@@ -1,8 +1,10 @@
 package restful
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 )
 
@@ -32,3 +34,53 @@ func TestOptionsFilter(t *testing.T) {
 		t.Fatal("expected: POST but got:" + actual)
 	}
 }
+
+// go test -v -test.run TestOptionsDiscoveryFilter ...restful
+func TestOptionsDiscoveryFilter(t *testing.T) {
+	container := NewContainer()
+	container.Filter(container.OPTIONSDiscoveryFilter)
+	ws := new(WebService).Path("/candy").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{kind}").To(dummy).Operation("getCandy").
+		Param(ws.PathParameter("kind", "kind of candy")).
+		Param(ws.QueryParameter("size", "size of the bag").DataType("integer")))
+	ws.Route(ws.PUT("/{kind}").To(dummy).Consumes(MIME_JSON))
+	ws.Route(ws.DELETE("/{kind}").To(dummy).Metadata(KeyExcludeFromDocumentation, true))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("OPTIONS", "http://here.io/candy/gum", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_Allow), "GET,PUT,DELETE"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	var description ResourceDescription
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &description); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(description.Operations), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	get := description.Operations[0]
+	if got, want := get.Operation, "getCandy"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(get.Parameters), 2; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := description.Operations[1].Consumes, []string{MIME_JSON}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if len(get.Filters) > 0 {
+		t.Errorf("got filters %v want none", get.Filters)
+	}
+
+	httpRequest, _ = http.NewRequest("OPTIONS", "http://here.io/cookies", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
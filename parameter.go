@@ -36,6 +36,7 @@ type ParameterData struct {
 	AllowableValues                         map[string]string
 	AllowMultiple                           bool
 	DefaultValue                            string
+	identifier                              bool // if true then DataType and DataFormat are set by the Container
 }
 
 // Data returns the state of the Parameter
@@ -112,3 +113,10 @@ func (p *Parameter) Description(doc string) *Parameter {
 	p.data.Description = doc
 	return p
 }
+
+// Identifier marks the parameter as an identifier ; its DataType and DataFormat
+// are set according to the IdentifierFormat of the Container when the WebService is added.
+func (p *Parameter) Identifier() *Parameter {
+	p.data.identifier = true
+	return p
+}
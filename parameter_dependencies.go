@@ -0,0 +1,83 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strings"
+)
+
+const (
+	// MutuallyExclusive is the kind of ParameterDependency that allows at most one of its parameters.
+	MutuallyExclusive = "mutuallyExclusive"
+	// RequiredTogether is the kind of ParameterDependency that requires all or none of its parameters.
+	RequiredTogether = "requiredTogether"
+)
+
+// ParameterDependency is a relationship between query or header parameters of a Route.
+// OpenAPI cannot express these ; they are rendered as text in the notes of an operation.
+// A request that violates a dependency gets a 400 (Bad Request) before its RouteFunction is called.
+type ParameterDependency struct {
+	Kind  string   `json:"kind"`
+	Names []string `json:"parameters"`
+}
+
+// Description returns an explanation of the dependency for documentation.
+func (d ParameterDependency) Description() string {
+	names := strings.Join(d.Names, ", ")
+	if d.Kind == MutuallyExclusive {
+		return "Parameters " + names + " are mutually exclusive."
+	}
+	return "Parameters " + names + " must be given together."
+}
+
+// MutuallyExclusive declares that a request may have at most one of the named parameters.
+func (b *RouteBuilder) MutuallyExclusive(names ...string) *RouteBuilder {
+	b.parameterDependencies = append(b.parameterDependencies, ParameterDependency{Kind: MutuallyExclusive, Names: names})
+	return b
+}
+
+// RequiredTogether declares that a request must have either all or none of the named parameters.
+func (b *RouteBuilder) RequiredTogether(names ...string) *RouteBuilder {
+	b.parameterDependencies = append(b.parameterDependencies, ParameterDependency{Kind: RequiredTogether, Names: names})
+	return b
+}
+
+// violatedParameterDependency returns a ServiceError if the request violates a ParameterDependency of the Route.
+// Parameters documented as header parameters are looked up in the header, others in the query.
+func (r *Route) violatedParameterDependency(req *Request) (ServiceError, bool) {
+	if len(r.ParameterDependencies) == 0 {
+		return ServiceError{}, false
+	}
+	query := req.Request.URL.Query()
+	for _, each := range r.ParameterDependencies {
+		present := []string{}
+		for _, name := range each.Names {
+			if r.isHeaderParameter(name) {
+				if len(req.Request.Header.Get(name)) > 0 {
+					present = append(present, name)
+				}
+			} else if _, ok := query[name]; ok {
+				present = append(present, name)
+			}
+		}
+		switch {
+		case each.Kind == MutuallyExclusive && len(present) > 1:
+			return NewError(http.StatusBadRequest, "parameters "+strings.Join(present, ", ")+" are mutually exclusive"), true
+		case each.Kind == RequiredTogether && len(present) > 0 && len(present) < len(each.Names):
+			return NewError(http.StatusBadRequest, each.Description()), true
+		}
+	}
+	return ServiceError{}, false
+}
+
+func (r *Route) isHeaderParameter(name string) bool {
+	for _, each := range r.ParameterDocs {
+		if each.Kind() == HeaderParameterKind && strings.EqualFold(each.Data().Name, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestParameterDependencies ...restful
+func TestParameterDependencies(t *testing.T) {
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("").To(dummy).
+		Param(ws.HeaderParameter("X-Cursor", "continuation")).
+		MutuallyExclusive("page", "X-Cursor").
+		RequiredTogether("from", "to"))
+	c := NewContainer()
+	c.Add(ws)
+	for _, each := range []struct {
+		query  string
+		cursor string
+		want   int
+	}{
+		{"", "", http.StatusOK},
+		{"?page=1", "", http.StatusOK},
+		{"?page=1", "abc", http.StatusBadRequest},
+		{"?from=1&to=2", "abc", http.StatusOK},
+		{"?from=1", "", http.StatusBadRequest},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "/orders"+each.query, nil)
+		if len(each.cursor) > 0 {
+			httpRequest.Header.Set("X-Cursor", each.cursor)
+		}
+		recorder := httptest.NewRecorder()
+		c.ServeHTTP(recorder, httpRequest)
+		if got := recorder.Code; got != each.want {
+			t.Errorf("%s (%s): got %v want %v", each.query, each.cursor, got, each.want)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// MIME_PROBLEM_JSON is the media type of a ProblemDocument
+const MIME_PROBLEM_JSON = "application/problem+json"
+
+// ProblemDocument is a machine-readable description of an error as specified by RFC 7807.
+type ProblemDocument struct {
+	Type     string `json:"type,omitempty"` // URI that identifies the problem type ; defaults to about:blank
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Diagnostics is an extension member that is only written if the Response has VerboseErrors.
+	Diagnostics map[string]interface{} `json:"diagnostics,omitempty"`
+	// Errors is an extension member that lists invalid fields or parameters, see WriteValidationError.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// NewProblemDocument returns a ProblemDocument with the status text as title.
+func NewProblemDocument(status int, detail string) ProblemDocument {
+	return ProblemDocument{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// WriteProblem writes the status and the ProblemDocument as JSON, using the problem media type.
+// For a 5xx status, the Detail is omitted if the Response must be terse (see Container.ErrorVerbosity).
+func (r *Response) WriteProblem(problem ProblemDocument) error {
+	if !r.VerboseErrors() {
+		problem.Diagnostics = nil
+	}
+	if r.terseError(problem.Status) {
+		problem.Detail = ""
+	}
+	output, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	r.err = NewError(problem.Status, problem.Detail)
+	r.Header().Set(HEADER_ContentType, MIME_PROBLEM_JSON)
+	r.WriteHeader(problem.Status)
+	_, err = r.Write(output)
+	return err
+}
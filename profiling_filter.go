@@ -0,0 +1,58 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"math/rand"
+	"runtime/pprof"
+	runtimetrace "runtime/trace"
+)
+
+// ProfileSampling is used to create a Filter that, for a sampled subset of requests, runs the rest
+// of the chain with pprof labels ("route" and "operation") and inside a runtime/trace task and region.
+// CPU profiles taken by continuous profilers can then be broken down per Route ;
+// execution traces (see runtime/trace) show the requests as tasks.
+//
+//	sampling := restful.ProfileSampling{Rate: 0.01}
+//	restful.Filter(sampling.Filter)
+type ProfileSampling struct {
+	// Rate is the fraction (0..1) of requests that is sampled. Ignored if Sample is set.
+	Rate float64
+	// Sample, if set, decides whether a request is sampled.
+	Sample func(req *Request) bool
+	// Labels, if set, returns additional pprof label key-value pairs for a sampled request.
+	Labels func(req *Request) []string
+}
+
+// Filter runs the rest of the chain with profiling labels if the request is sampled.
+// The next filters get a Request whose context carries the labels and the trace task.
+func (p ProfileSampling) Filter(req *Request, resp *Response, chain *FilterChain) {
+	if !p.sampled(req) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	routePath, operation := req.SelectedRoutePath(), ""
+	if route := req.SelectedRoute(); route != nil {
+		operation = route.Operation
+	}
+	labels := []string{"route", routePath, "operation", operation}
+	if p.Labels != nil {
+		labels = append(labels, p.Labels(req)...)
+	}
+	pprof.Do(req.Context(), pprof.Labels(labels...), func(ctx context.Context) {
+		ctx, task := runtimetrace.NewTask(ctx, req.Request.Method+" "+routePath)
+		defer task.End()
+		defer runtimetrace.StartRegion(ctx, "restful.FilterChain").End()
+		chain.ProcessFilter(req.WithContext(ctx), resp)
+	})
+}
+
+func (p ProfileSampling) sampled(req *Request) bool {
+	if p.Sample != nil {
+		return p.Sample(req)
+	}
+	return p.Rate > 0 && rand.Float64() < p.Rate
+}
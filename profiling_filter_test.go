@@ -0,0 +1,55 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+)
+
+// go test -v -test.run TestProfileSampling ...restful
+func TestProfileSampling(t *testing.T) {
+	var route, operation, tenant string
+	sampling := ProfileSampling{
+		Rate:   1,
+		Labels: func(req *Request) []string { return []string{"tenant", "acme"} },
+	}
+	container := NewContainer()
+	container.Filter(sampling.Filter)
+	ws := new(WebService).Path("/food")
+	ws.Route(ws.GET("/{kind}").Operation("getFood").To(func(req *Request, resp *Response) {
+		ctx := req.Request.Context()
+		route, _ = pprof.Label(ctx, "route")
+		operation, _ = pprof.Label(ctx, "operation")
+		tenant, _ = pprof.Label(ctx, "tenant")
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/food/apple", nil)
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if got, want := route, "/food/{kind}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := operation, "getFood"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := tenant, "acme"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestProfileSampling_NotSampled(t *testing.T) {
+	sampling := ProfileSampling{Sample: func(req *Request) bool { return false }}
+	req := NewRequest(&http.Request{})
+	called := false
+	chain := FilterChain{Target: func(req *Request, resp *Response) {
+		called = true
+		if _, ok := pprof.Label(req.Request.Context(), "route"); ok {
+			t.Error("unexpected label")
+		}
+	}}
+	sampling.Filter(req, nil, &chain)
+	if !called {
+		t.Error("expected target to be called")
+	}
+}
@@ -0,0 +1,197 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyRateLimit is the Route metadata key for a RateLimitPolicy that overrides the policy of the RateLimiter for that Route.
+// Requests for such a Route are counted separately from those of other Routes.
+//
+//	ws.Route(ws.POST("/login").To(login).Metadata(restful.KeyRateLimit, restful.RateLimitPolicy{RequestsPerSecond: 1, Burst: 5}))
+const KeyRateLimit = "restful.rateLimit"
+
+// RateLimitPolicy describes a token bucket.
+type RateLimitPolicy struct {
+	RequestsPerSecond float64 // rate at which tokens are added
+	Burst             int     // maximum number of tokens
+	// Key returns the identity of the client ; requests with the same key share a bucket.
+	// If nil, the RateLimiter Key is used.
+	Key func(req *Request) string
+}
+
+// RateLimitStore keeps the token buckets. Allow must take a token atomically such that concurrent requests
+// of a client cannot exceed the burst. See Stores in the package documentation.
+type RateLimitStore interface {
+	// Allow takes a token from the bucket for key. If none is available then it returns
+	// false and the duration after which a token will be available.
+	Allow(key string, policy RateLimitPolicy, now time.Time) (allowed bool, retryAfter time.Duration)
+}
+
+// RateLimitStatus is the state of a token bucket after a request has been counted.
+type RateLimitStatus struct {
+	Limit     int           // Burst of the policy
+	Remaining int           // number of tokens left
+	Reset     time.Duration // until the bucket is full again
+}
+
+// RateLimitStatusStore is implemented by a RateLimitStore that can report the state of a bucket.
+// The RateLimiter then sets the RateLimitStatusKey attribute, which a RetryAdvisor writes as RateLimit headers.
+type RateLimitStatusStore interface {
+	Status(key string, policy RateLimitPolicy, now time.Time) RateLimitStatus
+}
+
+// RateLimitStatusKey is the attribute key of the RateLimitStatus of a request that has passed a RateLimiter.
+var RateLimitStatusKey = NewAttributeKey[RateLimitStatus]("restful.rateLimitStatus")
+
+// RateLimiter is used to create a Filter that limits the rate of requests per client.
+// Rejected requests get a 429 (Too Many Requests) with a Retry-After header.
+//
+//	limiter := restful.NewRateLimiter(restful.RateLimitPolicy{RequestsPerSecond: 10, Burst: 20})
+//	restful.Filter(limiter.Filter)
+type RateLimiter struct {
+	Policy RateLimitPolicy // default policy for Routes without KeyRateLimit metadata
+	Store  RateLimitStore
+	Key    func(req *Request) string // default is RemoteAddrKey
+}
+
+// NewRateLimiter returns a RateLimiter for the policy using an in-memory store.
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
+	return &RateLimiter{Policy: policy, Store: NewMemoryRateLimitStore(), Key: RemoteAddrKey}
+}
+
+// Filter rejects the request if its client has exceeded the rate of the applicable policy.
+func (l *RateLimiter) Filter(req *Request, resp *Response, chain *FilterChain) {
+	policy, prefix := l.Policy, ""
+	if route := req.SelectedRoute(); route != nil {
+		if override, ok := route.Metadata[KeyRateLimit].(RateLimitPolicy); ok {
+			policy, prefix = override, routeVarsKey(route)+"|"
+		}
+	}
+	keyFunc := policy.Key
+	if keyFunc == nil {
+		keyFunc = l.Key
+	}
+	if keyFunc == nil {
+		keyFunc = RemoteAddrKey
+	}
+	key, now := prefix+keyFunc(req), time.Now()
+	allowed, retryAfter := l.Store.Allow(key, policy, now)
+	if reporter, ok := l.Store.(RateLimitStatusStore); ok {
+		SetAttr(req, RateLimitStatusKey, reporter.Status(key, policy, now))
+	}
+	if !allowed {
+		resp.Header().Set(HEADER_RetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		resp.WriteProblem(NewProblemDocument(http.StatusTooManyRequests, fmt.Sprintf("rate limit of %v requests per second exceeded", policy.RequestsPerSecond)))
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// RemoteAddrKey returns the host of the remote address of the request.
+func RemoteAddrKey(req *Request) string {
+	host, _, err := net.SplitHostPort(req.Request.RemoteAddr)
+	if err != nil {
+		return req.Request.RemoteAddr
+	}
+	return host
+}
+
+// HeaderKey returns a key function that uses the value of a request header, e.g. an API key.
+func HeaderKey(name string) func(req *Request) string {
+	return func(req *Request) string {
+		return req.Request.Header.Get(name)
+	}
+}
+
+// memoryRateLimitStore is a RateLimitStore that keeps buckets in memory.
+type memoryRateLimitStore struct {
+	lock      sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // time at which the bucket is full again
+}
+
+// NewMemoryRateLimitStore returns a RateLimitStore for a single process.
+// Buckets that have been refilled are removed periodically.
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{buckets: map[string]*tokenBucket{}}
+}
+
+// Allow is part of RateLimitStore
+func (s *memoryRateLimitStore) Allow(key string, policy RateLimitPolicy, now time.Time) (bool, time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sweep(now)
+	burst := float64(policy.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		s.buckets[key] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*policy.RequestsPerSecond)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		if policy.RequestsPerSecond <= 0 {
+			return false, time.Hour
+		}
+		return false, time.Duration((1 - bucket.tokens) / policy.RequestsPerSecond * float64(time.Second))
+	}
+	bucket.tokens--
+	if policy.RequestsPerSecond > 0 {
+		bucket.full = now.Add(time.Duration((burst - bucket.tokens) / policy.RequestsPerSecond * float64(time.Second)))
+	} else {
+		bucket.full = now.Add(time.Hour)
+	}
+	return true, 0
+}
+
+// Status is part of RateLimitStatusStore
+func (s *memoryRateLimitStore) Status(key string, policy RateLimitPolicy, now time.Time) RateLimitStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	status := RateLimitStatus{Limit: policy.Burst, Remaining: policy.Burst}
+	if status.Limit < 1 {
+		status.Limit, status.Remaining = 1, 1
+	}
+	bucket, ok := s.buckets[key]
+	if !ok {
+		return status
+	}
+	tokens := math.Min(float64(status.Limit), bucket.tokens+now.Sub(bucket.last).Seconds()*policy.RequestsPerSecond)
+	status.Remaining = int(math.Floor(tokens))
+	if reset := bucket.full.Sub(now); reset > 0 {
+		status.Reset = reset
+	}
+	return status
+}
+
+// sweep removes the buckets that are full, at most once a minute.
+func (s *memoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, bucket := range s.buckets {
+		if now.After(bucket.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
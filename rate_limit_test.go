@@ -0,0 +1,63 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestRateLimiter ...restful
+func TestRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitPolicy{RequestsPerSecond: 0.001, Burst: 2})
+	container := NewContainer()
+	container.Filter(limiter.Filter)
+	ws := new(WebService).Path("/limited")
+	ws.Route(ws.GET("/default").To(dummy))
+	ws.Route(ws.GET("/strict").To(dummy).Metadata(KeyRateLimit, RateLimitPolicy{RequestsPerSecond: 0.001, Burst: 1}))
+	container.Add(ws)
+
+	codes := []int{}
+	for _, each := range []string{"/default", "/default", "/default", "/strict", "/strict"} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/limited"+each, nil)
+		httpRequest.RemoteAddr = "10.0.0.1:1234"
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		codes = append(codes, httpWriter.Code)
+		if httpWriter.Code == http.StatusTooManyRequests && httpWriter.Header().Get(HEADER_RetryAfter) == "" {
+			t.Error("missing Retry-After")
+		}
+	}
+	want := []int{200, 200, 429, 200, 429}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Errorf("request %d: got %v want %v", i, codes[i], want[i])
+		}
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	policy := RateLimitPolicy{RequestsPerSecond: 10, Burst: 1}
+	now := time.Now()
+	if ok, _ := store.Allow("a", policy, now); !ok {
+		t.Fatal("expected first request to be allowed")
+	}
+	ok, retryAfter := store.Allow("a", policy, now)
+	if ok {
+		t.Fatal("expected second request to be rejected")
+	}
+	if got, want := retryAfter, 100*time.Millisecond; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if ok, _ := store.Allow("a", policy, now.Add(retryAfter)); !ok {
+		t.Error("expected request to be allowed after refill")
+	}
+	if ok, _ := store.Allow("b", policy, now); !ok {
+		t.Error("expected other key to be allowed")
+	}
+	store.Allow("c", policy, now.Add(2*time.Minute))
+	if got, want := len(store.(*memoryRateLimitStore).buckets), 1; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
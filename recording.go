@@ -0,0 +1,264 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// KeyExcludeFromRecording is the Route metadata key for a bool that, if true, stops a RequestRecorder
+// from recording requests for that Route, e.g. requests that carry secrets in their body.
+const KeyExcludeFromRecording = "restful.excludeFromRecording"
+
+// Recording is a captured request and its response.
+type Recording struct {
+	ID             uint64        `json:"id"`
+	Time           time.Time     `json:"time"`
+	Method         string        `json:"method"`
+	URL            string        `json:"url"`
+	RoutePath      string        `json:"routePath"`
+	Operation      string        `json:"operation,omitempty"`
+	RemoteAddr     string        `json:"remoteAddr"`
+	RequestHeader  http.Header   `json:"requestHeader"`
+	RequestBody    string        `json:"requestBody,omitempty"` // the part of the body read by the handler
+	Status         int           `json:"status"`
+	ResponseHeader http.Header   `json:"responseHeader"`
+	ResponseBody   string        `json:"responseBody,omitempty"` // before content encoding
+	Truncated      bool          `json:"truncated,omitempty"`    // true if a body was longer than MaxBodyBytes
+	Latency        time.Duration `json:"latency"`
+}
+
+// RequestRecorder is used to create a Filter that captures requests of matched Routes and their responses
+// into a ring buffer, to troubleshoot a running service without redeploying it with extra logging.
+// Recording is either enabled for all requests it processes, using Enable, or for requests that
+// have the TriggerHeader. Add the filter to a Container to record all its Routes, or to a WebService or Route.
+// The recordings can be inspected using the WebService of the recorder, which must be protected by
+// an authentication filter.
+//
+//	recorder := restful.NewRequestRecorder(100)
+//	recorder.TriggerHeader, recorder.TriggerToken = "X-Debug-Record", os.Getenv("DEBUG_RECORD_TOKEN")
+//	restful.Filter(recorder.Filter)
+//	restful.Add(recorder.WebService("/debug/recordings").Filter(adminOnly))
+type RequestRecorder struct {
+	// MaxBodyBytes limits the number of bytes kept of each body ; default is 64 KiB.
+	MaxBodyBytes int
+	// TriggerHeader is the name of the request header that enables recording of that request.
+	// If empty, only Enable controls the recording.
+	TriggerHeader string
+	// TriggerToken is the value the TriggerHeader must have. If empty, any value triggers recording.
+	TriggerToken string
+	// RedactHeaders lists the names of the headers whose values are not recorded.
+	// Default are Authorization, Proxy-Authorization, Cookie and Set-Cookie ; the TriggerHeader is always redacted.
+	RedactHeaders []string
+
+	lock       sync.Mutex
+	enabled    bool
+	recordings []Recording // ring buffer
+	next       int         // index in recordings for the next Recording, once it is full
+	lastID     uint64
+}
+
+// NewRequestRecorder returns a RequestRecorder that keeps the last size recordings.
+func NewRequestRecorder(size int) *RequestRecorder {
+	if size < 1 {
+		size = 1
+	}
+	return &RequestRecorder{
+		MaxBodyBytes:  64 * 1024,
+		RedactHeaders: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"},
+		recordings:    make([]Recording, 0, size),
+	}
+}
+
+// Enable turns recording of all requests on or off.
+func (r *RequestRecorder) Enable(on bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.enabled = on
+}
+
+// Enabled returns whether all requests are recorded.
+func (r *RequestRecorder) Enabled() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.enabled
+}
+
+// Recordings returns a copy of the recordings, oldest first.
+func (r *RequestRecorder) Recordings() []Recording {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	list := make([]Recording, 0, len(r.recordings))
+	list = append(list, r.recordings[r.next:]...)
+	return append(list, r.recordings[:r.next]...)
+}
+
+// Clear removes all recordings.
+func (r *RequestRecorder) Clear() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.recordings = r.recordings[:0]
+	r.next = 0
+}
+
+// Filter records the request and its response if recording is enabled or triggered.
+func (r *RequestRecorder) Filter(req *Request, resp *Response, chain *FilterChain) {
+	route := req.SelectedRoute()
+	if route == nil || !r.selects(req) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if exclude, _ := route.Metadata[KeyExcludeFromRecording].(bool); exclude {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	start := time.Now()
+	recording := Recording{
+		Time:          start,
+		Method:        req.Request.Method,
+		URL:           req.Request.URL.String(),
+		RoutePath:     req.SelectedRoutePath(),
+		Operation:     route.Operation,
+		RemoteAddr:    req.Request.RemoteAddr,
+		RequestHeader: r.redacted(req.Request.Header),
+	}
+	requestBody := &limitedBuffer{max: r.MaxBodyBytes}
+	if body := req.Request.Body; body != nil {
+		req.Request.Body = teeReadCloser{Reader: io.TeeReader(body, requestBody), Closer: body}
+		defer func() { req.Request.Body = body }()
+	}
+	responseBody := &limitedBuffer{max: r.MaxBodyBytes}
+	previous := resp.bodyRecorder
+	if previous != nil { // nested recorders
+		resp.bodyRecorder = io.MultiWriter(previous, responseBody)
+	} else {
+		resp.bodyRecorder = responseBody
+	}
+	defer func() { resp.bodyRecorder = previous }()
+
+	chain.ProcessFilter(req, resp)
+
+	recording.Status = resp.StatusCode()
+	recording.ResponseHeader = r.redacted(resp.Header())
+	recording.RequestBody = requestBody.String()
+	recording.ResponseBody = responseBody.String()
+	recording.Truncated = requestBody.truncated || responseBody.truncated
+	recording.Latency = time.Since(start)
+	r.add(recording)
+}
+
+// selects returns whether the request must be recorded.
+func (r *RequestRecorder) selects(req *Request) bool {
+	if r.Enabled() {
+		return true
+	}
+	if len(r.TriggerHeader) == 0 {
+		return false
+	}
+	value := req.Request.Header.Get(r.TriggerHeader)
+	if len(value) == 0 {
+		return false
+	}
+	return len(r.TriggerToken) == 0 || subtle.ConstantTimeCompare([]byte(value), []byte(r.TriggerToken)) == 1
+}
+
+// redacted returns a copy of the header without the values of the RedactHeaders and the TriggerHeader.
+func (r *RequestRecorder) redacted(header http.Header) http.Header {
+	clone := header.Clone()
+	redact := func(name string) {
+		if _, ok := clone[http.CanonicalHeaderKey(name)]; ok {
+			clone.Set(name, "[REDACTED]")
+		}
+	}
+	for _, each := range r.RedactHeaders {
+		redact(each)
+	}
+	if len(r.TriggerHeader) > 0 {
+		redact(r.TriggerHeader)
+	}
+	return clone
+}
+
+func (r *RequestRecorder) add(recording Recording) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.lastID++
+	recording.ID = r.lastID
+	if cap(r.recordings) == 0 { // not created using NewRequestRecorder
+		r.recordings = make([]Recording, 0, 100)
+	}
+	if len(r.recordings) < cap(r.recordings) {
+		r.recordings = append(r.recordings, recording)
+		return
+	}
+	r.recordings[r.next] = recording
+	r.next = (r.next + 1) % len(r.recordings)
+}
+
+// WebService returns a WebService to inspect the recordings:
+//
+//	GET    {path}      all recordings, oldest first
+//	GET    {path}/{id} one recording
+//	DELETE {path}      removes all recordings
+//
+// Its Routes are excluded from recording and from API documentation.
+func (r *RequestRecorder) WebService(path string) *WebService {
+	ws := new(WebService).Path(path).Produces(MIME_JSON)
+	ws.Metadata(KeyExcludeFromDocumentation, true)
+	ws.Metadata(KeyExcludeFromRecording, true)
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.Header().Set(HEADER_CacheControl, "no-store")
+		resp.WriteEntity(r.Recordings())
+	}).Doc("Lists the recorded requests").Operation("listRecordings"))
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		id, err := strconv.ParseUint(req.PathParameter("id"), 10, 64)
+		if err == nil {
+			for _, each := range r.Recordings() {
+				if each.ID == id {
+					resp.Header().Set(HEADER_CacheControl, "no-store")
+					resp.WriteEntity(each)
+					return
+				}
+			}
+		}
+		resp.WriteErrorString(http.StatusNotFound, "no recording with id "+req.PathParameter("id"))
+	}).Doc("Returns a recorded request").Operation("getRecording"))
+	ws.Route(ws.DELETE("").To(func(req *Request, resp *Response) {
+		r.Clear()
+		resp.WriteHeader(http.StatusNoContent)
+	}).Doc("Removes all recorded requests").Operation("clearRecordings"))
+	return ws
+}
+
+// limitedBuffer keeps the first max bytes written to it.
+type limitedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+// Write always reports that all of p is written, as required by io.TeeReader and io.MultiWriter.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.Len(); len(p) > room {
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
+
+// teeReadCloser closes the original body of a request whose reads are copied.
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
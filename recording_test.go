@@ -0,0 +1,146 @@
+package restful
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newRecordingContainer(recorder *RequestRecorder) *Container {
+	c := NewContainer()
+	c.Filter(recorder.Filter)
+	ws := new(WebService).Path("/echo").Consumes(MIME_JSON).Produces(MIME_JSON)
+	ws.Route(ws.POST("").To(func(req *Request, resp *Response) {
+		data, _ := io.ReadAll(req.Request.Body)
+		resp.Header().Set("Set-Cookie", "session=secret")
+		resp.WriteHeader(http.StatusCreated)
+		resp.Write(data)
+	}).Operation("echo"))
+	c.Add(ws)
+	c.Add(recorder.WebService("/debug/recordings"))
+	return c
+}
+
+func postEcho(c *Container, body string, header http.Header) {
+	httpRequest, _ := http.NewRequest("POST", "/echo", strings.NewReader(body))
+	httpRequest.Header = header
+	httpRequest.Header.Set("Content-Type", MIME_JSON)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+}
+
+// go test -v -test.run TestRequestRecorderEnabled ...restful
+func TestRequestRecorderEnabled(t *testing.T) {
+	recorder := NewRequestRecorder(2)
+	recorder.MaxBodyBytes = 8
+	c := newRecordingContainer(recorder)
+	postEcho(c, `{"a":1}`, http.Header{})
+	if got, want := len(recorder.Recordings()), 0; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	recorder.Enable(true)
+	postEcho(c, `{"a":1}`, http.Header{"Authorization": {"Bearer token"}})
+	list := recorder.Recordings()
+	if got, want := len(list), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	each := list[0]
+	if got, want := each.RoutePath, "/echo/"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.Operation, "echo"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.Status, 201; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.RequestBody, `{"a":1}`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.ResponseBody, `{"a":1}`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.RequestHeader.Get("Authorization"), "[REDACTED]"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := each.ResponseHeader.Get("Set-Cookie"), "[REDACTED]"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if each.Truncated {
+		t.Error("got truncated")
+	}
+
+	// ring buffer keeps the last 2
+	postEcho(c, `{"b":22}`, http.Header{})
+	postEcho(c, `{"c":333}`, http.Header{})
+	list = recorder.Recordings()
+	if got, want := len(list), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := list[0].ID, uint64(2); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := list[1].RequestBody, `{"c":333`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if !list[1].Truncated {
+		t.Error("got not truncated")
+	}
+}
+
+// go test -v -test.run TestRequestRecorderTriggerHeader ...restful
+func TestRequestRecorderTriggerHeader(t *testing.T) {
+	recorder := NewRequestRecorder(10)
+	recorder.TriggerHeader, recorder.TriggerToken = "X-Debug-Record", "s3cret"
+	c := newRecordingContainer(recorder)
+	postEcho(c, `{}`, http.Header{"X-Debug-Record": {"guess"}})
+	postEcho(c, `{}`, http.Header{"X-Debug-Record": {"s3cret"}})
+	list := recorder.Recordings()
+	if got, want := len(list), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := list[0].RequestHeader.Get("X-Debug-Record"), "[REDACTED]"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestRequestRecorderWebService ...restful
+func TestRequestRecorderWebService(t *testing.T) {
+	recorder := NewRequestRecorder(10)
+	recorder.Enable(true)
+	c := newRecordingContainer(recorder)
+	postEcho(c, `{}`, http.Header{})
+
+	httpRequest, _ := http.NewRequest("GET", "/debug/recordings", nil)
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	var list []Recording
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(list), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "/debug/recordings/1", nil)
+	httpWriter = httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, 200; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	httpRequest, _ = http.NewRequest("GET", "/debug/recordings/9", nil)
+	httpWriter = httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, 404; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	httpRequest, _ = http.NewRequest("DELETE", "/debug/recordings", nil)
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got, want := len(recorder.Recordings()), 0; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/emicklei/go-restful/log"
+)
+
+// PanicRecovery is used to create a Filter that recovers from panics in the next filters and the RouteFunction.
+// Unlike the Container recovery (see RecoverHandler), it has access to the Request and Response
+// and can therefore report and render the failure in context.
+//
+//	recovery := restful.PanicRecovery{OnPanic: reportToSentry}
+//	restful.Filter(recovery.Filter)
+type PanicRecovery struct {
+	// Logger is used to log the reason and the stack. If nil, the package logger is used.
+	Logger log.StdLogger
+	// OnPanic is called, if set, with the reason and stack ; e.g. for reporting to an error tracking service.
+	OnPanic func(reason interface{}, stack []byte, req *Request)
+	// WriteResponse is called to write the response. If nil, a 500 ProblemDocument is written
+	// with a generic detail ; it includes the reason, stack and request attributes only if the Response has VerboseErrors.
+	// It is not called if the response header was already written.
+	WriteResponse func(reason interface{}, req *Request, resp *Response)
+}
+
+// Filter recovers from a panic in the rest of the chain.
+// A panic with http.ErrAbortHandler is not recovered because it is meant to abort the connection.
+func (p PanicRecovery) Filter(req *Request, resp *Response, chain *FilterChain) {
+	defer func() {
+		reason := recover()
+		if reason == nil {
+			return
+		}
+		if reason == http.ErrAbortHandler {
+			panic(reason)
+		}
+		stack := debug.Stack()
+		if recovered, ok := reason.(*RecoveredPanic); ok {
+			reason, stack = recovered.Reason, recovered.Stack
+		}
+		logger := p.Logger
+		if logger == nil {
+			logger = log.Logger
+		}
+		logger.Printf("[restful] recovered from panic in %s %s: %v\n%s", req.Request.Method, req.Request.URL.Path, reason, stack)
+		if p.OnPanic != nil {
+			p.OnPanic(reason, stack, req)
+		}
+		if resp.committed {
+			return
+		}
+		if p.WriteResponse != nil {
+			p.WriteResponse(reason, req, resp)
+			return
+		}
+		// the reason can reveal internals, e.g. a query or a file path
+		problem := NewProblemDocument(http.StatusInternalServerError, "the request could not be completed")
+		if resp.VerboseErrors() {
+			problem.Detail = fmt.Sprintf("%v", reason)
+			problem.Diagnostics = map[string]interface{}{"stack": string(stack), "attributes": req.attributeDump()}
+		}
+		resp.WriteProblem(problem)
+	}()
+	chain.ProcessFilter(req, resp)
+}
+
+// attributeDump returns the request attributes formatted as strings.
+func (r *Request) attributeDump() map[string]string {
+	dump := map[string]string{}
+	for k, v := range r.attributes {
+		dump[k] = fmt.Sprintf("%v", v)
+	}
+	return dump
+}
@@ -0,0 +1,100 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestPanicRecovery ...restful
+func TestPanicRecovery(t *testing.T) {
+	var reported interface{}
+	recovery := PanicRecovery{
+		Logger:  testLogger{t},
+		OnPanic: func(reason interface{}, stack []byte, req *Request) { reported = reason },
+	}
+	container := NewContainer()
+	container.Filter(recovery.Filter)
+	ws := new(WebService).Path("/recover")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { panic("boom") }))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/recover", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_PROBLEM_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	var problem ProblemDocument
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := problem.Detail, "the request could not be completed"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := reported, "boom"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	networks, _ := ParseNetworks("10.0.0.0/8")
+	container.ErrorVerbosity(ErrorVerbosity{InternalNetworks: networks})
+	httpRequest.RemoteAddr = "10.0.0.1:1234"
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	problem = ProblemDocument{}
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := problem.Detail, "boom"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestPanicRecovery_WriteResponse(t *testing.T) {
+	recovery := PanicRecovery{
+		Logger: testLogger{t},
+		WriteResponse: func(reason interface{}, req *Request, resp *Response) {
+			resp.WriteErrorString(http.StatusServiceUnavailable, "try again")
+		},
+	}
+	container := NewContainer()
+	ws := new(WebService).Path("/recover").Filter(recovery.Filter)
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { panic("boom") }))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/recover", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if !strings.Contains(httpWriter.Body.String(), "try again") {
+		t.Errorf("unexpected body %q", httpWriter.Body.String())
+	}
+}
+
+func TestPanicRecovery_AfterCommit(t *testing.T) {
+	recovery := PanicRecovery{Logger: testLogger{t}}
+	container := NewContainer()
+	ws := new(WebService).Path("/recover").Filter(recovery.Filter)
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteHeader(http.StatusAccepted)
+		panic("boom")
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/recover", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusAccepted; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := httpWriter.Body.Len(); got != 0 {
+		t.Errorf("unexpected body length %d", got)
+	}
+}
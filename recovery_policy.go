@@ -0,0 +1,90 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// KeyRecoveryPolicy is the Route metadata key for a RecoveryPolicy that overrides the one of the Container.
+// See RouteBuilder.Recovery.
+const KeyRecoveryPolicy = "restful.recoveryPolicy"
+
+// RecoveryPolicy tells the Container what to do with a panic in a filter or RouteFunction.
+// Use RecoverAndLog, RecoverWith or PropagatePanics to create one.
+type RecoveryPolicy struct {
+	propagate bool
+	handler   RecoverHandleFunction
+}
+
+// RecoverAndLog is the default policy ; the panic is recovered, its stack is logged and a 500 (Internal Server Error)
+// is written. The stack is part of the response unless the Container has an ErrorVerbosity that hides it.
+var RecoverAndLog = RecoveryPolicy{handler: logStackOnRecover}
+
+// PropagatePanics is the policy that does not recover ; the panic reaches net/http, or crashes a test that
+// calls ServeHTTP directly.
+var PropagatePanics = RecoveryPolicy{propagate: true}
+
+// RecoverWith returns the policy that recovers and calls the handler to write the response.
+func RecoverWith(handler RecoverHandleFunction) RecoveryPolicy {
+	return RecoveryPolicy{handler: handler}
+}
+
+// Recovers returns whether the panic is recovered.
+func (p RecoveryPolicy) Recovers() bool {
+	return !p.propagate
+}
+
+// Recovery sets the RecoveryPolicy for panics in Routes that have no policy of their own,
+// and for panics in Container filters if no Route matches. Default is RecoverAndLog.
+//
+//	container.Recovery(restful.PropagatePanics) // e.g. in tests
+func (c *Container) Recovery(policy RecoveryPolicy) {
+	c.recoveryPolicy = policy
+}
+
+// Recovery sets the RecoveryPolicy for panics in this Route and the filters that process its requests.
+//
+//	ws.Route(ws.GET("/report").To(buildReport).Recovery(restful.RecoverWith(writeReportUnavailable)))
+func (b *RouteBuilder) Recovery(policy RecoveryPolicy) *RouteBuilder {
+	return b.Metadata(KeyRecoveryPolicy, policy)
+}
+
+// recoveryPolicyFor returns the policy of the Route, if any, or else the one of the Container.
+func (c *Container) recoveryPolicyFor(route *Route) RecoveryPolicy {
+	if route != nil {
+		if policy, ok := route.Metadata[KeyRecoveryPolicy].(RecoveryPolicy); ok {
+			return policy
+		}
+	}
+	return c.recoveryPolicy
+}
+
+// RecoveredPanic is the reason of a panic that a filter raises again on the goroutine of the request after
+// recovering it on another goroutine, as Timeout and WorkerPool do. Its Stack is that of the goroutine where
+// the panic happened ; PanicRecovery and RecoverAndLog report it instead of the stack of the filter.
+type RecoveredPanic struct {
+	Reason interface{}
+	Stack  []byte
+}
+
+// String returns the Reason formatted with %v.
+func (p *RecoveredPanic) String() string {
+	return fmt.Sprintf("%v", p.Reason)
+}
+
+// recoveredPanic returns the reason, with the current stack, to raise again on the goroutine of the request.
+// It must be called by the deferred function that recovers. http.ErrAbortHandler is returned as is.
+func recoveredPanic(reason interface{}) interface{} {
+	if reason == nil || reason == http.ErrAbortHandler {
+		return reason
+	}
+	if _, ok := reason.(*RecoveredPanic); ok {
+		return reason // raised again by a nested filter
+	}
+	return &RecoveredPanic{Reason: reason, Stack: debug.Stack()}
+}
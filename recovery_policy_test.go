@@ -0,0 +1,61 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newPanickingContainer(routePolicy *RecoveryPolicy) *Container {
+	c := NewContainer()
+	ws := new(WebService).Path("/panic")
+	builder := ws.GET("").To(func(req *Request, resp *Response) { panic("boom") })
+	if routePolicy != nil {
+		builder.Recovery(*routePolicy)
+	}
+	ws.Route(builder)
+	c.Add(ws)
+	return c
+}
+
+func servePanic(c *Container) (code int, panicked interface{}) {
+	defer func() { panicked = recover() }()
+	httpRequest, _ := http.NewRequest("GET", "/panic", nil)
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	return httpWriter.Code, nil
+}
+
+// go test -v -test.run TestRecoveryPolicyContainer ...restful
+func TestRecoveryPolicyContainer(t *testing.T) {
+	c := newPanickingContainer(nil)
+	c.Recovery(PropagatePanics)
+	if _, panicked := servePanic(c); panicked != "boom" {
+		t.Errorf("got %v want boom", panicked)
+	}
+	c.DoNotRecover(false)
+	if code, panicked := servePanic(c); panicked != nil || code != 500 {
+		t.Errorf("got %v,%v want 500,nil", code, panicked)
+	}
+	c.RecoverHandler(func(reason interface{}, w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) })
+	c.DoNotRecover(true)
+	c.DoNotRecover(false) // keeps the handler
+	if code, _ := servePanic(c); code != 503 {
+		t.Errorf("got %v want 503", code)
+	}
+}
+
+// go test -v -test.run TestRecoveryPolicyRoute ...restful
+func TestRecoveryPolicyRoute(t *testing.T) {
+	c := newPanickingContainer(&PropagatePanics)
+	if _, panicked := servePanic(c); panicked != "boom" {
+		t.Errorf("got %v want boom", panicked)
+	}
+
+	policy := RecoverWith(func(reason interface{}, w http.ResponseWriter) { w.WriteHeader(http.StatusTeapot) })
+	c = newPanickingContainer(&policy)
+	c.DoNotRecover(true)
+	if code, panicked := servePanic(c); panicked != nil || code != http.StatusTeapot {
+		t.Errorf("got %v,%v want 418,nil", code, panicked)
+	}
+}
@@ -0,0 +1,70 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "net/http"
+
+// KeyRenderOptions is the Route metadata key for a RenderOptionsFunc that overrides
+// the default RenderOptions of each Response for that Route.
+// Example:
+//
+//	ws.Route(ws.GET("/dump").To(dump).Metadata(restful.KeyRenderOptions, restful.RenderOptionsFunc(func(o *restful.RenderOptions) {
+//		o.PrettyPrint = false
+//		o.Encoding = "" // never compress
+//	})))
+const KeyRenderOptions = "restful.renderOptions"
+
+// RenderOptions holds the per-request settings that are consulted when writing a Response.
+// Defaults are computed by the Container, can be overridden by the selected Route (see KeyRenderOptions)
+// and can be changed by a Filter using Response.SetRenderOptions before anything is written.
+type RenderOptions struct {
+	// PrettyPrint controls the indentation feature of XML and JSON serialization.
+	// It is initialized using var PrettyPrintResponses.
+	PrettyPrint bool
+	// Encoding is the content encoding (ENCODING_GZIP or ENCODING_DEFLATE) applied to the response body.
+	// It is initialized from the Accept-Encoding header if the Container has content encoding enabled.
+	// Empty means no compression.
+	Encoding string
+	// EncodingThreshold is the number of bytes of the response body that is buffered before deciding to apply the Encoding.
+	// A smaller body is written as is, with a Content-Length header. It is initialized using Container.ContentEncodingThreshold.
+	// Zero means the Encoding is applied to all bodies.
+	EncodingThreshold int
+	// Locale is the language (e.g. "nl-NL") of the response content ; it is written as the Content-Language header.
+	// Empty means no preference.
+	Locale string
+	// CanonicalJSON makes JSON entities to be written in canonical form (see NewCanonicalJSONEntityAccessor),
+	// e.g. for Routes whose responses are signed or hashed by clients. It takes precedence over PrettyPrint.
+	CanonicalJSON bool
+	// Fields are the names of the members of JSON objects that are written ; other members are pruned.
+	// A name can be a dotted path, e.g. "author.name", to prune nested objects. Empty means all members.
+	// It is initialized from the query parameter of a Route with KeySparseFieldsets metadata.
+	Fields []string
+}
+
+// RenderOptionsFunc can change RenderOptions ; it is the type of value expected for the KeyRenderOptions Route metadata.
+type RenderOptionsFunc func(*RenderOptions)
+
+// defaultRenderOptions returns the RenderOptions for a Http request as configured by the Container.
+func (c *Container) defaultRenderOptions(httpRequest *http.Request) RenderOptions {
+	options := RenderOptions{PrettyPrint: PrettyPrintResponses}
+	if c.contentEncodingEnabled {
+		if doCompress, encoding := wantsCompressedResponse(httpRequest); doCompress {
+			options.Encoding = encoding
+			options.EncodingThreshold = c.encodingThreshold
+		}
+	}
+	return options
+}
+
+// renderOptions returns the defaults after applying the RenderOptionsFunc from the Route metadata, if any.
+func (r Route) renderOptions(defaults RenderOptions) RenderOptions {
+	switch override := r.Metadata[KeyRenderOptions].(type) {
+	case RenderOptionsFunc:
+		override(&defaults)
+	case func(*RenderOptions):
+		override(&defaults)
+	}
+	return defaults
+}
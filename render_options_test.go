@@ -0,0 +1,74 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestRenderOptions_RouteMetadata ...restful
+func TestRenderOptions_RouteMetadata(t *testing.T) {
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	ws := new(WebService).Path("/render")
+	ws.Route(ws.GET("/plain").To(writeFood).Produces(MIME_JSON).
+		Metadata(KeyRenderOptions, RenderOptionsFunc(func(o *RenderOptions) {
+			o.PrettyPrint = false
+			o.Encoding = ""
+		})))
+	ws.Route(ws.GET("/default").To(writeFood).Produces(MIME_JSON))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/render/plain", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "{\"Kind\":\"apple\"}\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got := httpWriter.Header().Get(HEADER_ContentEncoding); got != "" {
+		t.Errorf("unexpected encoding %q", got)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/render/default", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_ContentEncoding), ENCODING_GZIP; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestRenderOptions_SetByFilter ...restful
+func TestRenderOptions_SetByFilter(t *testing.T) {
+	container := NewContainer()
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		options := resp.RenderOptions()
+		options.Locale = "nl-NL"
+		resp.SetRenderOptions(options)
+		chain.ProcessFilter(req, resp)
+	})
+	ws := new(WebService).Path("/render")
+	ws.Route(ws.GET("").To(writeFood).Produces(MIME_JSON))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/render", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_ContentLanguage), "nl-NL"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestRenderOptions_FixedAfterCommit(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	resp.WriteHeader(http.StatusOK)
+	resp.SetRenderOptions(RenderOptions{Encoding: ENCODING_GZIP})
+	if got := resp.RenderOptions().Encoding; got != "" {
+		t.Errorf("unexpected encoding %q", got)
+	}
+}
+
+func writeFood(req *Request, resp *Response) {
+	resp.WriteEntity(food{"apple"})
+}
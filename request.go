@@ -5,9 +5,9 @@ package restful
 // that can be found in the LICENSE file.
 
 import (
-	"bytes"
 	"compress/zlib"
-	"io/ioutil"
+	"context"
+	"log/slog"
 	"net/http"
 )
 
@@ -18,18 +18,24 @@ var doCacheReadEntityBytes = true
 // Request is a wrapper for a http Request that provides convenience methods
 type Request struct {
 	Request           *http.Request
-	bodyContent       *[]byte // to cache the request body for multiple reads of ReadEntity
-	pathParameters    map[string]string
-	attributes        map[string]interface{} // for storing request-scoped values
+	bodyContent       *[]byte                // to cache the request body for multiple reads of ReadEntity
+	pathParameters    map[string]string      // nil if the Route has none
+	attributes        map[string]interface{} // for storing request-scoped values ; nil until the first is set
 	selectedRoutePath string                 // root path + route path that matched the request, e.g. /meetings/{id}/attendees
+	selectedRoute     *Route                 // Route that matched the request ; nil if not dispatched by a Container
+	identifierFormat  IdentifierFormat       // policy of the Container for identifier parameters
+	tracer            *slog.Logger           // non-nil if the request is selected for tracing by the Container
+	clientIPPolicy    *ClientIPPolicy        // policy of the Container for finding the client IP address ; can be nil
+	validator         Validator              // Validator of the Container ; can be nil
+	maxBodyBytes      int64                  // limit of the Container for buffering the body ; 0 means no limit
+	messageCatalog    MessageCatalog         // MessageCatalog of the WebService and Container ; can be nil
+	scope             *requestScope          // request scoped dependencies of the Container ; nil if none are provided
 }
 
 func NewRequest(httpRequest *http.Request) *Request {
 	return &Request{
-		Request:        httpRequest,
-		pathParameters: map[string]string{},
-		attributes:     map[string]interface{}{},
-	} // empty parameters, attributes
+		Request: httpRequest,
+	} // parameters, attributes are allocated when needed
 }
 
 // If ContentType is missing or */* is given then fall back to this type, otherwise
@@ -54,6 +60,9 @@ func (r *Request) PathParameter(name string) string {
 
 // PathParameters accesses the Path parameter values
 func (r *Request) PathParameters() map[string]string {
+	if r.pathParameters == nil {
+		r.pathParameters = map[string]string{}
+	}
 	return r.pathParameters
 }
 
@@ -77,20 +86,16 @@ func (r *Request) HeaderParameter(name string) string {
 }
 
 // ReadEntity checks the Accept header and reads the content into the entityPointer.
+// The value is then checked by the Validator of the Container, if any ; see ValidationError.
 func (r *Request) ReadEntity(entityPointer interface{}) (err error) {
 	contentType := r.Request.Header.Get(HEADER_ContentType)
 	contentEncoding := r.Request.Header.Get(HEADER_ContentEncoding)
 
-	// OLD feature, cache the body for reads
-	if doCacheReadEntityBytes {
-		if r.bodyContent == nil {
-			data, err := ioutil.ReadAll(r.Request.Body)
-			if err != nil {
-				return err
-			}
-			r.bodyContent = &data
+	// OLD feature, cache the body for reads ; also reuse the body if already read by BodyBytes
+	if doCacheReadEntityBytes || r.bodyContent != nil {
+		if _, err := r.BodyBytes(); err != nil {
+			return err
 		}
-		r.Request.Body = ioutil.NopCloser(bytes.NewReader(*r.bodyContent))
 	}
 
 	// check if the request body needs decompression
@@ -109,17 +114,31 @@ func (r *Request) ReadEntity(entityPointer interface{}) (err error) {
 
 	// lookup the EntityReader
 	entityReader, ok := entityAccessRegistry.AccessorAt(contentType)
+	if r.tracer != nil {
+		traceEntityAccessor(r.tracer, "reader", contentType, entityReader, ok)
+	}
 	if !ok {
 		return NewError(http.StatusBadRequest, "Unable to unmarshal content of type:"+contentType)
 	}
-	return entityReader.Read(r, entityPointer)
+	if err := entityReader.Read(r, entityPointer); err != nil {
+		return err
+	}
+	return r.validate(entityPointer, http.StatusUnprocessableEntity)
 }
 
 // SetAttribute adds or replaces the attribute with the given value.
 func (r *Request) SetAttribute(name string, value interface{}) {
+	r.ensureAttributes()
 	r.attributes[name] = value
 }
 
+// ensureAttributes allocates the attributes such that copies of the Request share them.
+func (r *Request) ensureAttributes() {
+	if r.attributes == nil {
+		r.attributes = map[string]interface{}{}
+	}
+}
+
 // Attribute returns the value associated to the given name. Returns nil if absent.
 func (r Request) Attribute(name string) interface{} {
 	return r.attributes[name]
@@ -129,3 +148,28 @@ func (r Request) Attribute(name string) interface{} {
 func (r Request) SelectedRoutePath() string {
 	return r.selectedRoutePath
 }
+
+// SelectedRoute returns the Route that matched the request, e.g. to inspect its Operation or Metadata in a Filter.
+// Returns nil if the request was not dispatched by a Container.
+func (r Request) SelectedRoute() *Route {
+	return r.selectedRoute
+}
+
+// Context returns the context of the underlying http.Request. It is cancelled when the client
+// goes away or, if a Timeout filter applies, at its deadline. Pass it to calls of downstream services.
+func (r *Request) Context() context.Context {
+	return r.Request.Context()
+}
+
+// WithContext returns a shallow copy of the Request with its http.Request changed to use ctx.
+// Path parameters and attributes are shared with the original. A filter passes it on to
+// make the context available to the next filters and the RouteFunction:
+//
+//	chain.ProcessFilter(req.WithContext(ctx), resp)
+func (r *Request) WithContext(ctx context.Context) *Request {
+	r.ensureAttributes()
+	r.PathParameters()
+	copied := *r
+	copied.Request = r.Request.WithContext(ctx)
+	return &copied
+}
@@ -0,0 +1,54 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// MaxBodyBytes sets the maximum number of bytes of a request body that is buffered by BodyBytes and ReadEntity
+// for requests dispatched by the Container. A larger body results in a 413 (Request Entity Too Large).
+// Default is 0 which means no limit.
+func (c *Container) MaxBodyBytes(max int64) {
+	c.maxBodyBytes = max
+}
+
+// BodyBytes reads and returns the raw body of the request, as received (i.e. not decompressed).
+// The content is kept such that other filters, ReadEntity and BodyBytes itself can read it again ;
+// after each call the Request.Body starts at the beginning of the content.
+// If the body exceeds the limit of the Container then a ServiceError with status 413 is returned.
+func (r *Request) BodyBytes() ([]byte, error) {
+	if r.bodyContent == nil {
+		data, err := r.readBody()
+		if err != nil {
+			return nil, err
+		}
+		r.bodyContent = &data
+	}
+	r.Request.Body = ioutil.NopCloser(bytes.NewReader(*r.bodyContent))
+	return *r.bodyContent, nil
+}
+
+// readBody reads the body of the http Request up to the limit, if any.
+func (r *Request) readBody() ([]byte, error) {
+	if r.Request.Body == nil {
+		return []byte{}, nil
+	}
+	if r.maxBodyBytes <= 0 {
+		return ioutil.ReadAll(r.Request.Body)
+	}
+	// read one more byte to detect a body that is too large
+	data, err := ioutil.ReadAll(io.LimitReader(r.Request.Body, r.maxBodyBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > r.maxBodyBytes {
+		return nil, NewError(http.StatusRequestEntityTooLarge, "413: Request Entity Too Large")
+	}
+	return data, nil
+}
@@ -0,0 +1,78 @@
+package restful
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestBodyBytesRepeatable ...restful
+func TestBodyBytesRepeatable(t *testing.T) {
+	SetCacheReadEntity(false)
+	defer SetCacheReadEntity(true)
+	httpRequest, _ := http.NewRequest("POST", "/test", strings.NewReader(`{"Value":"42"}`))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+	request := &Request{Request: httpRequest}
+
+	first, err := request.BodyBytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the body is rewound for the next reader
+	raw, _ := ioutil.ReadAll(request.Request.Body)
+	if got, want := string(raw), string(first); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// ReadEntity uses the cached body even if caching is disabled
+	sam := new(Sample)
+	if err := request.ReadEntity(sam); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := sam.Value, "42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	second, _ := request.BodyBytes()
+	if got, want := string(second), `{"Value":"42"}`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestBodyBytesNoBody(t *testing.T) {
+	request := &Request{Request: &http.Request{Method: "GET"}}
+	data, err := request.BodyBytes()
+	if err != nil || len(data) != 0 {
+		t.Errorf("got %v,%v want empty", data, err)
+	}
+}
+
+// go test -v -test.run TestMaxBodyBytes ...restful
+func TestMaxBodyBytes(t *testing.T) {
+	ws := new(WebService).Path("/audit")
+	ws.Route(ws.POST("").To(func(req *Request, resp *Response) {
+		data, err := req.BodyBytes()
+		if err != nil {
+			resp.WriteErrorString(err.(ServiceError).Code, err.Error())
+			return
+		}
+		resp.Write(data)
+	}))
+	c := NewContainer().Add(ws)
+	c.MaxBodyBytes(4)
+
+	for _, each := range []struct {
+		body string
+		want int
+	}{
+		{"1234", http.StatusOK},
+		{"12345", http.StatusRequestEntityTooLarge},
+	} {
+		httpRequest, _ := http.NewRequest("POST", "/audit", strings.NewReader(each.body))
+		httpWriter := httptest.NewRecorder()
+		c.ServeHTTP(httpWriter, httpRequest)
+		if got := httpWriter.Code; got != each.want {
+			t.Errorf("%s: got %v want %v", each.body, got, each.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "fmt"
+
+// ScopedConstructor creates the value of a request scoped dependency. The cleanup function, if not nil,
+// is called when the Container completes the request, e.g. to close a database session.
+type ScopedConstructor[T any] func(req *Request) (value T, cleanup func(), err error)
+
+// scopedConstructor is a ScopedConstructor with its type erased.
+type scopedConstructor func(req *Request) (interface{}, func(), error)
+
+// requestScope has the constructors of the Container and the cleanup functions of the values created for a request.
+// Like attributes, it is not safe for concurrent use.
+type requestScope struct {
+	constructors map[string]scopedConstructor
+	cleanups     []func()
+	// completed, if not nil, is closed when a chain that outlives the request completes, see Timeout
+	completed <-chan struct{}
+}
+
+// ProvideScoped registers the constructor of the dependency that is stored as the attribute of the key.
+// It is called lazily, at most once per request, by the first Resolve of that key ; the value is then
+// cached as the request attribute. Constructors must be registered before the Container serves requests.
+// The cleanup functions are called after the response is written ; if a Timeout filter responded before
+// the chain completed, they are called when the chain completes because it can still use the values.
+//
+//	var sessionKey = restful.NewAttributeKey[*Session]("session")
+//	restful.ProvideScoped(container, sessionKey, func(req *restful.Request) (*Session, func(), error) {
+//		session, err := pool.Open(req.Context())
+//		return session, session.Close, err
+//	})
+func ProvideScoped[T any](c *Container, key AttributeKey[T], constructor ScopedConstructor[T]) {
+	constructors := map[string]scopedConstructor{}
+	for name, each := range c.scopedConstructors {
+		constructors[name] = each
+	}
+	constructors[key.name] = func(req *Request) (interface{}, func(), error) {
+		return constructor(req)
+	}
+	c.scopedConstructors = constructors
+}
+
+// Resolve returns the request scoped dependency of the key. It returns the attribute if set ;
+// otherwise it calls the constructor registered with ProvideScoped and sets the attribute.
+// A constructor can Resolve other dependencies. It returns an error if there is no constructor or it fails.
+func Resolve[T any](req *Request, key AttributeKey[T]) (T, error) {
+	if value, ok := GetAttr(req, key); ok {
+		return value, nil
+	}
+	var zero T
+	var constructor scopedConstructor
+	if req.scope != nil {
+		constructor = req.scope.constructors[key.name]
+	}
+	if constructor == nil {
+		return zero, fmt.Errorf("no request scoped dependency provided for %q", key.name)
+	}
+	value, cleanup, err := constructor(req)
+	if cleanup != nil {
+		req.scope.cleanups = append(req.scope.cleanups, cleanup)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("unable to resolve %q: %v", key.name, err)
+	}
+	typed := value.(T)
+	SetAttr(req, key, typed)
+	return typed, nil
+}
+
+// newRequestScope returns the scope for a request, or nil if the Container has no constructors.
+func (c *Container) newRequestScope() *requestScope {
+	if len(c.scopedConstructors) == 0 {
+		return nil
+	}
+	return &requestScope{constructors: c.scopedConstructors}
+}
+
+// closeAfter makes close wait until the completed channel is closed.
+func (s *requestScope) closeAfter(completed <-chan struct{}) {
+	s.completed = completed
+}
+
+// close calls the cleanup functions in reverse order of creation, i.e. dependents first.
+func (s *requestScope) close() {
+	if s.completed != nil {
+		completed := s.completed
+		s.completed = nil
+		go func() {
+			<-completed
+			s.close()
+		}()
+		return
+	}
+	for i := len(s.cleanups) - 1; i >= 0; i-- {
+		s.cleanups[i]()
+	}
+	s.cleanups = nil
+}
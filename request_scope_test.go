@@ -0,0 +1,120 @@
+package restful
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type scopedSession struct {
+	tenant string
+	closed bool
+}
+
+var (
+	scopedTenantKey  = NewAttributeKey[string]("test.tenant")
+	scopedSessionKey = NewAttributeKey[*scopedSession]("test.session")
+)
+
+// go test -v -test.run TestResolveScopedDependencies ...restful
+func TestResolveScopedDependencies(t *testing.T) {
+	container := NewContainer()
+	calls, order := 0, []string{}
+	ProvideScoped(container, scopedTenantKey, func(req *Request) (string, func(), error) {
+		return req.HeaderParameter("X-Tenant"), func() { order = append(order, "tenant") }, nil
+	})
+	var session *scopedSession
+	ProvideScoped(container, scopedSessionKey, func(req *Request) (*scopedSession, func(), error) {
+		calls++
+		tenant, err := Resolve(req, scopedTenantKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		session = &scopedSession{tenant: tenant}
+		return session, func() { session.closed = true; order = append(order, "session") }, nil
+	})
+	ws := new(WebService).Path("/scoped")
+	ws.Route(ws.GET("").Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		if _, err := Resolve(req, scopedSessionKey); err != nil {
+			t.Error(err)
+		}
+		chain.ProcessFilter(req, resp)
+	}).To(func(req *Request, resp *Response) {
+		session, _ := Resolve(req, scopedSessionKey)
+		if session.closed {
+			t.Error("closed before the end of the request")
+		}
+		resp.Write([]byte(session.tenant))
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/scoped", nil)
+	httpRequest.Header.Set("X-Tenant", "acme")
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "acme"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %d constructor calls want %d", got, want)
+	}
+	if !session.closed {
+		t.Error("cleanup not called")
+	}
+	if got, want := strings.Join(order, ","), "session,tenant"; got != want {
+		t.Errorf("got cleanup order %s want %s", got, want)
+	}
+}
+
+// go test -v -test.run TestResolveScopedDependencyErrors ...restful
+func TestResolveScopedDependencyErrors(t *testing.T) {
+	req := NewRequest(nil)
+	if _, err := Resolve(req, scopedTenantKey); err == nil {
+		t.Error("expected error without constructor")
+	}
+	container := NewContainer()
+	ProvideScoped(container, scopedTenantKey, func(req *Request) (string, func(), error) {
+		return "", nil, errors.New("no tenant")
+	})
+	req.scope = container.newRequestScope()
+	if _, err := Resolve(req, scopedTenantKey); err == nil || !strings.Contains(err.Error(), "no tenant") {
+		t.Errorf("got %v want constructor error", err)
+	}
+	if _, ok := GetAttr(req, scopedTenantKey); ok {
+		t.Error("failed value cached")
+	}
+}
+
+// go test -v -test.run TestScopedDependenciesAfterTimeout ...restful
+func TestScopedDependenciesAfterTimeout(t *testing.T) {
+	container := NewContainer()
+	cleaned := make(chan bool, 1)
+	ProvideScoped(container, scopedSessionKey, func(req *Request) (*scopedSession, func(), error) {
+		session := &scopedSession{}
+		return session, func() { session.closed = true; cleaned <- true }, nil
+	})
+	container.Filter(Timeout{Duration: 10 * time.Millisecond}.Filter)
+	release, checked := make(chan bool), make(chan bool, 1)
+	ws := new(WebService).Path("/scoped")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		session, _ := Resolve(req, scopedSessionKey)
+		<-release
+		checked <- session.closed
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/scoped", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	release <- true
+	if <-checked {
+		t.Error("closed before the chain completed")
+	}
+	<-cleaned
+}
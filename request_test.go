@@ -1,6 +1,7 @@
 package restful
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -202,3 +203,22 @@ func TestSetAttribute(t *testing.T) {
 		t.Fatalf("missing request attribute:%v", there)
 	}
 }
+
+type requestTestKey struct{}
+
+// go test -v -test.run TestRequestWithContext ...restful
+func TestRequestWithContext(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	req.SetAttribute("shared", true)
+	copied := req.WithContext(context.WithValue(req.Context(), requestTestKey{}, "value"))
+	if got, want := copied.Context().Value(requestTestKey{}), "value"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if req.Context().Value(requestTestKey{}) != nil {
+		t.Error("original request context must not change")
+	}
+	if copied.Attribute("shared") != true {
+		t.Error("attributes must be shared")
+	}
+}
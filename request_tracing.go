@@ -0,0 +1,123 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful/log"
+)
+
+// RequestTracing selects requests for which the Container logs how they are dispatched:
+// the route selection, each filter and the RouteFunction entered and exited, and
+// the EntityReaderWriter chosen for reading and writing entities.
+// Unlike EnableTracing, which logs for all requests, it targets specific requests.
+// Because tracing multiplies the log records of a request, clients must prove that they may enable it:
+// either the Header has the secret Token or Match selects the request. See Container.TraceRequests.
+//
+//	container.TraceRequests(restful.RequestTracing{Token: os.Getenv("TRACE_TOKEN")})
+type RequestTracing struct {
+	// Header is the name of the request header that selects the request if its value is Token ; default is "X-Restful-Trace".
+	Header string
+	// Token is the secret value of the Header, compared in constant time. If empty, the Header selects no requests.
+	Token string
+	// Match, if set, selects the requests instead of Header ; e.g. to require a signed token (see ErrorVerbosity.Verbose).
+	// The value of the Header is then logged as the "trace" attribute.
+	Match func(httpRequest *http.Request) bool
+	// Logger receives the records at Info level ; default is slog.Default().
+	Logger *slog.Logger
+}
+
+// TraceRequests enables dispatch tracing for the requests selected by the RequestTracing.
+func (c *Container) TraceRequests(tracing RequestTracing) {
+	if tracing.Match == nil && len(tracing.Token) == 0 {
+		log.Print("[restful] RequestTracing has no Token or Match ; no requests are traced")
+	}
+	c.requestTracing = &tracing
+}
+
+// tracerContextKey is the key of the logger in the context of a request that is selected for tracing.
+type tracerContextKey struct{}
+
+// TracerFromContext returns the logger of a request that is selected for tracing, nil otherwise.
+// Handlers can use it to add records, e.g. about calls to downstream services, to the trace.
+//
+//	if tracer := restful.TracerFromContext(req.Context()); tracer != nil {
+//		tracer.Info("calling inventory", "sku", sku)
+//	}
+func TracerFromContext(ctx context.Context) *slog.Logger {
+	tracer, _ := ctx.Value(tracerContextKey{}).(*slog.Logger)
+	return tracer
+}
+
+// requestTracer returns a logger for the Http request if it is selected for tracing, nil otherwise.
+func (c *Container) requestTracer(httpRequest *http.Request) *slog.Logger {
+	if c.requestTracing == nil {
+		return nil
+	}
+	tracing := c.requestTracing
+	headerName := tracing.Header
+	if len(headerName) == 0 {
+		headerName = "X-Restful-Trace"
+	}
+	attributes := []any{slog.String("method", httpRequest.Method), slog.String("path", httpRequest.URL.Path)}
+	if tracing.Match != nil {
+		if !tracing.Match(httpRequest) {
+			return nil
+		}
+		attributes = append([]any{slog.String("trace", httpRequest.Header.Get(headerName))}, attributes...)
+	} else {
+		value := httpRequest.Header.Get(headerName)
+		if len(tracing.Token) == 0 || subtle.ConstantTimeCompare([]byte(value), []byte(tracing.Token)) != 1 {
+			return nil
+		}
+	}
+	logger := tracing.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return logger.With(attributes...)
+}
+
+// traceRouteSelection logs the outcome of selecting a Route.
+func traceRouteSelection(tracer *slog.Logger, webService *WebService, route *Route, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		if serviceError, ok := err.(ServiceError); ok {
+			status = serviceError.Code
+		}
+		tracer.Info("restful: no route selected", slog.Int("status", status), slog.String("error", err.Error()))
+		return
+	}
+	tracer.Info("restful: route selected",
+		slog.String("webService", webService.RootPath()),
+		slog.String("route", route.Path),
+		slog.String("operation", route.Operation),
+		slog.Any("produces", route.Produces),
+		slog.Any("consumes", route.Consumes))
+}
+
+// traceCall logs entering a filter or RouteFunction and returns the function that logs exiting it.
+func traceCall(tracer *slog.Logger, kind string, function interface{}) func() {
+	name := qualifiedNameOfFunction(function)
+	tracer.Info("restful: enter "+kind, slog.String("name", name))
+	start := time.Now()
+	return func() {
+		tracer.Info("restful: exit "+kind, slog.String("name", name), slog.Duration("elapsed", time.Since(start)))
+	}
+}
+
+// traceEntityAccessor logs which EntityReaderWriter was chosen (if any) to read or write an entity.
+func traceEntityAccessor(tracer *slog.Logger, direction, mime string, accessor EntityReaderWriter, found bool) {
+	tracer.Info("restful: entity "+direction,
+		slog.String("mime", mime),
+		slog.Bool("found", found),
+		slog.String("accessor", fmt.Sprintf("%T", accessor)))
+}
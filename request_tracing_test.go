@@ -0,0 +1,115 @@
+package restful
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTracedContainer(buf *bytes.Buffer) *Container {
+	c := NewContainer()
+	c.TraceRequests(RequestTracing{Token: "s3cret", Logger: slog.New(slog.NewTextHandler(buf, nil))})
+	ws := new(WebService).Path("/traced").Produces(MIME_JSON)
+	ws.Route(ws.GET("/{id}").Operation("getTraced").Filter(routeFilter).To(func(req *Request, resp *Response) {
+		if tracer := TracerFromContext(req.Context()); tracer != nil {
+			tracer.Info("calling upstream")
+		}
+		resp.WriteEntity(food{Kind: req.PathParameter("id")})
+	}))
+	c.Add(ws)
+	return c
+}
+
+// go test -v -test.run TestRequestTracing ...restful
+func TestRequestTracing(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := newTracedContainer(buf)
+	httpRequest, _ := http.NewRequest("GET", "/traced/apple", nil)
+	httpRequest.Header.Set("Accept", MIME_JSON)
+	httpRequest.Header.Set("X-Restful-Trace", "s3cret")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	log := buf.String()
+	for _, want := range []string{
+		"route selected",
+		"operation=getTraced",
+		"enter filter",
+		"exit filter",
+		"enter route function",
+		"exit route function",
+		"entity writer",
+		"calling upstream",
+		"path=/traced/apple",
+	} {
+		if !strings.Contains(log, want) {
+			t.Errorf("got %q want it to contain %q", log, want)
+		}
+	}
+	if strings.Contains(log, "s3cret") {
+		t.Errorf("got %q with the token", log)
+	}
+}
+
+// go test -v -test.run TestRequestTracingNotSelected ...restful
+func TestRequestTracingNotSelected(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := newTracedContainer(buf)
+	for _, value := range []string{"", "1", "s3cre"} {
+		httpRequest, _ := http.NewRequest("GET", "/traced/apple", nil)
+		httpRequest.Header.Set("X-Restful-Trace", value)
+		c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+		if got := buf.String(); len(got) > 0 {
+			t.Errorf("%q: got %q want no trace", value, got)
+		}
+	}
+}
+
+// go test -v -test.run TestRequestTracingWithoutToken ...restful
+func TestRequestTracingWithoutToken(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := NewContainer()
+	c.TraceRequests(RequestTracing{Logger: slog.New(slog.NewTextHandler(buf, nil))})
+	ws := new(WebService).Path("/traced")
+	ws.Route(ws.GET("").To(dummy))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/traced", nil)
+	httpRequest.Header.Set("X-Restful-Trace", "1")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got := buf.String(); len(got) > 0 {
+		t.Errorf("got %q want no trace", got)
+	}
+}
+
+// go test -v -test.run TestRequestTracingMatch ...restful
+func TestRequestTracingMatch(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := NewContainer()
+	c.TraceRequests(RequestTracing{
+		Match:  func(httpRequest *http.Request) bool { return httpRequest.RemoteAddr == "10.0.0.1:1234" },
+		Logger: slog.New(slog.NewTextHandler(buf, nil)),
+	})
+	ws := new(WebService).Path("/traced")
+	ws.Route(ws.GET("").To(dummy))
+	c.Add(ws)
+	httpRequest, _ := http.NewRequest("GET", "/traced", nil)
+	httpRequest.RemoteAddr = "10.0.0.1:1234"
+	httpRequest.Header.Set("X-Restful-Trace", "t1")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got, want := buf.String(), "trace=t1"; !strings.Contains(got, want) {
+		t.Errorf("got %q want it to contain %q", got, want)
+	}
+}
+
+// go test -v -test.run TestRequestTracingNoRoute ...restful
+func TestRequestTracingNoRoute(t *testing.T) {
+	buf := new(bytes.Buffer)
+	c := newTracedContainer(buf)
+	httpRequest, _ := http.NewRequest("GET", "/traced/apple/pie", nil)
+	httpRequest.Header.Set("X-Restful-Trace", "s3cret")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+	if got, want := buf.String(), "status=404"; !strings.Contains(got, want) {
+		t.Errorf("got %q want it to contain %q", got, want)
+	}
+}
@@ -6,8 +6,12 @@ package restful
 
 import (
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+
+	"github.com/emicklei/go-restful/log"
 )
 
 // DEPRECATED, use DefaultResponseContentType(mime)
@@ -20,17 +24,40 @@ var PrettyPrintResponses = true
 // It provides several convenience methods to prepare and write response content.
 type Response struct {
 	http.ResponseWriter
-	requestAccept string   // mime-type what the Http Request says it wants to receive
-	routeProduces []string // mime-types what the Route says it can produce
-	statusCode    int      // HTTP status code that has been written explicity (if zero then net/http has written 200)
-	contentLength int      // number of bytes written for the response body
-	prettyPrint   bool     // controls the indentation feature of XML and JSON serialization. It is initialized using var PrettyPrintResponses.
-	err           error    // err property is kept when WriteError is called
+	requestAccept string             // mime-type what the Http Request says it wants to receive
+	routeProduces []string           // mime-types what the Route says it can produce
+	statusCode    int                // HTTP status code that has been written explicity (if zero then net/http has written 200)
+	wroteHeader   bool               // true if the (final) status has been sent, explicitly or by writing the body
+	contentLength int                // number of bytes written for the response body, before content encoding
+	err           error              // err property is kept when WriteError is called
+	renderOptions RenderOptions      // settings consulted when writing ; fixed after the header is written
+	committed     bool               // true if the header has been written (explicitly or by writing the body)
+	defaultWriter EntityReaderWriter // used if no registered EntityReaderWriter matches ; can be nil
+
+	lateWritePolicy   LateWritePolicy // what to do if a Filter writes after the RouteFunction
+	routeFunctionDone bool            // true if the RouteFunction has returned
+	lateWriteReported bool            // true if a late write has been reported
+
+	producesRestricted bool             // true if routeProduces was narrowed using RestrictProduces
+	errorDetail        errorDetailLevel // verbosity of 5xx bodies as decided by the ErrorVerbosity of the Container
+	tracer             *slog.Logger     // non-nil if the request is selected for tracing by the Container
+
+	compressWriter *CompressingResponseWriter // non-nil if a compressor was installed ; kept after closing for its count
+	lifecycle      *requestLifecycle          // non-nil if the Container has lifecycle hooks
+	bodyRecorder   io.Writer                  // non-nil if a RequestRecorder captures the response body
+	commitHooks    []func(*Response, int)     // called before the header is written, e.g. by a RetryAdvisor
+
+	requestAcceptLanguage string         // value of the Accept-Language header of the request
+	messageCatalog        MessageCatalog // non-nil if error messages are localized
 }
 
 // Creates a new response based on a http ResponseWriter.
 func NewResponse(httpWriter http.ResponseWriter) *Response {
-	return &Response{httpWriter, "", []string{}, http.StatusOK, 0, PrettyPrintResponses, nil} // empty content-types
+	return &Response{
+		ResponseWriter: httpWriter,
+		routeProduces:  []string{}, // empty content-types
+		statusCode:     http.StatusOK,
+		renderOptions:  RenderOptions{PrettyPrint: PrettyPrintResponses}}
 }
 
 // If Accept header matching fails, fall back to this type.
@@ -50,7 +77,25 @@ func (r Response) InternalServerError() Response {
 
 // PrettyPrint changes whether this response must produce pretty (line-by-line, indented) JSON or XML output.
 func (r *Response) PrettyPrint(bePretty bool) {
-	r.prettyPrint = bePretty
+	r.renderOptions.PrettyPrint = bePretty
+}
+
+// RenderOptions returns the settings that are used for writing this response.
+func (r *Response) RenderOptions() RenderOptions {
+	return r.renderOptions
+}
+
+// SetRenderOptions replaces the settings that are used for writing this response.
+// Typically a Filter calls this once before passing on the request.
+// It has no effect after the header of the response has been written.
+func (r *Response) SetRenderOptions(options RenderOptions) {
+	if r.committed {
+		if trace {
+			traceLogger.Print("render options cannot be changed after the header has been written")
+		}
+		return
+	}
+	r.renderOptions = options
 }
 
 // AddHeader is a shortcut for .Header().Add(header,value)
@@ -68,30 +113,18 @@ func (r *Response) SetRequestAccepts(mime string) {
 // can write according to what the request wants (Accept) and what the Route can produce or what the restful defaults say.
 // If called before WriteEntity and WriteHeader then a false return value can be used to write a 406: Not Acceptable.
 func (r *Response) EntityWriter() (EntityReaderWriter, bool) {
-	for _, qualifiedMime := range strings.Split(r.requestAccept, ",") {
-		mime := strings.Trim(strings.Split(qualifiedMime, ";")[0], " ")
-		if 0 == len(mime) || mime == "*/*" {
-			for _, each := range r.routeProduces {
-				if MIME_JSON == each {
-					return entityAccessRegistry.AccessorAt(MIME_JSON)
-				}
-				if MIME_XML == each {
-					return entityAccessRegistry.AccessorAt(MIME_XML)
-				}
-			}
-		} else { // mime is not blank; see if we have a match in Produces
-			for _, each := range r.routeProduces {
-				if mime == each {
-					if MIME_JSON == each {
-						return entityAccessRegistry.AccessorAt(MIME_JSON)
-					}
-					if MIME_XML == each {
-						return entityAccessRegistry.AccessorAt(MIME_XML)
-					}
-				}
-			}
+	// try the produced MIME types in the order of preference of the Accept header
+	for _, each := range cachedNegotiatedMediaTypes(r.requestAccept, r.routeProduces) {
+		if each == "*/*" {
+			continue
+		}
+		if writer, ok := entityAccessRegistry.AccessorAt(each); ok {
+			return writer, true
 		}
 	}
+	if r.producesRestricted {
+		return r.restrictedEntityWriter()
+	}
 	writer, ok := entityAccessRegistry.AccessorAt(r.requestAccept)
 	if !ok {
 		// if not registered then fallback to the defaults (if set)
@@ -101,6 +134,12 @@ func (r *Response) EntityWriter() (EntityReaderWriter, bool) {
 		if DefaultResponseMimeType == MIME_XML {
 			return entityAccessRegistry.AccessorAt(MIME_XML)
 		}
+		if r.defaultWriter != nil {
+			if trace {
+				traceLogger.Printf("no registered EntityReaderWriter found for %s ; using fallback writer", r.requestAccept)
+			}
+			return r.defaultWriter, true
+		}
 		if trace {
 			traceLogger.Printf("no registered EntityReaderWriter found for %s", r.requestAccept)
 		}
@@ -108,6 +147,46 @@ func (r *Response) EntityWriter() (EntityReaderWriter, bool) {
 	return writer, ok
 }
 
+// RestrictProduces narrows the MIME types in which this response can be written to those given
+// that are also produced by the Route. Typically a Filter calls this to enforce a representation policy,
+// e.g. a partner that only gets XML. Writing an entity for a request that accepts none of the
+// remaining MIME types results in a 406 (Not Acceptable) ; defaults and fallback writers are not used.
+func (r *Response) RestrictProduces(mimes ...string) {
+	if len(r.routeProduces) == 0 {
+		r.routeProduces = mimes
+	} else {
+		allowed := []string{}
+		for _, each := range r.routeProduces {
+			for _, other := range mimes {
+				if each == other {
+					allowed = append(allowed, each)
+				}
+			}
+		}
+		r.routeProduces = allowed
+	}
+	r.producesRestricted = true
+}
+
+// restrictedEntityWriter returns the registered EntityReaderWriter for the first accepted MIME type
+// that is in the (restricted) routeProduces.
+func (r *Response) restrictedEntityWriter() (EntityReaderWriter, bool) {
+	for _, qualifiedMime := range strings.Split(r.requestAccept, ",") {
+		mime := strings.Trim(strings.Split(qualifiedMime, ";")[0], " ")
+		for _, each := range r.routeProduces {
+			if 0 == len(mime) || mime == "*/*" || mime == each {
+				if writer, ok := entityAccessRegistry.AccessorAt(each); ok {
+					return writer, true
+				}
+			}
+		}
+	}
+	if trace {
+		traceLogger.Printf("no registered EntityReaderWriter found for %s in restricted produces %v", r.requestAccept, r.routeProduces)
+	}
+	return nil, false
+}
+
 // WriteEntity calls WriteHeaderAndEntity with Http Status OK (200)
 func (r *Response) WriteEntity(value interface{}) error {
 	return r.WriteHeaderAndEntity(http.StatusOK, value)
@@ -118,15 +197,19 @@ func (r *Response) WriteEntity(value interface{}) error {
 // If an Accept header is specified then respond with the Content-Type as specified by the first in the Route.Produces that is matched with the Accept header.
 // If the value is nil then no response is send except for the Http status. You may want to call WriteHeader(http.StatusNotFound) instead.
 // If there is no writer available that can represent the value in the requested MIME type then Http Status NotAcceptable is written.
-// Current implementation ignores any q-parameters in the Accept Header.
 // Returns an error if the value could not be written on the response.
 func (r *Response) WriteHeaderAndEntity(status int, value interface{}) error {
 	writer, ok := r.EntityWriter()
+	if r.tracer != nil {
+		traceEntityAccessor(r.tracer, "writer", r.requestAccept, writer, ok)
+	}
 	if !ok {
 		r.WriteHeader(http.StatusNotAcceptable)
 		return nil
 	}
-	return writer.Write(r, status, value)
+	err := writer.Write(r, status, value)
+	r.entityWritten(value, err)
+	return err
 }
 
 // WriteAsXml is a convenience method for writing a value in xml (requires Xml tags on the value)
@@ -168,6 +251,9 @@ func (r *Response) WriteError(httpStatus int, err error) error {
 // WriteServiceError is a convenience method for a responding with a status and a ServiceError
 func (r *Response) WriteServiceError(httpStatus int, err ServiceError) error {
 	r.err = err
+	if r.terseError(httpStatus) {
+		err.Message = http.StatusText(httpStatus)
+	}
 	return r.WriteHeaderAndEntity(httpStatus, err)
 }
 
@@ -177,6 +263,9 @@ func (r *Response) WriteErrorString(httpStatus int, errorReason string) error {
 		// if not called from WriteError
 		r.err = errors.New(errorReason)
 	}
+	if r.terseError(httpStatus) {
+		errorReason = http.StatusText(httpStatus)
+	}
 	r.WriteHeader(httpStatus)
 	if _, err := r.Write([]byte(errorReason)); err != nil {
 		return err
@@ -186,11 +275,66 @@ func (r *Response) WriteErrorString(httpStatus int, errorReason string) error {
 
 // WriteHeader is overridden to remember the Status Code that has been written.
 // Changes to the Header of the response have no effect after this.
+// Like net/http, only the first status is sent and remembered ; the informational statuses
+// 100 (Continue), 102 (Processing) and 103 (Early Hints) are sent but are not the status of the response.
 func (r *Response) WriteHeader(httpStatus int) {
-	r.statusCode = httpStatus
+	if httpStatus == http.StatusContinue || httpStatus == http.StatusProcessing || httpStatus == http.StatusEarlyHints {
+		r.ResponseWriter.WriteHeader(httpStatus)
+		return
+	}
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.statusCode = httpStatus
+	}
+	r.commit(httpStatus)
 	r.ResponseWriter.WriteHeader(httpStatus)
 }
 
+// commit applies the render options to the header and installs a compressor if needed.
+// Only the first call has effect.
+func (r *Response) commit(httpStatus int) {
+	if r.committed {
+		return
+	}
+	r.committed = true
+	for _, each := range r.commitHooks {
+		each(r, httpStatus)
+	}
+	if len(r.renderOptions.Locale) > 0 && len(r.Header().Get(HEADER_ContentLanguage)) == 0 {
+		r.Header().Set(HEADER_ContentLanguage, r.renderOptions.Locale)
+	}
+	if len(r.renderOptions.Encoding) == 0 || !bodyAllowedForStatus(httpStatus) {
+		return
+	}
+	compressWriter, err := newCompressingResponseWriter(r.ResponseWriter, r.renderOptions.Encoding, r.renderOptions.EncodingThreshold)
+	if err != nil {
+		log.Print("[restful] unable to install compressor: ", err)
+		return
+	}
+	r.ResponseWriter = compressWriter
+	r.compressWriter = compressWriter
+}
+
+// closeCompressor closes the CompressingResponseWriter if one was installed and is still open.
+func (r *Response) closeCompressor() {
+	if r.compressWriter != nil && !r.compressWriter.isCompressorClosed() {
+		r.compressWriter.Close()
+	}
+}
+
+// bodyAllowedForStatus reports whether a given response status code permits a body.
+func bodyAllowedForStatus(status int) bool {
+	switch {
+	case status >= 100 && status <= 199:
+		return false
+	case status == http.StatusNoContent:
+		return false
+	case status == http.StatusNotModified:
+		return false
+	}
+	return true
+}
+
 // StatusCode returns the code that has been written using WriteHeader.
 func (r Response) StatusCode() int {
 	if 0 == r.statusCode {
@@ -203,23 +347,68 @@ func (r Response) StatusCode() int {
 // Write writes the data to the connection as part of an HTTP reply.
 // Write is part of http.ResponseWriter interface.
 func (r *Response) Write(bytes []byte) (int, error) {
+	r.checkLateWrite(len(bytes))
+	r.commit(http.StatusOK)
+	if !r.wroteHeader { // net/http sends 200 (OK) on the first write
+		r.wroteHeader = true
+		r.statusCode = http.StatusOK
+	}
 	written, err := r.ResponseWriter.Write(bytes)
 	r.contentLength += written
+	if r.bodyRecorder != nil {
+		r.bodyRecorder.Write(bytes[:written])
+	}
 	return written, err
 }
 
-// ContentLength returns the number of bytes written for the response content.
+// ContentLength returns the number of bytes written for the response content, before content encoding.
 // Note that this value is only correct if all data is written through the Response using its Write* methods.
 // Data written directly using the underlying http.ResponseWriter is not accounted for.
 func (r Response) ContentLength() int {
 	return r.contentLength
 }
 
+// EncodedContentLength returns the number of bytes sent for the response content, after content encoding.
+// It equals ContentLength if the response is not compressed. Because a compressor buffers its output,
+// the value is only final after the compressor is closed, i.e. after the Container completes
+// the response or an AccessLog filter has recorded it.
+func (r Response) EncodedContentLength() int {
+	if r.compressWriter != nil {
+		return r.compressWriter.counter.count
+	}
+	return r.contentLength
+}
+
 // CloseNotify is part of http.CloseNotifier interface
 func (r Response) CloseNotify() <-chan bool {
 	return r.ResponseWriter.(http.CloseNotifier).CloseNotify()
 }
 
+// Flush sends any buffered data to the client ; it writes the header first if not yet written.
+// Flush is part of http.Flusher interface and flushes a compressor, if any, as well.
+func (r *Response) Flush() {
+	r.commit(r.StatusCode())
+	r.wroteHeader = true
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Push initiates an HTTP/2 server push. Push is part of http.Pusher interface.
+// It returns http.ErrNotSupported if the underlying ResponseWriter does not support it.
+func (r *Response) Push(target string, opts *http.PushOptions) error {
+	if pusher, ok := r.ResponseWriter.(http.Pusher); ok {
+		return pusher.Push(target, opts)
+	}
+	return http.ErrNotSupported
+}
+
+// Unwrap returns the underlying ResponseWriter ; it is used by http.ResponseController
+// to find optional methods, e.g. SetWriteDeadline.
+func (r *Response) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
 // Error returns the err created by WriteError
 func (r Response) Error() error {
 	return r.err
@@ -0,0 +1,234 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeyCacheTTL is the Route metadata key for a time.Duration that overrides the TTL of the ResponseCache for that Route.
+// A zero or negative duration disables caching for the Route.
+//
+//	ws.Route(ws.GET("/countries").To(countries).Metadata(restful.KeyCacheTTL, time.Hour))
+const KeyCacheTTL = "restful.cacheTTL"
+
+// CachedResponse is a successful response as kept by a ResponseCacheStore.
+// Values are shared between requests and must not be changed.
+type CachedResponse struct {
+	Status       int
+	Header       http.Header
+	Body         []byte // not compressed ; the Response compresses it if the request asks for it
+	ETag         string // strong entity tag, quoted
+	LastModified time.Time
+}
+
+// ResponseCacheStore keeps CachedResponse values by key until they expire. See Stores in the package documentation.
+type ResponseCacheStore interface {
+	// Get returns the response for key if it has not expired.
+	Get(key string) (*CachedResponse, bool)
+	// Set keeps the response for key during ttl.
+	Set(key string, response *CachedResponse, ttl time.Duration)
+}
+
+// ResponseCache is used to create a Filter that caches successful responses to GET and HEAD requests.
+// Each cached response gets a strong ETag (unless the RouteFunction has set one) and a Last-Modified header.
+// Conditional requests (If-None-Match, If-Modified-Since) are answered with 304 (Not Modified) and
+// requests for which an entry exists are answered without calling the rest of the chain.
+// Responses with a Cache-Control header containing "no-store" or "private", or with a Set-Cookie header, are not cached.
+// Requests with an Authorization or Cookie header are not answered from the cache, unless a Key is set
+// that separates the responses for each user.
+//
+//	cache := restful.ResponseCache{Store: restful.NewLRUResponseCacheStore(1000), TTL: time.Minute}
+//	ws.Filter(cache.Filter)
+type ResponseCache struct {
+	// Store is required ; without a Store, responses are not cached.
+	Store ResponseCacheStore
+	TTL   time.Duration // default for Routes without KeyCacheTTL metadata ; zero means only those Routes are cached
+	// Key returns the cache key of the request ; default is the method, URL path and query with the Accept and Accept-Encoding headers.
+	Key func(req *Request) string
+}
+
+// Filter answers the request from the cache, if possible, or caches the response of the rest of the chain.
+func (c ResponseCache) Filter(req *Request, resp *Response, chain *FilterChain) {
+	method := req.Request.Method
+	ttl := c.TTL
+	if route := req.SelectedRoute(); route != nil {
+		if override, ok := route.Metadata[KeyCacheTTL].(time.Duration); ok {
+			ttl = override
+		}
+	}
+	if ttl <= 0 || (method != "GET" && method != "HEAD") {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if c.Store == nil {
+		missingCacheStore.Do(func() { log.Print("[restful] ResponseCache has no Store ; responses are not cached") })
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	keyFunc := c.Key
+	if keyFunc == nil {
+		if personalized(req.Request) {
+			chain.ProcessFilter(req, resp)
+			return
+		}
+		keyFunc = defaultResponseCacheKey
+	}
+	key := keyFunc(req)
+	if cached, ok := c.Store.Get(key); ok {
+		cached.writeTo(req, resp)
+		return
+	}
+	// capture the uncompressed response of the chain
+	buffer := &timeoutWriter{header: http.Header{}}
+	shadow := *resp
+	shadow.ResponseWriter = buffer
+	shadow.renderOptions.Encoding = ""
+	chain.ProcessFilter(req, &shadow)
+	shadow.closeCompressor()
+	resp.err = shadow.err
+	status := buffer.code
+	if !buffer.wroteHeader {
+		status = http.StatusOK
+	}
+	if status != http.StatusOK || !cacheable(buffer.header) {
+		buffer.copyTo(resp)
+		return
+	}
+	cached := &CachedResponse{
+		Status:       status,
+		Header:       buffer.header,
+		Body:         buffer.buffer.Bytes(),
+		ETag:         buffer.header.Get(HEADER_ETag),
+		LastModified: time.Now().UTC().Truncate(time.Second),
+	}
+	if len(cached.ETag) == 0 {
+		sum := sha256.Sum256(cached.Body)
+		cached.ETag = `"` + hex.EncodeToString(sum[:16]) + `"`
+	}
+	if modified, err := http.ParseTime(buffer.header.Get(HEADER_LastModified)); err == nil {
+		cached.LastModified = modified
+	}
+	c.Store.Set(key, cached, ttl)
+	cached.writeTo(req, resp)
+}
+
+// missingCacheStore makes the missing Store of a ResponseCache to be logged once.
+var missingCacheStore sync.Once
+
+// defaultResponseCacheKey separates the responses for each method, URL and representation.
+func defaultResponseCacheKey(req *Request) string {
+	return req.Request.Method + " " + req.Request.URL.RequestURI() + " " + req.Request.Header.Get(HEADER_Accept) + " " + req.Request.Header.Get(HEADER_AcceptEncoding)
+}
+
+// personalized returns true if the request has credentials, such that its response can differ per user.
+func personalized(httpRequest *http.Request) bool {
+	return len(httpRequest.Header.Get("Authorization")) > 0 || len(httpRequest.Header.Get("Cookie")) > 0
+}
+
+// cacheable returns false if the response header forbids keeping it in a shared cache
+// or if it sets a cookie, which must not be replayed to other clients.
+func cacheable(header http.Header) bool {
+	if len(header.Values("Set-Cookie")) > 0 {
+		return false
+	}
+	control := strings.ToLower(header.Get(HEADER_CacheControl))
+	return !strings.Contains(control, "no-store") && !strings.Contains(control, "private")
+}
+
+// writeTo writes the cached response, or 304 (Not Modified) if the request is conditional and matches.
+func (c *CachedResponse) writeTo(req *Request, resp *Response) {
+	header := resp.Header()
+	for k, v := range c.Header {
+		if k == "Content-Length" && len(resp.renderOptions.Encoding) > 0 {
+			continue
+		}
+		header[k] = append([]string(nil), v...)
+	}
+	header.Set(HEADER_ETag, c.ETag)
+	header.Set(HEADER_LastModified, c.LastModified.UTC().Format(http.TimeFormat))
+	if c.notModified(req.Request) {
+		header.Del(HEADER_ContentType)
+		header.Del("Content-Length")
+		resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+	resp.WriteHeader(c.Status)
+	resp.Write(c.Body)
+}
+
+// notModified evaluates If-None-Match or, if absent, If-Modified-Since.
+func (c *CachedResponse) notModified(httpRequest *http.Request) bool {
+	if match := httpRequest.Header.Get(HEADER_IfNoneMatch); len(match) > 0 {
+		return matchesETag(match, c.ETag, true)
+	}
+	since, err := http.ParseTime(httpRequest.Header.Get(HEADER_IfModifiedSince))
+	if err != nil {
+		return false
+	}
+	return !c.LastModified.Truncate(time.Second).After(since)
+}
+
+// lruResponseCacheStore is an in-memory ResponseCacheStore that evicts the least recently used entry.
+type lruResponseCacheStore struct {
+	lock     sync.Mutex
+	capacity int
+	order    *list.List // of *lruEntry, most recently used first
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	response *CachedResponse
+	expires  time.Time
+}
+
+// NewLRUResponseCacheStore returns an in-memory ResponseCacheStore that keeps at most capacity responses.
+func NewLRUResponseCacheStore(capacity int) ResponseCacheStore {
+	return &lruResponseCacheStore{capacity: capacity, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Get is part of ResponseCacheStore
+func (s *lruResponseCacheStore) Get(key string) (*CachedResponse, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		s.order.Remove(element)
+		delete(s.entries, key)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return entry.response, true
+}
+
+// Set is part of ResponseCacheStore
+func (s *lruResponseCacheStore) Set(key string, response *CachedResponse, ttl time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	entry := &lruEntry{key: key, response: response, expires: time.Now().Add(ttl)}
+	if element, ok := s.entries[key]; ok {
+		element.Value = entry
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(entry)
+	for s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*lruEntry).key)
+	}
+}
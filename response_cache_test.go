@@ -0,0 +1,140 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newCachedContainer(calls *int) *Container {
+	c := NewContainer()
+	cache := ResponseCache{Store: NewLRUResponseCacheStore(10), TTL: time.Minute}
+	ws := new(WebService).Path("/cached").Produces(MIME_JSON).Filter(cache.Filter)
+	ws.Route(ws.GET("/fruit").To(func(req *Request, resp *Response) {
+		*calls++
+		resp.WriteEntity(food{Kind: "apple"})
+	}))
+	ws.Route(ws.GET("/private").To(func(req *Request, resp *Response) {
+		*calls++
+		resp.Header().Set(HEADER_CacheControl, "private")
+		resp.WriteEntity(food{Kind: "secret"})
+	}))
+	ws.Route(ws.GET("/session").To(func(req *Request, resp *Response) {
+		*calls++
+		http.SetCookie(resp, &http.Cookie{Name: "session", Value: "42"})
+		resp.WriteEntity(food{Kind: "cookie"})
+	}))
+	ws.Route(ws.GET("/live").Metadata(KeyCacheTTL, time.Duration(0)).To(func(req *Request, resp *Response) {
+		*calls++
+		resp.WriteEntity(food{Kind: "fresh"})
+	}))
+	c.Add(ws)
+	return c
+}
+
+func cachedGet(c *Container, path string, header map[string]string) *httptest.ResponseRecorder {
+	httpRequest, _ := http.NewRequest("GET", path, nil)
+	httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+	for k, v := range header {
+		httpRequest.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httpRequest)
+	return recorder
+}
+
+// go test -v -test.run TestResponseCacheHit ...restful
+func TestResponseCacheHit(t *testing.T) {
+	calls := 0
+	c := newCachedContainer(&calls)
+	first := cachedGet(c, "/cached/fruit", nil)
+	etag := first.Header().Get(HEADER_ETag)
+	if len(etag) == 0 {
+		t.Fatal("missing ETag")
+	}
+	second := cachedGet(c, "/cached/fruit", nil)
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %v calls want %v", got, want)
+	}
+	if got, want := second.Body.String(), first.Body.String(); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := second.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	notModified := cachedGet(c, "/cached/fruit", map[string]string{HEADER_IfNoneMatch: `"other", ` + etag})
+	if got, want := notModified.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := notModified.Body.Len(); got != 0 {
+		t.Errorf("got body of %d bytes want none", got)
+	}
+	since := cachedGet(c, "/cached/fruit", map[string]string{HEADER_IfModifiedSince: time.Now().UTC().Add(time.Hour).Format(http.TimeFormat)})
+	if got, want := since.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := calls, 1; got != want {
+		t.Errorf("got %v calls want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestResponseCacheNotCached ...restful
+func TestResponseCacheNotCached(t *testing.T) {
+	calls := 0
+	c := newCachedContainer(&calls)
+	for _, path := range []string{"/cached/private", "/cached/session", "/cached/live"} {
+		calls = 0
+		cachedGet(c, path, nil)
+		recorder := cachedGet(c, path, nil)
+		if got, want := calls, 2; got != want {
+			t.Errorf("%s: got %v calls want %v", path, got, want)
+		}
+		if got, want := recorder.Code, http.StatusOK; got != want {
+			t.Errorf("%s: got %v want %v", path, got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestResponseCachePersonalized ...restful
+func TestResponseCachePersonalized(t *testing.T) {
+	calls := 0
+	c := newCachedContainer(&calls)
+	cachedGet(c, "/cached/fruit", nil)
+	for _, header := range []map[string]string{{"Authorization": "Bearer 42"}, {"Cookie": "session=42"}} {
+		cachedGet(c, "/cached/fruit", header)
+	}
+	if got, want := calls, 3; got != want {
+		t.Errorf("got %v calls want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestResponseCacheWithoutStore ...restful
+func TestResponseCacheWithoutStore(t *testing.T) {
+	c := NewContainer()
+	ws := new(WebService).Path("/uncached").Produces(MIME_JSON).Filter(ResponseCache{TTL: time.Minute}.Filter)
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { resp.WriteEntity(food{Kind: "apple"}) }))
+	c.Add(ws)
+	if got, want := cachedGet(c, "/uncached", nil).Code, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestLRUResponseCacheStore ...restful
+func TestLRUResponseCacheStore(t *testing.T) {
+	store := NewLRUResponseCacheStore(2)
+	store.Set("a", &CachedResponse{Status: 200}, time.Minute)
+	store.Set("b", &CachedResponse{Status: 200}, time.Minute)
+	store.Get("a")
+	store.Set("c", &CachedResponse{Status: 200}, time.Minute)
+	if _, ok := store.Get("b"); ok {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if _, ok := store.Get("a"); !ok {
+		t.Error("recently used entry should be kept")
+	}
+	store.Set("d", &CachedResponse{Status: 200}, -time.Second)
+	if _, ok := store.Get("d"); ok {
+		t.Error("expired entry should not be returned")
+	}
+}
@@ -2,15 +2,17 @@ package restful
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestWriteHeader(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteHeader(123)
 	if resp.StatusCode() != 123 {
 		t.Errorf("Unexpected status code:%d", resp.StatusCode())
@@ -19,7 +21,7 @@ func TestWriteHeader(t *testing.T) {
 
 func TestNoWriteHeader(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	if resp.StatusCode() != http.StatusOK {
 		t.Errorf("Unexpected status code:%d", resp.StatusCode())
 	}
@@ -32,7 +34,7 @@ type food struct {
 // go test -v -test.run TestMeasureContentLengthXml ...restful
 func TestMeasureContentLengthXml(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteAsXml(food{"apple"})
 	if resp.ContentLength() != 76 {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -42,7 +44,7 @@ func TestMeasureContentLengthXml(t *testing.T) {
 // go test -v -test.run TestMeasureContentLengthJson ...restful
 func TestMeasureContentLengthJson(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteAsJson(food{"apple"})
 	if resp.ContentLength() != 22 {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -52,7 +54,7 @@ func TestMeasureContentLengthJson(t *testing.T) {
 // go test -v -test.run TestMeasureContentLengthJsonNotPretty ...restful
 func TestMeasureContentLengthJsonNotPretty(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, false, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: false}}
 	resp.WriteAsJson(food{"apple"})
 	if resp.ContentLength() != 17 { // 16+1 using the Encoder directly yields another /n
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -62,7 +64,7 @@ func TestMeasureContentLengthJsonNotPretty(t *testing.T) {
 // go test -v -test.run TestMeasureContentLengthWriteErrorString ...restful
 func TestMeasureContentLengthWriteErrorString(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteErrorString(404, "Invalid")
 	if resp.ContentLength() != len("Invalid") {
 		t.Errorf("Incorrect measured length:%d", resp.ContentLength())
@@ -80,7 +82,7 @@ func TestStatusIsPassedToResponse(t *testing.T) {
 		{write: 400, read: 400},
 	} {
 		httpWriter := httptest.NewRecorder()
-		resp := Response{httpWriter, "*/*", []string{"*/*"}, 0, 0, true, nil}
+		resp := Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{"*/*"}, renderOptions: RenderOptions{PrettyPrint: true}}
 		resp.WriteHeader(each.write)
 		if got, want := httpWriter.Code, each.read; got != want {
 			t.Errorf("got %v want %v", got, want)
@@ -91,7 +93,7 @@ func TestStatusIsPassedToResponse(t *testing.T) {
 // go test -v -test.run TestStatusCreatedAndContentTypeJson_Issue54 ...restful
 func TestStatusCreatedAndContentTypeJson_Issue54(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "application/json", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/json", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteHeader(201)
 	resp.WriteAsJson(food{"Juicy"})
 	if httpWriter.HeaderMap.Get("Content-Type") != "application/json" {
@@ -113,7 +115,7 @@ func (e errorOnWriteRecorder) Write(bytes []byte) (int, error) {
 // go test -v -test.run TestLastWriteErrorCaught ...restful
 func TestLastWriteErrorCaught(t *testing.T) {
 	httpWriter := errorOnWriteRecorder{httptest.NewRecorder()}
-	resp := Response{httpWriter, "application/json", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/json", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	err := resp.WriteAsJson(food{"Juicy"})
 	if err.Error() != "fail" {
 		t.Errorf("Unexpected error message:%v", err)
@@ -124,7 +126,7 @@ func TestLastWriteErrorCaught(t *testing.T) {
 func TestAcceptStarStar_Issue83(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
 	//								Accept									Produces
-	resp := Response{httpWriter, "application/bogus,*/*;q=0.8", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/bogus,*/*;q=0.8", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteEntity(food{"Juicy"})
 	ct := httpWriter.Header().Get("Content-Type")
 	if "application/json" != ct {
@@ -136,7 +138,7 @@ func TestAcceptStarStar_Issue83(t *testing.T) {
 func TestAcceptSkipStarStar_Issue83(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
 	//								Accept									Produces
-	resp := Response{httpWriter, " application/xml ,*/* ; q=0.8", []string{"application/json", "application/xml"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: " application/xml ,*/* ; q=0.8", routeProduces: []string{"application/json", "application/xml"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteEntity(food{"Juicy"})
 	ct := httpWriter.Header().Get("Content-Type")
 	if "application/xml" != ct {
@@ -148,7 +150,7 @@ func TestAcceptSkipStarStar_Issue83(t *testing.T) {
 func TestAcceptXmlBeforeStarStar_Issue83(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
 	//								Accept									Produces
-	resp := Response{httpWriter, "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteEntity(food{"Juicy"})
 	ct := httpWriter.Header().Get("Content-Type")
 	if "application/json" != ct {
@@ -159,7 +161,7 @@ func TestAcceptXmlBeforeStarStar_Issue83(t *testing.T) {
 // go test -v -test.run TestWriteHeaderNoContent_Issue124 ...restful
 func TestWriteHeaderNoContent_Issue124(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "text/plain", []string{"text/plain"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "text/plain", routeProduces: []string{"text/plain"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteHeader(http.StatusNoContent)
 	if httpWriter.Code != http.StatusNoContent {
 		t.Errorf("got %d want %d", httpWriter.Code, http.StatusNoContent)
@@ -169,7 +171,7 @@ func TestWriteHeaderNoContent_Issue124(t *testing.T) {
 // go test -v -test.run TestStatusCreatedAndContentTypeJson_Issue163 ...restful
 func TestStatusCreatedAndContentTypeJson_Issue163(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "application/json", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/json", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteHeader(http.StatusNotModified)
 	if httpWriter.Code != http.StatusNotModified {
 		t.Errorf("Got %d want %d", httpWriter.Code, http.StatusNotModified)
@@ -178,7 +180,7 @@ func TestStatusCreatedAndContentTypeJson_Issue163(t *testing.T) {
 
 func TestWriteHeaderAndEntity_Issue235(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "application/json", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/json", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	var pong = struct {
 		Foo string `json:"foo"`
 	}{Foo: "123"}
@@ -196,9 +198,83 @@ func TestWriteHeaderAndEntity_Issue235(t *testing.T) {
 
 func TestWriteEntityNotAcceptable(t *testing.T) {
 	httpWriter := httptest.NewRecorder()
-	resp := Response{httpWriter, "application/bogus", []string{"application/json"}, 0, 0, true, nil}
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/bogus", routeProduces: []string{"application/json"}, renderOptions: RenderOptions{PrettyPrint: true}}
 	resp.WriteEntity("done")
 	if httpWriter.Code != http.StatusNotAcceptable {
 		t.Errorf("got %d want %d", httpWriter.Code, http.StatusNotAcceptable)
 	}
 }
+
+// go test -v -test.run TestRestrictProduces ...restful
+func TestRestrictProduces(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := Response{ResponseWriter: httpWriter, requestAccept: "application/json", routeProduces: []string{MIME_JSON, MIME_XML}}
+	resp.RestrictProduces(MIME_XML)
+	resp.WriteEntity(food{"apple"})
+	if got, want := httpWriter.Code, http.StatusNotAcceptable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	httpWriter = httptest.NewRecorder()
+	resp = Response{ResponseWriter: httpWriter, requestAccept: "application/json, application/xml", routeProduces: []string{MIME_JSON, MIME_XML}}
+	resp.RestrictProduces(MIME_XML)
+	resp.WriteEntity(food{"apple"})
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_XML; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	httpWriter = httptest.NewRecorder()
+	resp = Response{ResponseWriter: httpWriter, requestAccept: "*/*", routeProduces: []string{MIME_JSON, MIME_XML}}
+	resp.RestrictProduces(MIME_XML)
+	resp.WriteEntity(food{"apple"})
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_XML; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestResponseController ...restful
+func TestResponseController(t *testing.T) {
+	ws := new(WebService).Path("/stream")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		controller := http.NewResponseController(resp)
+		if err := controller.SetWriteDeadline(time.Now().Add(time.Minute)); err != nil {
+			t.Error(err)
+		}
+		resp.Write([]byte("tick"))
+		if err := controller.Flush(); err != nil {
+			t.Error(err)
+		}
+		if err := resp.Push("/style.css", nil); err != http.ErrNotSupported {
+			t.Errorf("got %v want %v", err, http.ErrNotSupported)
+		}
+	}))
+	server := httptest.NewServer(NewContainer().Add(ws))
+	defer server.Close()
+	response, err := http.Get(server.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	data, _ := io.ReadAll(response.Body)
+	if got, want := string(data), "tick"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestStatusCodeFirstWins ...restful
+func TestStatusCodeFirstWins(t *testing.T) {
+	httpWriter := httptest.NewRecorder()
+	resp := NewResponse(httpWriter)
+	resp.WriteHeader(http.StatusEarlyHints)
+	if resp.wroteHeader {
+		t.Error("informational status is not final")
+	}
+	resp.WriteHeader(http.StatusCreated)
+	resp.WriteHeader(http.StatusConflict)
+	if got, want := resp.StatusCode(), http.StatusCreated; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := resp.EncodedContentLength(), resp.ContentLength(); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,235 @@
+// Package restfultest provides a fluent client to test WebServices by dispatching requests
+// through a Container, without a network listener.
+//
+//	var user User
+//	err := restfultest.New(container).GET("/users/1").WithHeader("Accept", restful.MIME_JSON).
+//		Expect().Status(200).JSON(&user)
+//
+// Expectations that fail are collected and returned by JSON or Err. If the Client has a testing.TB,
+// see WithT, each failed expectation is also reported as a test error.
+package restfultest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Client creates requests that are dispatched by a Container.
+type Client struct {
+	container *restful.Container
+	header    http.Header
+	t         testing.TB
+}
+
+// New returns a Client for the container.
+func New(container *restful.Container) *Client {
+	return &Client{container: container, header: http.Header{}}
+}
+
+// WithT returns a copy of the Client that reports each failed expectation using t.Error.
+func (c *Client) WithT(t testing.TB) *Client {
+	copied := *c
+	copied.t = t
+	return &copied
+}
+
+// WithHeader returns a copy of the Client that adds the header to each request, e.g. for authorization.
+func (c *Client) WithHeader(name, value string) *Client {
+	copied := *c
+	copied.header = c.header.Clone()
+	copied.header.Add(name, value)
+	return &copied
+}
+
+// GET returns a GET request for the path, which can have a query.
+func (c *Client) GET(path string) *Request { return c.Request(http.MethodGet, path) }
+
+// HEAD returns a HEAD request for the path.
+func (c *Client) HEAD(path string) *Request { return c.Request(http.MethodHead, path) }
+
+// POST returns a POST request for the path.
+func (c *Client) POST(path string) *Request { return c.Request(http.MethodPost, path) }
+
+// PUT returns a PUT request for the path.
+func (c *Client) PUT(path string) *Request { return c.Request(http.MethodPut, path) }
+
+// PATCH returns a PATCH request for the path.
+func (c *Client) PATCH(path string) *Request { return c.Request(http.MethodPatch, path) }
+
+// DELETE returns a DELETE request for the path.
+func (c *Client) DELETE(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+// OPTIONS returns an OPTIONS request for the path.
+func (c *Client) OPTIONS(path string) *Request { return c.Request(http.MethodOptions, path) }
+
+// Request returns a request with the method for the path.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, header: c.header.Clone(), query: url.Values{}}
+}
+
+// Request is a request to be dispatched ; its methods return the same Request for chaining.
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+	err    error // of encoding the body
+}
+
+// WithHeader adds a header value.
+func (r *Request) WithHeader(name, value string) *Request {
+	r.header.Add(name, value)
+	return r
+}
+
+// WithQuery adds a query parameter value.
+func (r *Request) WithQuery(name, value string) *Request {
+	r.query.Add(name, value)
+	return r
+}
+
+// WithBody sets the body and its Content-Type.
+func (r *Request) WithBody(contentType string, body []byte) *Request {
+	r.header.Set(restful.HEADER_ContentType, contentType)
+	r.body = body
+	return r
+}
+
+// WithJSON sets the body to the JSON encoding of the value.
+func (r *Request) WithJSON(value interface{}) *Request {
+	data, err := json.Marshal(value)
+	if err != nil {
+		r.err = fmt.Errorf("unable to encode JSON body: %v", err)
+	}
+	return r.WithBody(restful.MIME_JSON, data)
+}
+
+// HTTPRequest returns the http.Request that is dispatched by Expect.
+func (r *Request) HTTPRequest() *http.Request {
+	target := r.path
+	if len(r.query) > 0 {
+		separator := "?"
+		if strings.Contains(target, "?") {
+			separator = "&"
+		}
+		target += separator + r.query.Encode()
+	}
+	httpRequest := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for name, values := range r.header {
+		httpRequest.Header[name] = values
+	}
+	return httpRequest
+}
+
+// Expect dispatches the request through the Container and returns the Response to check.
+func (r *Request) Expect() *Response {
+	recorder := httptest.NewRecorder()
+	response := &Response{Recorder: recorder, t: r.client.t, description: r.method + " " + r.path}
+	if r.err != nil {
+		response.fail(r.err)
+		return response
+	}
+	r.client.container.ServeHTTP(recorder, r.HTTPRequest())
+	return response
+}
+
+// Response is the result of a dispatched Request ; its expectation methods return the same Response for chaining.
+type Response struct {
+	// Recorder has the status, header and body as written by the Container.
+	Recorder    *httptest.ResponseRecorder
+	t           testing.TB
+	description string // method and path of the request
+	errs        []error
+}
+
+func (r *Response) fail(err error) {
+	err = fmt.Errorf("%s: %w", r.description, err)
+	r.errs = append(r.errs, err)
+	if r.t != nil {
+		r.t.Helper()
+		r.t.Error(err)
+	}
+}
+
+// Status expects the status code.
+func (r *Response) Status(code int) *Response {
+	if r.t != nil {
+		r.t.Helper()
+	}
+	if got := r.Recorder.Code; got != code {
+		r.fail(fmt.Errorf("got status %d want %d, body: %s", got, code, r.Recorder.Body.String()))
+	}
+	return r
+}
+
+// Header expects the header to have the value.
+func (r *Response) Header(name, value string) *Response {
+	if r.t != nil {
+		r.t.Helper()
+	}
+	if got := r.Recorder.Header().Get(name); got != value {
+		r.fail(fmt.Errorf("got header %s %q want %q", name, got, value))
+	}
+	return r
+}
+
+// ContentType expects the media type of the Content-Type header, ignoring parameters such as charset.
+func (r *Response) ContentType(mime string) *Response {
+	if r.t != nil {
+		r.t.Helper()
+	}
+	got := r.Recorder.Header().Get(restful.HEADER_ContentType)
+	if semicolon := strings.Index(got, ";"); semicolon != -1 {
+		got = got[:semicolon]
+	}
+	if strings.TrimSpace(got) != mime {
+		r.fail(fmt.Errorf("got content type %q want %q", got, mime))
+	}
+	return r
+}
+
+// BodyContains expects the body to contain the text.
+func (r *Response) BodyContains(text string) *Response {
+	if r.t != nil {
+		r.t.Helper()
+	}
+	if body := r.Recorder.Body.String(); !strings.Contains(body, text) {
+		r.fail(fmt.Errorf("got body %q want it to contain %q", body, text))
+	}
+	return r
+}
+
+// Body returns the body as written by the Container.
+func (r *Response) Body() []byte {
+	return r.Recorder.Body.Bytes()
+}
+
+// JSON decodes the body into the value and returns the failed expectations, if any, or the decoding error.
+func (r *Response) JSON(value interface{}) error {
+	if r.t != nil {
+		r.t.Helper()
+	}
+	if err := r.Err(); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(r.Recorder.Body.Bytes(), value); err != nil {
+		r.fail(fmt.Errorf("unable to decode JSON body: %v", err))
+	}
+	return r.Err()
+}
+
+// Err returns the failed expectations, if any.
+func (r *Response) Err() error {
+	return errors.Join(r.errs...)
+}
@@ -0,0 +1,68 @@
+package restfultest
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func newContainer() *restful.Container {
+	c := restful.NewContainer()
+	ws := new(restful.WebService).Path("/users").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		if req.Request.Header.Get("Authorization") != "Bearer secret" {
+			resp.WriteErrorString(http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		resp.WriteEntity(user{ID: req.PathParameter("id"), Name: req.QueryParameter("name")})
+	}))
+	ws.Route(ws.POST("").To(func(req *restful.Request, resp *restful.Response) {
+		var u user
+		if err := req.ReadEntity(&u); err != nil {
+			resp.WriteError(http.StatusBadRequest, err)
+			return
+		}
+		resp.WriteHeaderAndEntity(http.StatusCreated, u)
+	}))
+	c.Add(ws)
+	return c
+}
+
+func TestClient(t *testing.T) {
+	client := New(newContainer()).WithT(t).WithHeader("Authorization", "Bearer secret")
+	var got user
+	err := client.GET("/users/1").WithQuery("name", "ann").WithHeader("Accept", restful.MIME_JSON).
+		Expect().Status(200).ContentType(restful.MIME_JSON).JSON(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (user{ID: "1", Name: "ann"}); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	client.POST("/users").WithJSON(user{ID: "2"}).Expect().Status(201).BodyContains(`"id": "2"`)
+}
+
+func TestClientFailedExpectations(t *testing.T) {
+	response := New(newContainer()).GET("/users/1").Expect().Status(200).Header("X-Missing", "value")
+	err := response.Err()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"GET /users/1: got status 401 want 200", "got header X-Missing"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("got %v want it to contain %q", err, want)
+		}
+	}
+	var u user
+	if response.JSON(&u) == nil {
+		t.Error("expected JSON to return the failed expectations")
+	}
+}
@@ -0,0 +1,92 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// KeyRetrySafe is the Route metadata key for a bool that declares whether a request for the Route
+// can be sent again after a failure without unintended effects. See RouteBuilder.RetrySafe.
+const KeyRetrySafe = "restful.retrySafe"
+
+// RetrySafe declares whether the operation of the Route is safe to retry, overriding the default of its method.
+// A POST that is protected by an Idempotency filter can be declared safe.
+//
+//	ws.Route(ws.POST("/payments").To(pay).Filter(idempotency.Filter).RetrySafe(true))
+func (b *RouteBuilder) RetrySafe(safe bool) *RouteBuilder {
+	return b.Metadata(KeyRetrySafe, safe)
+}
+
+// IsRetrySafe returns the KeyRetrySafe metadata of the Route or, if absent, whether its method
+// is idempotent: GET, HEAD, OPTIONS, TRACE, PUT and DELETE are, POST and PATCH are not.
+func (r Route) IsRetrySafe() bool {
+	if safe, ok := r.Metadata[KeyRetrySafe].(bool); ok {
+		return safe
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// RetryAdvisor is used to create a Filter that writes the headers clients and gateways need to retry correctly:
+//
+//   - RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset, from the RateLimitStatus set by a RateLimiter ;
+//   - Retry-After on 429 (Too Many Requests) and 503 (Service Unavailable) responses that have none,
+//     e.g. those of a WorkerPool or MemoryBudget ;
+//   - Retry-After on other 5xx responses, only if the selected Route is retry safe.
+//
+// The headers are written when the response is committed, so its Filter must run before the filters that reject requests.
+//
+//	restful.Filter(restful.RetryAdvisor{}.Filter)
+//	restful.Filter(limiter.Filter)
+type RetryAdvisor struct {
+	// RetryAfter is the value of the Retry-After headers that are added ; default is 1 second.
+	// Retry-After headers written by a RateLimiter or ConcurrencyLimiter are kept.
+	RetryAfter time.Duration
+}
+
+// Filter registers the writing of the headers and continues the chain.
+// If nothing was written then the headers are written for the implicit 200 (OK).
+func (a RetryAdvisor) Filter(req *Request, resp *Response, chain *FilterChain) {
+	resp.commitHooks = append(resp.commitHooks, func(committing *Response, status int) {
+		a.writeHeaders(req, committing, status)
+	})
+	chain.ProcessFilter(req, resp)
+	if !resp.committed {
+		a.writeHeaders(req, resp, http.StatusOK)
+	}
+}
+
+func (a RetryAdvisor) writeHeaders(req *Request, resp *Response, status int) {
+	header := resp.Header()
+	if limit, ok := GetAttr(req, RateLimitStatusKey); ok {
+		header.Set(HEADER_RateLimitLimit, strconv.Itoa(limit.Limit))
+		header.Set(HEADER_RateLimitRemaining, strconv.Itoa(limit.Remaining))
+		header.Set(HEADER_RateLimitReset, strconv.Itoa(int(math.Ceil(limit.Reset.Seconds()))))
+	}
+	if len(header.Get(HEADER_RetryAfter)) > 0 {
+		return
+	}
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusServiceUnavailable:
+	case status >= 500 && status <= 599:
+		if route := req.SelectedRoute(); route == nil || !route.IsRetrySafe() {
+			return
+		}
+	default:
+		return
+	}
+	retryAfter := a.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	header.Set(HEADER_RetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+}
@@ -0,0 +1,97 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestRouteIsRetrySafe ...restful
+func TestRouteIsRetrySafe(t *testing.T) {
+	ws := new(WebService).Path("/orders")
+	ws.Route(ws.GET("").To(dummy))
+	ws.Route(ws.POST("").To(dummy))
+	ws.Route(ws.PUT("/{id}").To(dummy))
+	ws.Route(ws.POST("/{id}/cancel").To(dummy).RetrySafe(true))
+	ws.Route(ws.DELETE("/{id}").To(dummy).RetrySafe(false))
+	want := []bool{true, false, true, true, false}
+	for i, each := range ws.Routes() {
+		if got := each.IsRetrySafe(); got != want[i] {
+			t.Errorf("%s %s: got %v want %v", each.Method, each.Path, got, want[i])
+		}
+	}
+}
+
+// go test -v -test.run TestRetryAdvisor ...restful
+func TestRetryAdvisor(t *testing.T) {
+	container := NewContainer()
+	container.Filter(RetryAdvisor{RetryAfter: 1500 * time.Millisecond}.Filter)
+	fail := func(status int) RouteFunction {
+		return func(req *Request, resp *Response) { resp.WriteHeader(status) }
+	}
+	ws := new(WebService).Path("/jobs")
+	ws.Route(ws.GET("/busy").To(fail(http.StatusServiceUnavailable)))
+	ws.Route(ws.GET("/broken").To(fail(http.StatusBadGateway)))
+	ws.Route(ws.POST("/broken").To(fail(http.StatusBadGateway)))
+	ws.Route(ws.GET("/fine").To(fail(http.StatusOK)))
+	ws.Route(ws.GET("/limited").To(func(req *Request, resp *Response) {
+		resp.Header().Set(HEADER_RetryAfter, "30")
+		resp.WriteHeader(http.StatusTooManyRequests)
+	}))
+	container.Add(ws)
+
+	for _, each := range []struct {
+		method, path, retryAfter string
+	}{
+		{"GET", "/jobs/busy", "2"},
+		{"GET", "/jobs/broken", "2"},
+		{"POST", "/jobs/broken", ""},
+		{"GET", "/jobs/fine", ""},
+		{"GET", "/jobs/limited", "30"},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, "http://here.com"+each.path, nil)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Header().Get(HEADER_RetryAfter), each.retryAfter; got != want {
+			t.Errorf("%s %s: got Retry-After %q want %q", each.method, each.path, got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestRetryAdvisorRateLimitHeaders ...restful
+func TestRetryAdvisorRateLimitHeaders(t *testing.T) {
+	container := NewContainer()
+	container.Filter(RetryAdvisor{}.Filter)
+	container.Filter(NewRateLimiter(RateLimitPolicy{RequestsPerSecond: 1, Burst: 2}).Filter)
+	ws := new(WebService).Path("/limited")
+	ws.Route(ws.GET("").To(dummy))
+	container.Add(ws)
+
+	for i, want := range []struct {
+		code                      int
+		limit, remaining, pending string
+	}{
+		{200, "2", "1", "1"},
+		{200, "2", "0", "2"},
+		{429, "2", "0", "2"},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/limited", nil)
+		httpRequest.RemoteAddr = "10.0.0.1:1234"
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		header := httpWriter.Header()
+		if got := httpWriter.Code; got != want.code {
+			t.Errorf("request %d: got status %d want %d", i, got, want.code)
+		}
+		if got := header.Get(HEADER_RateLimitLimit); got != want.limit {
+			t.Errorf("request %d: got limit %q want %q", i, got, want.limit)
+		}
+		if got := header.Get(HEADER_RateLimitRemaining); got != want.remaining {
+			t.Errorf("request %d: got remaining %q want %q", i, got, want.remaining)
+		}
+		if got := header.Get(HEADER_RateLimitReset); got != want.pending {
+			t.Errorf("request %d: got reset %q want %q", i, got, want.pending)
+		}
+	}
+}
@@ -25,30 +25,55 @@ type Route struct {
 	// cached values for dispatching
 	relativePath string
 	pathParts    []string
+	template     *routeTemplate  // cached compilation of pathParts for matching and extracting parameters
 	pathExpr     *pathExpression // cached compilation of relativePath as RegExp
 
+	// derivedOperation is true if the Operation is the name of the Function, not set with RouteBuilder.Operation
+	derivedOperation bool
+
 	// documentation
 	Doc                     string
 	Notes                   string
 	Operation               string
 	ParameterDocs           []*Parameter
 	ResponseErrors          map[int]ResponseError
-	ReadSample, WriteSample interface{} // structs that model an example request or response payload
+	ResponseHeaders         []ResponseHeader
+	ParameterDependencies   []ParameterDependency // relationships between query or header parameters
+	ReadSample, WriteSample interface{}           // structs that model an example request or response payload
+
+	// Metadata is a map of arbitrary (per Route) values that can be consumed by filters and extensions
+	Metadata map[string]interface{}
+}
+
+// KeyExcludeFromDocumentation is the Route metadata key for a bool that, if true, leaves the Route out of
+// generated API documentation such as Swagger and collections, e.g. for health endpoints.
+const KeyExcludeFromDocumentation = "restful.excludeFromDocumentation"
+
+// ExcludedFromDocumentation returns whether the Route has KeyExcludeFromDocumentation metadata set to true.
+func (r Route) ExcludedFromDocumentation() bool {
+	exclude, _ := r.Metadata[KeyExcludeFromDocumentation].(bool)
+	return exclude
 }
 
 // Initialize for Route
 func (r *Route) postBuild() {
 	r.pathParts = tokenizePath(r.Path)
+	r.template = newRouteTemplate(r.pathParts)
 }
 
 // Create Request and Response from their http versions
 func (r *Route) wrapRequestResponse(httpWriter http.ResponseWriter, httpRequest *http.Request) (*Request, *Response) {
-	params := r.extractParameters(httpRequest.URL.Path)
-	wrappedRequest := NewRequest(httpRequest)
-	wrappedRequest.pathParameters = params
+	return r.wrapInto(NewRequest(httpRequest), NewResponse(httpWriter))
+}
+
+// wrapInto sets the Route specific fields of a new Request and Response and returns them.
+func (r *Route) wrapInto(wrappedRequest *Request, wrappedResponse *Response) (*Request, *Response) {
+	httpRequest := wrappedRequest.Request
+	wrappedRequest.pathParameters = r.extractParameters(httpRequest.URL.Path)
 	wrappedRequest.selectedRoutePath = r.Path
-	wrappedResponse := NewResponse(httpWriter)
+	wrappedRequest.selectedRoute = r
 	wrappedResponse.requestAccept = httpRequest.Header.Get(HEADER_Accept)
+	wrappedResponse.requestAcceptLanguage = httpRequest.Header.Get(HEADER_AcceptLanguage)
 	wrappedResponse.routeProduces = r.Produces
 	return wrappedRequest, wrappedResponse
 }
@@ -66,26 +91,27 @@ func (r *Route) dispatchWithFilters(wrappedRequest *Request, wrappedResponse *Re
 
 // Return whether the mimeType matches to what this Route can produce.
 func (r Route) matchesAccept(mimeTypesWithQuality string) bool {
-	parts := strings.Split(mimeTypesWithQuality, ",")
-	for _, each := range parts {
-		var withoutQuality string
-		if strings.Contains(each, ";") {
-			withoutQuality = strings.Split(each, ";")[0]
-		} else {
-			withoutQuality = each
-		}
-		// trim before compare
-		withoutQuality = strings.Trim(withoutQuality, " ")
-		if withoutQuality == "*/*" {
-			return true
-		}
-		for _, producibleType := range r.Produces {
-			if producibleType == "*/*" || producibleType == withoutQuality {
-				return true
+	quality, _ := r.acceptPreference(ParseAccept(mimeTypesWithQuality))
+	return quality > 0
+}
+
+// acceptPreference returns the highest quality, and the specificity of its media range, that the Accept
+// media ranges give to what this Route can produce. A Route without Produces is only acceptable for a */* media range.
+func (r Route) acceptPreference(ranges []MediaRange) (quality float64, specificity int) {
+	if len(r.Produces) == 0 {
+		for _, each := range ranges {
+			if each.Type == "*" && each.Subtype == "*" && each.Quality > quality {
+				quality, specificity = each.Quality, 1
 			}
 		}
+		return quality, specificity
 	}
-	return false
+	for _, each := range r.Produces {
+		if q, s := preferenceOf(ranges, strings.TrimSpace(strings.Split(each, ";")[0])); morePreferred(q, s, quality, specificity) {
+			quality, specificity = q, s
+		}
+	}
+	return quality, specificity
 }
 
 // Return whether this Route can consume content with a type specified by mimeTypes (can be empty).
@@ -125,35 +151,17 @@ func (r Route) matchesContentType(mimeTypes string) bool {
 	return false
 }
 
-// Extract the parameters from the request url path
+// Extract the parameters from the request url path ; nil if the Route has none
 func (r Route) extractParameters(urlPath string) map[string]string {
-	urlParts := tokenizePath(urlPath)
-	pathParameters := map[string]string{}
-	for i, key := range r.pathParts {
-		var value string
-		if i >= len(urlParts) {
-			value = ""
-		} else {
-			value = urlParts[i]
-		}
-		if strings.HasPrefix(key, "{") { // path-parameter
-			if colon := strings.Index(key, ":"); colon != -1 {
-				// extract by regex
-				regPart := key[colon+1 : len(key)-1]
-				keyPart := key[1:colon]
-				if regPart == "*" {
-					pathParameters[keyPart] = untokenizePath(i, urlParts)
-					break
-				} else {
-					pathParameters[keyPart] = value
-				}
-			} else {
-				// without enclosing {}
-				pathParameters[key[1:len(key)-1]] = value
-			}
-		}
+	return r.compiledTemplate().extract(tokenizePath(urlPath))
+}
+
+// compiledTemplate returns the template compiled by postBuild or, for a Route that was not built, compiles it.
+func (r Route) compiledTemplate() *routeTemplate {
+	if r.template != nil {
+		return r.template
 	}
-	return pathParameters
+	return newRouteTemplate(tokenizePath(r.Path))
 }
 
 // Untokenize back into an URL path using the slash separator
@@ -22,6 +22,7 @@ type RouteBuilder struct {
 	httpMethod  string        // required
 	function    RouteFunction // required
 	filters     []FilterFunction
+	priorities  []int // priority of each of the filters
 	// documentation
 	doc                     string
 	notes                   string
@@ -29,6 +30,9 @@ type RouteBuilder struct {
 	readSample, writeSample interface{}
 	parameters              []*Parameter
 	errorMap                map[int]ResponseError
+	responseHeaders         []ResponseHeader
+	parameterDependencies   []ParameterDependency
+	metadata                map[string]interface{}
 }
 
 // Do evaluates each argument with the RouteBuilder itself.
@@ -157,12 +161,35 @@ func (b *RouteBuilder) Returns(code int, message string, model interface{}) *Rou
 	return b
 }
 
+// ReturnsHeader allows you to document a header that responses can have, e.g. for pagination or rate limits.
+// The CORS filter exposes these headers to browser clients (see CrossOriginResourceSharing).
+func (b *RouteBuilder) ReturnsHeader(name, description string) *RouteBuilder {
+	b.responseHeaders = append(b.responseHeaders, ResponseHeader{Name: name, Description: description})
+	return b
+}
+
+// Metadata adds or updates a key=value pair to the metadata map of the Route.
+// Metadata can be consumed by filters and extensions, e.g. to declare per Route policies.
+func (b *RouteBuilder) Metadata(key string, value interface{}) *RouteBuilder {
+	if b.metadata == nil {
+		b.metadata = map[string]interface{}{}
+	}
+	b.metadata[key] = value
+	return b
+}
+
 type ResponseError struct {
 	Code    int
 	Message string
 	Model   interface{}
 }
 
+// ResponseHeader documents a header of the responses of a Route.
+type ResponseHeader struct {
+	Name        string
+	Description string
+}
+
 func (b *RouteBuilder) servicePath(path string) *RouteBuilder {
 	b.rootPath = path
 	return b
@@ -170,7 +197,13 @@ func (b *RouteBuilder) servicePath(path string) *RouteBuilder {
 
 // Filter appends a FilterFunction to the end of filters for this Route to build.
 func (b *RouteBuilder) Filter(filter FilterFunction) *RouteBuilder {
-	b.filters = append(b.filters, filter)
+	return b.FilterWithPriority(DefaultFilterPriority, filter)
+}
+
+// FilterWithPriority adds a FilterFunction that is called before all filters for this Route with a higher priority
+// and after those with a lower or equal priority.
+func (b *RouteBuilder) FilterWithPriority(priority int, filter FilterFunction) *RouteBuilder {
+	b.filters, b.priorities = insertFilter(b.filters, b.priorities, filter, priority)
 	return b
 }
 
@@ -203,21 +236,25 @@ func (b *RouteBuilder) Build() Route {
 		operationName = nameOfFunction(b.function)
 	}
 	route := Route{
-		Method:         b.httpMethod,
-		Path:           concatPath(b.rootPath, b.currentPath),
-		Produces:       b.produces,
-		Consumes:       b.consumes,
-		Function:       b.function,
-		Filters:        b.filters,
-		relativePath:   b.currentPath,
-		pathExpr:       pathExpr,
-		Doc:            b.doc,
-		Notes:          b.notes,
-		Operation:      operationName,
-		ParameterDocs:  b.parameters,
-		ResponseErrors: b.errorMap,
-		ReadSample:     b.readSample,
-		WriteSample:    b.writeSample}
+		Method:                b.httpMethod,
+		Path:                  concatPath(b.rootPath, b.currentPath),
+		Produces:              b.produces,
+		Consumes:              b.consumes,
+		Function:              b.function,
+		Filters:               b.filters,
+		relativePath:          b.currentPath,
+		pathExpr:              pathExpr,
+		Doc:                   b.doc,
+		Notes:                 b.notes,
+		Operation:             operationName,
+		ParameterDocs:         b.parameters,
+		ResponseErrors:        b.errorMap,
+		ResponseHeaders:       b.responseHeaders,
+		ParameterDependencies: b.parameterDependencies,
+		ReadSample:            b.readSample,
+		WriteSample:           b.writeSample,
+		Metadata:              b.metadata}
+	route.derivedOperation = len(b.operation) == 0
 	route.postBuild()
 	return route
 }
@@ -56,3 +56,15 @@ func TestRouteBuilder(t *testing.T) {
 		t.Error("Operation not set")
 	}
 }
+
+func TestRouteBuilder_ReturnsHeader(t *testing.T) {
+	b := new(RouteBuilder)
+	b.To(dummy).ReturnsHeader("X-Total-Count", "number of items")
+	r := b.Build()
+	if got, want := len(r.ResponseHeaders), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := r.ResponseHeaders[0].Description, "number of items"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,105 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// RouteSelection describes which Route a Container would select for a Http request.
+// It is meant for diagnosing shadowed or unreachable Routes.
+type RouteSelection struct {
+	Method         string            `json:"method"`
+	Path           string            `json:"path"`
+	WebService     string            `json:"webService,omitempty"` // root path of the selected WebService
+	Route          string            `json:"route,omitempty"`      // path template of the selected Route
+	Operation      string            `json:"operation,omitempty"`
+	PathParameters map[string]string `json:"pathParameters,omitempty"`
+	Filters        []string          `json:"filters,omitempty"` // container, webservice and route filters in order of invocation
+	Status         int               `json:"status"`            // Http status that a request would get if no Route was selected
+	Error          string            `json:"error,omitempty"`
+}
+
+// ExplainRouteSelection returns which WebService and Route would handle the Http request,
+// the path parameters that would be extracted and the filters that would run.
+// The request is not dispatched ; no filter or RouteFunction is called.
+func (c *Container) ExplainRouteSelection(httpRequest *http.Request) RouteSelection {
+	selection := RouteSelection{
+		Method: httpRequest.Method,
+		Path:   httpRequest.URL.Path,
+		Status: http.StatusOK}
+	c.webServicesLock.RLock()
+	webService, route, err := c.router.SelectRoute(c.webServices, httpRequest)
+	c.webServicesLock.RUnlock()
+	selection.Filters = namesOfFilters(c.containerFilters)
+	if webService != nil {
+		selection.WebService = webService.RootPath()
+	}
+	if err != nil {
+		selection.Status = http.StatusInternalServerError
+		if serviceError, ok := err.(ServiceError); ok {
+			selection.Status = serviceError.Code
+		}
+		selection.Error = err.Error()
+		return selection
+	}
+	selection.Filters = append(selection.Filters, namesOfFilters(webService.filters)...)
+	selection.Filters = append(selection.Filters, namesOfFilters(route.Filters)...)
+	selection.Route = route.Path
+	selection.Operation = route.Operation
+	selection.PathParameters = route.extractParameters(httpRequest.URL.Path)
+	return selection
+}
+
+// RouteSelectionHandler returns a http.Handler that writes the RouteSelection, as JSON, for
+// the method and path given by the query parameters "method" (default GET) and "path".
+// The optional query parameters "accept" and "contentType" are used as the corresponding request headers.
+// It is not registered by default ; use e.g. container.Handle("/debug/routes", container.RouteSelectionHandler())
+func (c *Container) RouteSelectionHandler() http.Handler {
+	return http.HandlerFunc(func(httpWriter http.ResponseWriter, httpRequest *http.Request) {
+		query := httpRequest.URL.Query()
+		method := query.Get("method")
+		if len(method) == 0 {
+			method = "GET"
+		}
+		path := query.Get("path")
+		if len(path) == 0 {
+			http.Error(httpWriter, "missing query parameter: path", http.StatusBadRequest)
+			return
+		}
+		probe, err := http.NewRequest(strings.ToUpper(method), path, nil)
+		if err != nil {
+			http.Error(httpWriter, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if accept := query.Get("accept"); len(accept) > 0 {
+			probe.Header.Set(HEADER_Accept, accept)
+		}
+		if contentType := query.Get("contentType"); len(contentType) > 0 {
+			probe.Header.Set(HEADER_ContentType, contentType)
+		}
+		output, err := json.MarshalIndent(c.ExplainRouteSelection(probe), "", " ")
+		if err != nil {
+			http.Error(httpWriter, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		httpWriter.Header().Set(HEADER_ContentType, MIME_JSON)
+		httpWriter.Write(output)
+	})
+}
+
+// qualifiedNameOfFunction returns the package qualified name of the function f.
+// It uses a runtime feature for debugging ; its value may change for later Go versions.
+func qualifiedNameOfFunction(f interface{}) string {
+	fun := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fun == nil {
+		return "?"
+	}
+	return strings.TrimSuffix(fun.Name(), "-fm")
+}
@@ -0,0 +1,61 @@
+package restful
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// go test -v -test.run TestExplainRouteSelection ...restful
+func TestExplainRouteSelection(t *testing.T) {
+	container := NewContainer()
+	container.Filter(globalFilter)
+	ws := new(WebService).Path("/users").Filter(serviceFilter)
+	ws.Route(ws.GET("/{id}").To(dummy).Filter(routeFilter))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/users/42", nil)
+	selection := container.ExplainRouteSelection(httpRequest)
+	if got, want := selection.Route, "/users/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := selection.PathParameters["id"], "42"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(selection.Filters), 3; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if !strings.HasSuffix(selection.Filters[2], ".routeFilter") {
+		t.Errorf("unexpected filter name %v", selection.Filters[2])
+	}
+
+	httpRequest, _ = http.NewRequest("POST", "/users/42", nil)
+	selection = container.ExplainRouteSelection(httpRequest)
+	if got, want := selection.Status, http.StatusMethodNotAllowed; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := len(selection.Filters), 1; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestRouteSelectionHandler(t *testing.T) {
+	container := NewContainer()
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy))
+	container.Add(ws)
+	container.Handle("/debug/routes", container.RouteSelectionHandler())
+
+	httpRequest, _ := http.NewRequest("GET", "/debug/routes?path=/users/7", nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	var selection RouteSelection
+	if err := json.Unmarshal(httpWriter.Body.Bytes(), &selection); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := selection.Operation, "dummy"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
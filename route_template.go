@@ -0,0 +1,115 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"regexp"
+	"strings"
+)
+
+// routeTemplate is the path of a Route compiled once such that requests are matched, and their path parameters
+// extracted, without inspecting the tokens of the path or compiling regular expressions again.
+type routeTemplate struct {
+	segments         []templateSegment
+	paramCount       int
+	staticCount      int
+	acceptsRemainder bool // the last token ends with *} ; a request path can have more tokens
+}
+
+// templateSegment is a token of a Route path: a literal or a parameter, e.g. "users", "{id}" or "{zip:[0-9]+}".
+type templateSegment struct {
+	literal  string         // if not a parameter
+	name     string         // of the parameter ; empty if a literal
+	pattern  *regexp.Regexp // of a {name:expression} parameter ; nil if none or if the expression is invalid
+	invalid  bool           // the expression does not compile ; the segment matches nothing
+	wildcard bool           // {name:*} matches the remainder of the request path
+}
+
+// newRouteTemplate compiles the tokens of a Route path, see tokenizePath.
+func newRouteTemplate(tokens []string) *routeTemplate {
+	template := &routeTemplate{segments: make([]templateSegment, len(tokens))}
+	for i, each := range tokens {
+		if !strings.HasPrefix(each, "{") {
+			template.segments[i].literal = each
+			template.staticCount++
+			continue
+		}
+		template.paramCount++
+		segment := &template.segments[i]
+		colon := strings.Index(each, ":")
+		if colon == -1 {
+			// without enclosing {}
+			segment.name = each[1 : len(each)-1]
+			continue
+		}
+		segment.name = each[1:colon]
+		expression := each[colon+1 : len(each)-1]
+		if expression == "*" {
+			segment.wildcard = true
+			continue
+		}
+		pattern, err := regexp.Compile(expression)
+		segment.pattern, segment.invalid = pattern, err != nil
+	}
+	if count := len(tokens); count > 0 {
+		template.acceptsRemainder = strings.HasSuffix(tokens[count-1], "*}")
+	}
+	return template
+}
+
+// match computes whether the request path tokens match, how many parameters do match
+// and what the number of static path elements is.
+func (t *routeTemplate) match(requestTokens []string) (matches bool, paramCount int, staticCount int) {
+	if len(t.segments) < len(requestTokens) && !t.acceptsRemainder {
+		return false, 0, 0
+	}
+	for i := range t.segments {
+		if i == len(requestTokens) {
+			// reached end of request path
+			return false, 0, 0
+		}
+		segment := &t.segments[i]
+		requestToken := requestTokens[i]
+		if len(segment.name) == 0 {
+			if requestToken != segment.literal {
+				return false, 0, 0
+			}
+			staticCount++
+			continue
+		}
+		paramCount++
+		if segment.wildcard {
+			break
+		}
+		if segment.invalid || (segment.pattern != nil && !segment.pattern.MatchString(requestToken)) {
+			return false, 0, 0
+		}
+	}
+	return true, paramCount, staticCount
+}
+
+// extract returns the path parameters of the request path tokens ; nil if the template has none.
+func (t *routeTemplate) extract(requestTokens []string) map[string]string {
+	if t.paramCount == 0 {
+		return nil
+	}
+	pathParameters := make(map[string]string, t.paramCount)
+	for i := range t.segments {
+		segment := &t.segments[i]
+		if len(segment.name) == 0 {
+			continue
+		}
+		if segment.wildcard {
+			pathParameters[segment.name] = untokenizePath(i, requestTokens)
+			break
+		}
+		if i < len(requestTokens) {
+			pathParameters[segment.name] = requestTokens[i]
+		} else {
+			pathParameters[segment.name] = ""
+		}
+	}
+	return pathParameters
+}
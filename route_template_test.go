@@ -0,0 +1,61 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestRouteTemplateCompilesOnce ...restful
+func TestRouteTemplateCompilesOnce(t *testing.T) {
+	template := newRouteTemplate(tokenizePath("/zip/{code:[0-9]{4}[A-Z]{2}}/{rest:*}"))
+	if template.segments[1].pattern == nil {
+		t.Fatal("expression not compiled")
+	}
+	if !template.segments[2].wildcard {
+		t.Error("wildcard not detected")
+	}
+	if matches, _, _ := template.match(tokenizePath("/zip/1234AB/a/b")); !matches {
+		t.Error("expected match")
+	}
+	if matches, _, _ := template.match(tokenizePath("/zip/12/a")); matches {
+		t.Error("unexpected match")
+	}
+	params := template.extract(tokenizePath("/zip/1234AB/a/b"))
+	if got, want := params["code"], "1234AB"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := params["rest"], "a/b"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+// go test -v -test.run TestRouteTemplateInvalidExpression ...restful
+func TestRouteTemplateInvalidExpression(t *testing.T) {
+	template := newRouteTemplate(tokenizePath("/a/{b:[}"))
+	if matches, _, _ := template.match(tokenizePath("/a/[")); matches {
+		t.Error("invalid expression must not match")
+	}
+}
+
+// go test -v -test.run TestCurlyRouterUsesBuiltTemplate ...restful
+func TestCurlyRouterUsesBuiltTemplate(t *testing.T) {
+	c := NewContainer()
+	c.Router(CurlyRouter{})
+	ws := new(WebService).Path("/zip")
+	ws.Route(ws.GET("/{code:[0-9]+}").To(func(req *Request, resp *Response) {
+		resp.Write([]byte(req.PathParameter("code")))
+	}))
+	c.Add(ws)
+	if ws.Routes()[0].template == nil {
+		t.Fatal("template not compiled when building the Route")
+	}
+	for path, want := range map[string]int{"/zip/1234": http.StatusOK, "/zip/abc": http.StatusNotFound} {
+		httpRequest, _ := http.NewRequest("GET", path, nil)
+		httpWriter := httptest.NewRecorder()
+		c.dispatch(httpWriter, httpRequest)
+		if got := httpWriter.Code; got != want {
+			t.Errorf("%s: got %d want %d", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// RouteVars publishes counters per Route using the expvar package such that
+// they are served by /debug/vars. It is used to create a Filter:
+//
+//	vars := restful.NewRouteVars("restful.routes")
+//	restful.Filter(vars.Filter)
+//
+// For each Route, keyed by its Operation if set with RouteBuilder.Operation or else by its method and path
+// (e.g. "GET /users/{id}"), it publishes
+// "hits" (number of requests), "errors" (number of responses with status >= 500)
+// and "lastErrorTime" (RFC 3339 time of the last error).
+type RouteVars struct {
+	routes *expvar.Map
+	lock   sync.Mutex // guards creating the vars of a route
+}
+
+// NewRouteVars creates RouteVars and publishes them under the given name.
+// Like expvar.Publish, it panics if the name is already in use.
+func NewRouteVars(name string) *RouteVars {
+	return &RouteVars{routes: expvar.NewMap(name)}
+}
+
+// Filter updates the counters of the selected Route after the rest of the chain has processed the request.
+func (v *RouteVars) Filter(req *Request, resp *Response, chain *FilterChain) {
+	chain.ProcessFilter(req, resp)
+	route := req.SelectedRoute()
+	if route == nil {
+		return
+	}
+	vars := v.varsOf(route)
+	vars.Add("hits", 1)
+	if resp.StatusCode() >= 500 {
+		vars.Add("errors", 1)
+		lastErrorTime := new(expvar.String)
+		lastErrorTime.Set(time.Now().UTC().Format(time.RFC3339))
+		vars.Set("lastErrorTime", lastErrorTime)
+	}
+}
+
+// varsOf returns the vars of the route, creating them if needed.
+func (v *RouteVars) varsOf(route *Route) *expvar.Map {
+	key := routeVarsKey(route)
+	if vars, ok := v.routes.Get(key).(*expvar.Map); ok {
+		return vars
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if vars, ok := v.routes.Get(key).(*expvar.Map); ok {
+		return vars
+	}
+	vars := new(expvar.Map).Init()
+	vars.Add("hits", 0)
+	vars.Add("errors", 0)
+	v.routes.Set(key, vars)
+	return vars
+}
+
+// routeVarsKey returns the Operation of the route if it was set explicitly, or else its method and path ;
+// a derived Operation is the name of the function (e.g. "func1"), which Routes can share.
+func routeVarsKey(route *Route) string {
+	if len(route.Operation) > 0 && !route.derivedOperation {
+		return route.Operation
+	}
+	return route.Method + " " + route.Path
+}
@@ -0,0 +1,56 @@
+package restful
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestRouteVars ...restful
+func TestRouteVars(t *testing.T) {
+	vars := NewRouteVars("restful.test.routes")
+	container := NewContainer()
+	container.Filter(vars.Filter)
+	ws := new(WebService).Path("/vars")
+	ws.Route(ws.GET("/ok").To(dummy).Operation("ok"))
+	ws.Route(ws.GET("/fail").To(func(req *Request, resp *Response) {
+		resp.WriteErrorString(http.StatusInternalServerError, "fail")
+	}).Operation("fail"))
+	container.Add(ws)
+
+	for _, each := range []string{"/vars/ok", "/vars/ok", "/vars/fail"} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+each, nil)
+		container.dispatch(httptest.NewRecorder(), httpRequest)
+	}
+	routes := expvar.Get("restful.test.routes").(*expvar.Map)
+	ok := routes.Get("ok").(*expvar.Map)
+	if got, want := ok.Get("hits").String(), "2"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := ok.Get("errors").String(), "0"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	fail := routes.Get("fail").(*expvar.Map)
+	if got, want := fail.Get("errors").String(), "1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if fail.Get("lastErrorTime") == nil {
+		t.Error("expected lastErrorTime")
+	}
+}
+
+func TestRouteVarsKey(t *testing.T) {
+	ws := new(WebService).Path("/users")
+	ws.Route(ws.GET("/{id}").To(dummy).Operation("getUser"))
+	ws.Route(ws.GET("/{id}/friends").To(dummy))
+	ws.Route(ws.GET("/{id}/orders").To(func(req *Request, resp *Response) {}))
+	for i, want := range []string{"getUser", "GET /users/{id}/friends", "GET /users/{id}/orders"} {
+		if got := routeVarsKey(&ws.Routes()[i]); got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+	if got, want := routeVarsKey(&Route{Method: "GET", Path: "/users/{id}"}), "GET /users/{id}"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
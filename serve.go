@@ -0,0 +1,143 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServeOptions configures Serve and ServeListeners.
+type ServeOptions struct {
+	// Server provides settings such as timeouts and TLSConfig ; its Handler is set to the Container.
+	// Default is a new http.Server.
+	Server *http.Server
+	// Context stops serving when done ; the Server is then shut down gracefully. Default is to serve until an error.
+	Context context.Context
+	// ShutdownTimeout limits the time for completing active requests at shutdown ; default is 10 seconds.
+	ShutdownTimeout time.Duration
+}
+
+// Serve accepts connections on the listener, e.g. created by ListenUnix, and dispatches their requests to the container.
+// See ServeListeners.
+func Serve(listener net.Listener, container *Container, options ServeOptions) error {
+	return ServeListeners([]net.Listener{listener}, container, options)
+}
+
+// ServeListeners accepts connections on all listeners, e.g. TCP and a Unix socket, using one http.Server
+// that dispatches requests to the container. It returns when serving on one of the listeners fails or
+// when the Context of the options is done ; in both cases the Server is shut down and all listeners are closed.
+// It returns nil if the Server was shut down by the Context or elsewhere.
+//
+//	listeners, _ := restful.SystemdListeners()
+//	log.Fatal(restful.ServeListeners(listeners, restful.DefaultContainer, restful.ServeOptions{}))
+func ServeListeners(listeners []net.Listener, container *Container, options ServeOptions) error {
+	if len(listeners) == 0 {
+		return errors.New("no listeners to serve")
+	}
+	server := options.Server
+	if server == nil {
+		server = new(http.Server)
+	}
+	server.Handler = container
+	failures := make(chan error, len(listeners))
+	for _, each := range listeners {
+		go func(listener net.Listener) {
+			failures <- server.Serve(listener)
+		}(each)
+	}
+	var done <-chan struct{}
+	if options.Context != nil {
+		done = options.Context.Done()
+	}
+	var err error
+	select {
+	case err = <-failures:
+	case <-done:
+	}
+	timeout := options.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if shutdownErr := server.Shutdown(ctx); err == nil {
+		err = shutdownErr
+	}
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+// ListenUnix returns a listener on the Unix domain socket at the path, e.g. for a sidecar.
+// A socket file that remains from an earlier run is removed first. If mode is not zero then
+// the permissions of the socket file are changed, e.g. to 0660 to give access to a group only.
+// The socket file is removed when the listener is closed.
+func ListenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			listener.Close()
+			return nil, err
+		}
+	}
+	return listener, nil
+}
+
+// listenFdsStart is the first file descriptor passed by systemd.
+const listenFdsStart = 3
+
+// SystemdListeners returns the listeners passed by systemd socket activation, in the order of the socket unit.
+// It returns no listeners and no error if the process was not activated by systemd.
+// The environment variables of the protocol are removed such that child processes do not use the sockets.
+func SystemdListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	listeners := []net.Listener{}
+	for i := 0; i < count; i++ {
+		fd := listenFdsStart + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && len(names[i]) > 0 {
+			name = names[i]
+		}
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file)
+		file.Close() // the listener has its own copy
+		if err != nil {
+			for _, each := range listeners {
+				each.Close()
+			}
+			return nil, fmt.Errorf("invalid systemd socket %s: %v", name, err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
@@ -0,0 +1,85 @@
+package restful
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestServeListeners ...restful
+func TestServeListeners(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	unix, err := ListenUnix(socket, 0660)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(socket); info.Mode().Perm() != 0660 {
+		t.Errorf("got %v want 0660", info.Mode().Perm())
+	}
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ws := new(WebService).Path("/ping")
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) { io.WriteString(resp, "pong") }))
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error)
+	go func() {
+		served <- ServeListeners([]net.Listener{unix, tcp}, NewContainer().Add(ws), ServeOptions{Context: ctx, ShutdownTimeout: time.Second})
+	}()
+
+	overUnix := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return new(net.Dialer).DialContext(ctx, "unix", socket)
+	}}}
+	for _, each := range []struct {
+		client *http.Client
+		url    string
+	}{
+		{overUnix, "http://sidecar/ping"},
+		{http.DefaultClient, "http://" + tcp.Addr().String() + "/ping"},
+	} {
+		response, err := each.client.Get(each.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(response.Body)
+		response.Body.Close()
+		if got, want := string(body), "pong"; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+	cancel()
+	if err := <-served; err != nil {
+		t.Errorf("got %v want nil", err)
+	}
+	if _, err := os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("socket file should be removed, got %v", err)
+	}
+}
+
+func TestListenUnixRemovesStaleSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	stale, _ := net.Listen("unix", socket)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	listener, err := ListenUnix(socket, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.Close()
+}
+
+func TestSystemdListenersNotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()+1))
+	t.Setenv("LISTEN_FDS", "1")
+	listeners, err := SystemdListeners()
+	if listeners != nil || err != nil {
+		t.Errorf("got %v,%v want none", listeners, err)
+	}
+}
@@ -0,0 +1,42 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import "net/http"
+
+// KeyPush is the Route metadata key for a []string of paths that are pushed, using HTTP/2 server push,
+// before the RouteFunction is called. See RouteBuilder.Push.
+const KeyPush = "restful.push"
+
+// Push declares the paths of related resources, e.g. scripts and stylesheets of an HTML page, that
+// are pushed to HTTP/2 clients before the RouteFunction writes the response.
+//
+//	ws.Route(ws.GET("/").To(index).Push("/static/app.js", "/static/app.css"))
+func (b *RouteBuilder) Push(paths ...string) *RouteBuilder {
+	return b.Metadata(KeyPush, paths)
+}
+
+// PushResources initiates an HTTP/2 server push for each of the paths. Call it before writing the response.
+// Because push is an optimization, it does nothing if the connection does not support it, e.g. for HTTP/1.1.
+// It returns the first error other than http.ErrNotSupported, e.g. when the client disabled push.
+func (r *Response) PushResources(paths ...string) error {
+	for _, each := range paths {
+		if err := r.Push(each, nil); err != nil && err != http.ErrNotSupported {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushDeclared pushes the paths of the KeyPush metadata of the Route, if any.
+func (r *Response) pushDeclared(route *Route) {
+	paths, ok := route.Metadata[KeyPush].([]string)
+	if !ok {
+		return
+	}
+	if err := r.PushResources(paths...); err != nil && trace {
+		traceLogger.Printf("unable to push resources of Route %s %s: %v", route.Method, route.Path, err)
+	}
+}
@@ -0,0 +1,54 @@
+package restful
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pushRecorder is a ResponseRecorder that supports HTTP/2 server push.
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+	err    error
+}
+
+func (p *pushRecorder) Push(target string, opts *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return p.err
+}
+
+// go test -v -test.run TestRoutePush ...restful
+func TestRoutePush(t *testing.T) {
+	ws := new(WebService).Path("/")
+	ws.Route(ws.GET("/index.html").Push("/static/app.js", "/static/app.css").To(func(req *Request, resp *Response) {
+		resp.Write([]byte("<html/>"))
+	}))
+	c := NewContainer().Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/index.html", nil)
+	httpWriter := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := strings.Join(httpWriter.pushed, ","), "/static/app.js,/static/app.css"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	// HTTP/1.1
+	recorder := httptest.NewRecorder()
+	c.ServeHTTP(recorder, httpRequest)
+	if got, want := recorder.Body.String(), "<html/>"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestPushResourcesError(t *testing.T) {
+	disabled := errors.New("push disabled")
+	resp := NewResponse(&pushRecorder{ResponseRecorder: httptest.NewRecorder(), err: disabled})
+	if got, want := resp.PushResources("/a.js"), disabled; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if err := NewResponse(httptest.NewRecorder()).PushResources("/a.js"); err != nil {
+		t.Errorf("got %v want nil", err)
+	}
+}
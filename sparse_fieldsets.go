@@ -0,0 +1,122 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// KeySparseFieldsets is the Route metadata key for the name (string) of the query parameter with which clients
+// select the members of the JSON objects in a response. See RouteBuilder.SparseFieldsets.
+const KeySparseFieldsets = "restful.sparseFieldsets"
+
+// SparseFieldsets lets clients reduce the size of the JSON responses of the Route by listing, comma separated,
+// the members to keep in the query parameter (default "fields"), e.g. GET /users?fields=id,name,address.city.
+// Objects in arrays are pruned alike, unknown names are ignored and error responses are written in full.
+// The parameter is added to the documentation of the Route.
+//
+//	ws.Route(ws.GET("/users").To(listUsers).Writes([]User{}).SparseFieldsets("fields"))
+func (b *RouteBuilder) SparseFieldsets(parameter string) *RouteBuilder {
+	if len(parameter) == 0 {
+		parameter = "fields"
+	}
+	b.Param(QueryParameter(parameter, "comma separated names of the fields to include in the response"))
+	return b.Metadata(KeySparseFieldsets, parameter)
+}
+
+// sparseFieldset returns the fields requested with the query parameter of the Route, if it has KeySparseFieldsets
+// metadata and the request has the parameter, or else the fields.
+func (r Route) sparseFieldset(httpRequest *http.Request, fields []string) []string {
+	parameter, ok := r.Metadata[KeySparseFieldsets].(string)
+	if !ok {
+		return fields
+	}
+	requested := []string{}
+	for _, each := range strings.Split(httpRequest.URL.Query().Get(parameter), ",") {
+		if each = strings.TrimSpace(each); len(each) > 0 {
+			requested = append(requested, each)
+		}
+	}
+	if len(requested) == 0 {
+		return fields
+	}
+	return requested
+}
+
+// sparseFields returns the Fields of the RenderOptions if the status is successful ; error entities are not pruned.
+func (r *Response) sparseFields(status int) []string {
+	if status < 200 || status > 299 {
+		return nil
+	}
+	return r.renderOptions.Fields
+}
+
+// pruneJSON returns the JSON document of the value with only the fields as members of its objects.
+func pruneJSON(v interface{}, fields []string) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	return newFieldTree(fields).prune(document), nil
+}
+
+// fieldTree has a subtree for each selected member ; a nil subtree selects the member as a whole.
+type fieldTree map[string]fieldTree
+
+// newFieldTree returns the tree of the dotted fields, e.g. "author.name".
+func newFieldTree(fields []string) fieldTree {
+	tree := fieldTree{}
+	for _, each := range fields {
+		node := tree
+		names := strings.Split(each, ".")
+		for i, name := range names {
+			subtree, seen := node[name]
+			if i == len(names)-1 {
+				node[name] = nil
+				break
+			}
+			if seen && subtree == nil {
+				break // already selected as a whole
+			}
+			if subtree == nil {
+				subtree = fieldTree{}
+				node[name] = subtree
+			}
+			node = subtree
+		}
+	}
+	return tree
+}
+
+// prune removes the members of the objects in the document that are not in the tree.
+func (t fieldTree) prune(document interface{}) interface{} {
+	switch value := document.(type) {
+	case map[string]interface{}:
+		for name, member := range value {
+			subtree, ok := t[name]
+			if !ok {
+				delete(value, name)
+				continue
+			}
+			if subtree != nil {
+				value[name] = subtree.prune(member)
+			}
+		}
+	case []interface{}:
+		for i, each := range value {
+			value[i] = t.prune(each)
+		}
+	}
+	return document
+}
@@ -0,0 +1,67 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type sparseAddress struct {
+	Street string `json:"street"`
+	City   string `json:"city"`
+}
+
+type sparseUser struct {
+	ID      int           `json:"id"`
+	Name    string        `json:"name"`
+	Email   string        `json:"email"`
+	Address sparseAddress `json:"address"`
+}
+
+// go test -v -test.run TestSparseFieldsets ...restful
+func TestSparseFieldsets(t *testing.T) {
+	PrettyPrintResponses = false
+	defer func() { PrettyPrintResponses = true }()
+	users := []sparseUser{
+		{1, "ann", "ann@here.com", sparseAddress{"main", "rome"}},
+		{2, "bob", "bob@here.com", sparseAddress{"side", "oslo"}},
+	}
+	container := NewContainer()
+	ws := new(WebService).Path("/users").Produces(MIME_JSON)
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(users)
+	}).SparseFieldsets(""))
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		if req.PathParameter("id") != "1" {
+			resp.WriteHeaderAndEntity(http.StatusNotFound, ServiceError{Code: 404, Message: "no such user"})
+			return
+		}
+		resp.WriteEntity(users[0])
+	}).SparseFieldsets("only"))
+	ws.Route(ws.GET("/{id}/full").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(users[0])
+	}))
+	container.Add(ws)
+
+	for _, each := range []struct {
+		url, body string
+	}{
+		{"/users?fields=id,address.city", `[{"address":{"city":"rome"},"id":1},{"address":{"city":"oslo"},"id":2}]`},
+		{"/users?fields=name,+unknown,,address.city,address", `[{"address":{"city":"rome","street":"main"},"name":"ann"},{"address":{"city":"oslo","street":"side"},"name":"bob"}]`},
+		{"/users/1?only=email", `{"email":"ann@here.com"}`},
+		{"/users/1?fields=email", `{"id":1,"name":"ann","email":"ann@here.com","address":{"street":"main","city":"rome"}}`},
+		{"/users/2?only=email", `{"Code":404,"Message":"no such user"}`},
+		{"/users/1/full?fields=email", `{"id":1,"name":"ann","email":"ann@here.com","address":{"street":"main","city":"rome"}}`},
+	} {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com"+each.url, nil)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := strings.TrimSpace(httpWriter.Body.String()), each.body; got != want {
+			t.Errorf("%s: got %s want %s", each.url, got, want)
+		}
+	}
+	if got, want := ws.Routes()[0].ParameterDocs[0].Data().Name, "fields"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// KeyStrictJSON is the Route metadata key that, if set to true, makes reading a JSON entity fail
+// if the document has fields that are not known to the entity type or is followed by more data.
+// The returned ServiceError (400) lists the path of all unknown fields, e.g. "unknown JSON fields: address.streeet, items[2].nmae".
+//
+//	ws.Route(ws.PUT("/{id}").To(update).Reads(User{}).Metadata(restful.KeyStrictJSON, true))
+const KeyStrictJSON = "restful.strictJSON"
+
+// isStrictJSON returns whether the Route that matched the request has KeyStrictJSON metadata set to true.
+func (r *Request) isStrictJSON() bool {
+	route := r.SelectedRoute()
+	if route == nil {
+		return false
+	}
+	strict, _ := route.Metadata[KeyStrictJSON].(bool)
+	return strict
+}
+
+// readStrictJSON decodes the body into v, rejecting unknown fields and trailing data.
+// If the decoder rejects the document then a second pass over it collects the paths of all unknown fields.
+func readStrictJSON(req *Request, v interface{}) error {
+	data, err := ioutil.ReadAll(req.Request.Body)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		var document interface{}
+		if json.Unmarshal(data, &document) != nil {
+			return err
+		}
+		if unknown := unknownJSONFields("", document, reflect.TypeOf(v)); len(unknown) > 0 {
+			sort.Strings(unknown)
+			return NewError(http.StatusBadRequest, "unknown JSON fields: "+strings.Join(unknown, ", "))
+		}
+		return err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return NewError(http.StatusBadRequest, "unexpected data after the JSON document")
+	}
+	return nil
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// unknownJSONFields returns the paths of the fields in the decoded document that have no
+// matching field in type t, using the same (case-insensitive) matching as encoding/json.
+func unknownJSONFields(path string, document interface{}, t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+	unknown := []string{}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := jsonFieldsOf(t)
+		for key, value := range object {
+			fieldType, ok := fields[key]
+			if !ok {
+				for name, each := range fields {
+					if strings.EqualFold(name, key) {
+						fieldType, ok = each, true
+						break
+					}
+				}
+			}
+			if !ok {
+				unknown = append(unknown, joinJSONPath(path, key))
+				continue
+			}
+			unknown = append(unknown, unknownJSONFields(joinJSONPath(path, key), value, fieldType)...)
+		}
+	case reflect.Map:
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for key, value := range object {
+			unknown = append(unknown, unknownJSONFields(joinJSONPath(path, key), value, t.Elem())...)
+		}
+	case reflect.Slice, reflect.Array:
+		array, ok := document.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, value := range array {
+			unknown = append(unknown, unknownJSONFields(path+"["+strconv.Itoa(i)+"]", value, t.Elem())...)
+		}
+	}
+	return unknown
+}
+
+// jsonFieldsOf returns the types of the exported fields of struct type t by their JSON name,
+// including those promoted from embedded structs.
+func jsonFieldsOf(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && len(name) == 0 {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for each, fieldType := range jsonFieldsOf(embedded) {
+					if _, ok := fields[each]; !ok {
+						fields[each] = fieldType
+					}
+				}
+				continue
+			}
+		}
+		if len(field.PkgPath) > 0 { // unexported
+			continue
+		}
+		if len(name) == 0 {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+func joinJSONPath(path, key string) string {
+	if len(path) == 0 {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,82 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type strictAddress struct {
+	Street string `json:"street"`
+}
+
+type strictBase struct {
+	ID string `json:"id"`
+}
+
+type strictUser struct {
+	strictBase
+	Name      string            `json:"name"`
+	Address   *strictAddress    `json:"address"`
+	Addresses []strictAddress   `json:"addresses"`
+	Labels    map[string]string `json:"labels"`
+	Ignored   string            `json:"-"`
+}
+
+// go test -v -test.run TestStrictJSON ...restful
+func TestStrictJSON(t *testing.T) {
+	var readErr error
+	var user strictUser
+	container := NewContainer()
+	ws := new(WebService).Path("/users").Consumes(MIME_JSON)
+	ws.Route(ws.PUT("/strict").To(func(req *Request, resp *Response) {
+		readErr = req.ReadEntity(&user)
+	}).Metadata(KeyStrictJSON, true))
+	ws.Route(ws.PUT("/lenient").To(func(req *Request, resp *Response) {
+		readErr = req.ReadEntity(&user)
+	}))
+	container.Add(ws)
+
+	for _, each := range []struct {
+		body, message string
+	}{
+		{`{"id":"1","Name":"joe","nmae":"x","address":{"streeet":"main"},"addresses":[{"street":"a"},{"stret":"b"}],"labels":{"k":"v"},"Ignored":"y"}`,
+			"unknown JSON fields: Ignored, address.streeet, addresses[1].stret, nmae"},
+		{`{"addresses":[{"street":"a"},{"stret":"b"}]}`, "unknown JSON fields: addresses[1].stret"},
+		{`{"id":"1"} {"id":"2"}`, "unexpected data after the JSON document"},
+	} {
+		httpRequest, _ := http.NewRequest("PUT", "http://here.com/users/strict", strings.NewReader(each.body))
+		httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+		container.dispatch(httptest.NewRecorder(), httpRequest)
+		serviceError, ok := readErr.(ServiceError)
+		if !ok {
+			t.Fatalf("%s: expected ServiceError, got %v", each.body, readErr)
+		}
+		if got, want := serviceError.Code, http.StatusBadRequest; got != want {
+			t.Errorf("%s: got %v want %v", each.body, got, want)
+		}
+		if got, want := serviceError.Message, each.message; got != want {
+			t.Errorf("%s: got %v want %v", each.body, got, want)
+		}
+	}
+
+	body := `{"id":"1","Name":"joe","address":{"street":"main"},"addresses":[{"street":"a"}],"labels":{"k":"v"}}`
+	httpRequest, _ := http.NewRequest("PUT", "http://here.com/users/strict", strings.NewReader(body))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if readErr != nil {
+		t.Errorf("unexpected error %v", readErr)
+	}
+
+	body = `{"id":"1","nmae":"x"}`
+	httpRequest, _ = http.NewRequest("PUT", "http://here.com/users/lenient", strings.NewReader(body))
+	httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+	container.dispatch(httptest.NewRecorder(), httpRequest)
+	if readErr != nil {
+		t.Errorf("unexpected error %v", readErr)
+	}
+	if got, want := user.ID, "1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
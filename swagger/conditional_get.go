@@ -0,0 +1,62 @@
+package swagger
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+)
+
+// writeJsonConditionally writes the value as JSON with an ETag computed from its content and
+// a Last-Modified set to the time the specification was built.
+// If the request has matching validators (If-None-Match or If-Modified-Since) then only 304 is written.
+func writeJsonConditionally(req *restful.Request, resp *restful.Response, builtAt time.Time, value interface{}) {
+	var output []byte
+	var err error
+	if resp.RenderOptions().PrettyPrint {
+		output, err = json.MarshalIndent(value, " ", " ")
+	} else {
+		output, err = json.Marshal(value)
+	}
+	if err != nil {
+		resp.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+	hash := sha1.Sum(output)
+	etag := `"` + hex.EncodeToString(hash[:]) + `"`
+	resp.Header().Set(restful.HEADER_ETag, etag)
+	resp.Header().Set(restful.HEADER_LastModified, builtAt.UTC().Format(http.TimeFormat))
+	if isNotModified(req.Request, etag, builtAt) {
+		resp.WriteHeader(http.StatusNotModified)
+		return
+	}
+	resp.Header().Set(restful.HEADER_ContentType, restful.MIME_JSON)
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(output)
+}
+
+// isNotModified returns whether the validators of the request match the current representation.
+// If-Modified-Since is only considered when If-None-Match is absent (RFC 7232, 3.3).
+func isNotModified(httpRequest *http.Request, etag string, builtAt time.Time) bool {
+	if inm := httpRequest.Header.Get(restful.HEADER_IfNoneMatch); len(inm) > 0 {
+		for _, each := range strings.Split(inm, ",") {
+			each = strings.TrimPrefix(strings.TrimSpace(each), "W/")
+			if each == etag || each == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	if ims := httpRequest.Header.Get(restful.HEADER_IfModifiedSince); len(ims) > 0 {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !builtAt.Truncate(time.Second).After(since)
+	}
+	return false
+}
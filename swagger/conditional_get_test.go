@@ -0,0 +1,62 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+// go test -v -test.run TestConditionalGetOfListing ...swagger
+func TestConditionalGetOfListing(t *testing.T) {
+	ws := new(restful.WebService).Path("/tests")
+	ws.Route(ws.GET("/a").To(dummy))
+	container := restful.NewContainer()
+	container.Add(ws)
+	RegisterSwaggerService(Config{ApiPath: "/apidocs.json", WebServices: container.RegisteredWebServices()}, container)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/apidocs.json", nil)
+	httpWriter := httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	etag := httpWriter.Header().Get(restful.HEADER_ETag)
+	if len(etag) == 0 {
+		t.Fatal("missing ETag")
+	}
+	lastModified := httpWriter.Header().Get(restful.HEADER_LastModified)
+	if len(lastModified) == 0 {
+		t.Fatal("missing Last-Modified")
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/apidocs.json", nil)
+	httpRequest.Header.Set(restful.HEADER_IfNoneMatch, etag)
+	httpWriter = httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusNotModified; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := httpWriter.Body.Len(); got != 0 {
+		t.Errorf("unexpected body length %d", got)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/apidocs.json/tests", nil)
+	httpRequest.Header.Set(restful.HEADER_IfNoneMatch, `"other"`)
+	httpWriter = httptest.NewRecorder()
+	container.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestIsNotModifiedSince(t *testing.T) {
+	builtAt, _ := http.ParseTime("Mon, 02 Jan 2006 15:04:05 GMT")
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	httpRequest.Header.Set(restful.HEADER_IfModifiedSince, "Mon, 02 Jan 2006 15:04:05 GMT")
+	if !isNotModified(httpRequest, `"x"`, builtAt) {
+		t.Error("expected not modified")
+	}
+	httpRequest.Header.Set(restful.HEADER_IfModifiedSince, "Mon, 02 Jan 2006 15:04:04 GMT")
+	if isNotModified(httpRequest, `"x"`, builtAt) {
+		t.Error("expected modified")
+	}
+}
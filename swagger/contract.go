@@ -0,0 +1,472 @@
+package swagger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+)
+
+// Exchange is a request that is replayed against a Container by a ContractVerifier.
+type Exchange struct {
+	Method string
+	URL    string // path and query, e.g. /users/1?fields=name
+	Header http.Header
+	Body   []byte
+}
+
+// ExchangeOf returns the Exchange that replays a recorded request.
+// Headers that were redacted by the RequestRecorder are left out ; set them using ContractVerifier.Header.
+func ExchangeOf(recording restful.Recording) Exchange {
+	header := http.Header{}
+	for name, values := range recording.RequestHeader {
+		if len(values) == 1 && values[0] == "[REDACTED]" {
+			continue
+		}
+		header[name] = values
+	}
+	target := recording.URL
+	if parsed, err := url.Parse(recording.URL); err == nil {
+		target = parsed.RequestURI()
+	}
+	return Exchange{Method: recording.Method, URL: target, Header: header, Body: []byte(recording.RequestBody)}
+}
+
+// ContractViolation describes how a response differs from the API declaration.
+type ContractViolation struct {
+	Method  string
+	URL     string
+	Status  int
+	Message string
+}
+
+// Error returns a text representation of the violation
+func (v ContractViolation) Error() string {
+	return fmt.Sprintf("%s %s (%d): %s", v.Method, v.URL, v.Status, v.Message)
+}
+
+// ContractVerifier replays requests against a Container and checks the responses against the
+// ApiDeclarations that are built for its WebServices, to detect that implementation and documentation drift apart.
+// A response violates the contract if:
+//   - its operation is not documented ;
+//   - its status is not 2xx or 3xx and not one of the ResponseMessages of the operation ;
+//   - its Content-Type is not one the operation produces ;
+//   - its JSON body does not match the model of the operation (or of the ResponseMessage for an error status):
+//     a property is unknown, a required property is missing or a value has another type.
+//
+// Typical use in a test:
+//
+//	verifier := swagger.NewContractVerifier(container, swagger.Config{})
+//	if err := verifier.VerifyAll(verifier.GenerateExchanges()); err != nil {
+//		t.Error(err)
+//	}
+type ContractVerifier struct {
+	// Header is added to each replayed request, e.g. for authorization.
+	Header http.Header
+	// PathSamples has values for path parameters by name, e.g. the id of an existing user, for GenerateExchanges.
+	PathSamples map[string]string
+
+	container    *restful.Container
+	declarations map[string]ApiDeclaration
+}
+
+// NewContractVerifier returns a ContractVerifier for the container. If the config has no WebServices,
+// the WebServices registered in the container are used.
+func NewContractVerifier(container *restful.Container, config Config) *ContractVerifier {
+	if len(config.WebServices) == 0 {
+		config.WebServices = container.RegisteredWebServices()
+	}
+	return &ContractVerifier{
+		Header:       http.Header{},
+		PathSamples:  map[string]string{},
+		container:    container,
+		declarations: newSwaggerService(config).produceAllDeclarations(),
+	}
+}
+
+// VerifyAll replays each exchange and returns all violations, if any.
+func (v *ContractVerifier) VerifyAll(exchanges []Exchange) error {
+	errs := []error{}
+	for _, each := range exchanges {
+		for _, violation := range v.Verify(each) {
+			errs = append(errs, violation)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify replays the exchange and returns the violations of the response.
+func (v *ContractVerifier) Verify(exchange Exchange) []ContractViolation {
+	httpRequest := httptest.NewRequest(exchange.Method, exchange.URL, bytes.NewReader(exchange.Body))
+	for name, values := range exchange.Header {
+		httpRequest.Header[name] = values
+	}
+	for name, values := range v.Header {
+		httpRequest.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	v.container.ServeHTTP(recorder, httpRequest)
+
+	violations := []ContractViolation{}
+	violate := func(format string, args ...interface{}) {
+		violations = append(violations, ContractViolation{Method: exchange.Method, URL: exchange.URL, Status: recorder.Code, Message: fmt.Sprintf(format, args...)})
+	}
+	operation, models, ok := v.operationFor(exchange.Method, httpRequest.URL.Path)
+	if !ok {
+		violate("operation is not documented")
+		return violations
+	}
+	var responseModel *DataTypeFields
+	if recorder.Code < 400 {
+		responseModel = &operation.DataTypeFields
+	} else {
+		documented := false
+		for _, each := range operation.ResponseMessages {
+			if each.Code == recorder.Code {
+				documented = true
+				if len(each.ResponseModel) > 0 {
+					responseModel = dataTypeOfResponseModel(each.ResponseModel)
+				}
+			}
+		}
+		if !documented {
+			violate("status is not documented")
+		}
+	}
+	if recorder.Body.Len() == 0 {
+		return violations
+	}
+	contentType := recorder.Header().Get(restful.HEADER_ContentType)
+	if semicolon := strings.Index(contentType, ";"); semicolon != -1 {
+		contentType = contentType[:semicolon]
+	}
+	if recorder.Code < 400 && len(operation.Produces) > 0 && !contains(operation.Produces, contentType) {
+		violate("content type %q is not one of %v", contentType, operation.Produces)
+	}
+	if responseModel == nil || !strings.Contains(contentType, "json") {
+		return violations
+	}
+	if responseModel.Type != nil && *responseModel.Type == "void" {
+		return violations // no WriteSample ; nothing to check
+	}
+	decoder := json.NewDecoder(bytes.NewReader(recorder.Body.Bytes()))
+	decoder.UseNumber()
+	var body interface{}
+	if err := decoder.Decode(&body); err != nil {
+		violate("body is not valid JSON: %v", err)
+		return violations
+	}
+	for _, each := range checkValue(body, *responseModel, models, "body", 0) {
+		violate("%s", each)
+	}
+	return violations
+}
+
+// operationFor returns the documented operation with the method whose path matches with the most literal segments.
+func (v *ContractVerifier) operationFor(method, path string) (operation Operation, models ModelList, found bool) {
+	best := -1
+	for _, decl := range v.declarations {
+		for _, api := range decl.Apis {
+			literals, ok := pathMatches(api.Path, path)
+			if !ok || literals <= best {
+				continue
+			}
+			for _, each := range api.Operations {
+				if each.Method == method {
+					operation, models, found, best = each, decl.Models, true, literals
+				}
+			}
+		}
+	}
+	return
+}
+
+// pathMatches returns whether the path matches the template, e.g. /users/{id} matches /users/42,
+// and the number of segments that matched literally.
+func pathMatches(template, path string) (int, bool) {
+	templateParts := strings.Split(strings.Trim(template, "/"), "/")
+	pathParts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(templateParts) != len(pathParts) {
+		return 0, false
+	}
+	literals := 0
+	for i, each := range templateParts {
+		if strings.HasPrefix(each, "{") && strings.HasSuffix(each, "}") {
+			continue
+		}
+		if each != pathParts[i] {
+			return 0, false
+		}
+		literals++
+	}
+	return literals, true
+}
+
+// dataTypeOfResponseModel returns the DataTypeFields of a ResponseMessage model, e.g. "restful.Error" or "array[restful.Error]".
+func dataTypeOfResponseModel(model string) *DataTypeFields {
+	if strings.HasPrefix(model, "array[") && strings.HasSuffix(model, "]") {
+		array, element := "array", model[len("array["):len(model)-1]
+		return &DataTypeFields{Type: &array, Items: &Item{Ref: &element}}
+	}
+	return &DataTypeFields{Ref: &model}
+}
+
+// maxModelDepth limits the nesting that is checked or generated, e.g. for recursive models.
+const maxModelDepth = 8
+
+// checkValue returns the differences between a decoded JSON value and its declared type.
+// Types that are not known, e.g. "any" for maps, accept any value.
+func checkValue(value interface{}, declared DataTypeFields, models ModelList, path string, depth int) []string {
+	if value == nil || depth > maxModelDepth {
+		return nil // null is accepted for any type
+	}
+	typeName := ""
+	if declared.Ref != nil {
+		typeName = *declared.Ref
+	} else if declared.Type != nil {
+		typeName = *declared.Type
+	}
+	mismatch := func(expected string) []string {
+		return []string{fmt.Sprintf("%s is %s, want %s", path, jsonKind(value), expected)}
+	}
+	switch typeName {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch("string")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch("boolean")
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return mismatch("number")
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return mismatch("integer")
+		}
+		if _, err := number.Int64(); err != nil {
+			if _, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+				return mismatch("integer")
+			}
+		}
+	case "array":
+		elements, ok := value.([]interface{})
+		if !ok {
+			return mismatch("array")
+		}
+		if declared.Items == nil {
+			return nil
+		}
+		itemType := DataTypeFields{Type: declared.Items.Type, Ref: declared.Items.Ref}
+		problems := []string{}
+		for i, each := range elements {
+			problems = append(problems, checkValue(each, itemType, models, fmt.Sprintf("%s[%d]", path, i), depth+1)...)
+		}
+		return problems
+	default:
+		model, ok := models.At(typeName)
+		if !ok {
+			return nil
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch("object " + typeName)
+		}
+		return checkObject(object, model, models, path, depth)
+	}
+	return nil
+}
+
+func checkObject(object map[string]interface{}, model Model, models ModelList, path string, depth int) []string {
+	problems := []string{}
+	for _, each := range model.Required {
+		if _, ok := object[each]; !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s is required", path, each))
+		}
+	}
+	names := make([]string, 0, len(object))
+	for each := range object {
+		names = append(names, each)
+	}
+	sort.Strings(names)
+	for _, each := range names {
+		property, ok := model.Properties.At(each)
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s.%s is not a property of %s", path, each, model.Id))
+			continue
+		}
+		problems = append(problems, checkValue(object[each], property.DataTypeFields, models, path+"."+each, depth+1)...)
+	}
+	return problems
+}
+
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case json.Number:
+		return "a number"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return "null"
+}
+
+func contains(list []string, value string) bool {
+	for _, each := range list {
+		if each == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateExchanges returns an Exchange for each documented operation, with sample values for the
+// path parameters, the required query and header parameters and the body, as declared by their
+// type, default value or enum. Path parameters can be given a value using PathSamples.
+func (v *ContractVerifier) GenerateExchanges() []Exchange {
+	keys := make([]string, 0, len(v.declarations))
+	for each := range v.declarations {
+		keys = append(keys, each)
+	}
+	sort.Strings(keys)
+	exchanges := []Exchange{}
+	for _, key := range keys {
+		decl := v.declarations[key]
+		for _, api := range decl.Apis {
+			for _, operation := range api.Operations {
+				exchanges = append(exchanges, v.generateExchange(api.Path, operation, decl.Models))
+			}
+		}
+	}
+	return exchanges
+}
+
+func (v *ContractVerifier) generateExchange(path string, operation Operation, models ModelList) Exchange {
+	exchange := Exchange{Method: operation.Method, Header: http.Header{}}
+	query := url.Values{}
+	for _, each := range operation.Parameters {
+		sample := sampleParameter(each)
+		switch each.ParamType {
+		case "path":
+			if value, ok := v.PathSamples[each.Name]; ok {
+				sample = value
+			}
+			path = replacePathParameter(path, each.Name, url.PathEscape(sample))
+		case "query":
+			if each.Required {
+				query.Set(each.Name, sample)
+			}
+		case "header":
+			if each.Required {
+				exchange.Header.Set(each.Name, sample)
+			}
+		case "body":
+			body, _ := json.Marshal(sampleValue(each.DataTypeFields, models, 0))
+			exchange.Body = body
+		}
+	}
+	if exchange.Body == nil {
+		for _, each := range []string{"POST", "PUT", "PATCH"} {
+			if operation.Method == each && len(operation.Consumes) > 0 {
+				exchange.Body = []byte("{}")
+			}
+		}
+	}
+	if exchange.Body != nil {
+		contentType := restful.MIME_JSON
+		if len(operation.Consumes) > 0 && !contains(operation.Consumes, restful.MIME_JSON) {
+			contentType = operation.Consumes[0]
+		}
+		exchange.Header.Set(restful.HEADER_ContentType, contentType)
+	}
+	if len(operation.Produces) > 0 {
+		exchange.Header.Set(restful.HEADER_Accept, strings.Join(operation.Produces, ","))
+	}
+	exchange.URL = path
+	if len(query) > 0 {
+		exchange.URL += "?" + query.Encode()
+	}
+	return exchange
+}
+
+// replacePathParameter replaces {name} or {name:expression} in the path.
+func replacePathParameter(path, name, value string) string {
+	parts := strings.Split(path, "/")
+	for i, each := range parts {
+		if each == "{"+name+"}" || strings.HasPrefix(each, "{"+name+":") {
+			parts[i] = value
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func sampleParameter(parameter Parameter) string {
+	if len(parameter.DefaultValue) > 0 {
+		return string(parameter.DefaultValue)
+	}
+	if len(parameter.Enum) > 0 {
+		return parameter.Enum[0]
+	}
+	sample := sampleValue(parameter.DataTypeFields, ModelList{}, 0)
+	if text, ok := sample.(string); ok {
+		return text
+	}
+	data, _ := json.Marshal(sample)
+	return string(data)
+}
+
+// sampleValue returns a value of the declared type ; objects have a sample for each property.
+func sampleValue(declared DataTypeFields, models ModelList, depth int) interface{} {
+	if len(declared.Enum) > 0 {
+		return declared.Enum[0]
+	}
+	typeName := ""
+	if declared.Ref != nil {
+		typeName = *declared.Ref
+	} else if declared.Type != nil {
+		typeName = *declared.Type
+	}
+	switch typeName {
+	case "integer", "int", "int32", "int64":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean", "bool":
+		return true
+	case "string":
+		if declared.Format == "date-time" {
+			return "2006-01-02T15:04:05Z"
+		}
+		return "sample"
+	case "array":
+		if declared.Items == nil || depth > maxModelDepth {
+			return []interface{}{}
+		}
+		return []interface{}{sampleValue(DataTypeFields{Type: declared.Items.Type, Ref: declared.Items.Ref}, models, depth+1)}
+	}
+	model, ok := models.At(typeName)
+	if !ok || depth > maxModelDepth {
+		return map[string]interface{}{}
+	}
+	object := map[string]interface{}{}
+	model.Properties.Do(func(name string, property ModelProperty) {
+		object[name] = sampleValue(property.DataTypeFields, models, depth+1)
+	})
+	return object
+}
@@ -0,0 +1,122 @@
+package swagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+type contractUser struct {
+	ID    int      `json:"id"`
+	Name  string   `json:"name"`
+	Email string   `json:"email,omitempty"`
+	Tags  []string `json:"tags"`
+}
+
+// newContractContainer returns a container whose GET /users/{id} writes the body, which can drift from the User model.
+func newContractContainer(body func(id string) interface{}) *restful.Container {
+	c := restful.NewContainer()
+	ws := new(restful.WebService).Path("/users").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.GET("/{id}").To(func(req *restful.Request, resp *restful.Response) {
+		if req.PathParameter("id") == "404" {
+			resp.WriteErrorString(http.StatusNotFound, "no such user")
+			return
+		}
+		resp.WriteEntity(body(req.PathParameter("id")))
+	}).Param(ws.PathParameter("id", "identifier").DataType("integer")).
+		Writes(contractUser{}).
+		Returns(http.StatusNotFound, "not found", nil))
+	ws.Route(ws.POST("").To(func(req *restful.Request, resp *restful.Response) {
+		var user contractUser
+		if err := req.ReadEntity(&user); err != nil {
+			resp.WriteErrorString(http.StatusBadRequest, err.Error())
+			return
+		}
+		resp.WriteHeaderAndEntity(http.StatusCreated, user)
+	}).Reads(contractUser{}).Writes(contractUser{}))
+	c.Add(ws)
+	return c
+}
+
+// go test -v -test.run TestContractVerifier ...swagger
+func TestContractVerifier(t *testing.T) {
+	c := newContractContainer(func(id string) interface{} { return contractUser{ID: 1, Name: "ann", Tags: []string{"a"}} })
+	verifier := NewContractVerifier(c, Config{})
+	exchanges := verifier.GenerateExchanges()
+	if got, want := len(exchanges), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := exchanges[0].URL, "/users/1"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := string(exchanges[1].Body), `"name":"sample"`; !strings.Contains(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if err := verifier.VerifyAll(exchanges); err != nil {
+		t.Error(err)
+	}
+	verifier.PathSamples["id"] = "404"
+	if err := verifier.VerifyAll(verifier.GenerateExchanges()); err != nil {
+		t.Error(err)
+	}
+}
+
+// go test -v -test.run TestContractVerifierDrift ...swagger
+func TestContractVerifierDrift(t *testing.T) {
+	c := newContractContainer(func(id string) interface{} {
+		return map[string]interface{}{"id": "1", "fullName": "ann", "tags": []int{1}}
+	})
+	verifier := NewContractVerifier(c, Config{})
+	violations := verifier.Verify(Exchange{Method: "GET", URL: "/users/1"})
+	messages := []string{}
+	for _, each := range violations {
+		messages = append(messages, each.Message)
+	}
+	want := []string{
+		"body.name is required",
+		"body.fullName is not a property of swagger.contractUser",
+		"body.id is a string, want integer",
+		"body.tags[0] is a number, want string",
+	}
+	if got := strings.Join(messages, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got\n%v\nwant\n%v", got, strings.Join(want, "\n"))
+	}
+
+	violations = verifier.Verify(Exchange{Method: "POST", URL: "/users", Header: http.Header{"Content-Type": {restful.MIME_JSON}}, Body: []byte("{")})
+	if len(violations) != 1 || violations[0].Message != "status is not documented" || violations[0].Status != 400 {
+		t.Errorf("got %v", violations)
+	}
+	violations = verifier.Verify(Exchange{Method: "DELETE", URL: "/users/1"})
+	if len(violations) != 1 || violations[0].Message != "operation is not documented" {
+		t.Errorf("got %v", violations)
+	}
+}
+
+// go test -v -test.run TestContractVerifierReplay ...swagger
+func TestContractVerifierReplay(t *testing.T) {
+	c := newContractContainer(func(id string) interface{} { return contractUser{ID: 1, Name: "ann"} })
+	recorder := restful.NewRequestRecorder(10)
+	recorder.Enable(true)
+	c.Filter(recorder.Filter)
+	httpRequest := httptest.NewRequest("GET", "/users/7?verbose=true", nil)
+	httpRequest.Header.Set("Authorization", "Bearer secret")
+	c.ServeHTTP(httptest.NewRecorder(), httpRequest)
+
+	recordings := recorder.Recordings()
+	if got, want := len(recordings), 1; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	exchange := ExchangeOf(recordings[0])
+	if got, want := exchange.URL, "/users/7?verbose=true"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if _, ok := exchange.Header["Authorization"]; ok {
+		t.Error("redacted header is replayed")
+	}
+	if violations := NewContractVerifier(c, Config{}).Verify(exchange); len(violations) > 0 {
+		t.Error(violations)
+	}
+}
@@ -145,6 +145,14 @@ type Operation struct {
 	Produces         []string          `json:"produces,omitempty"`
 	Consumes         []string          `json:"consumes,omitempty"`
 	Deprecated       string            `json:"deprecated,omitempty"`
+	// ParameterDependencies is an extension ; the dependencies are also described in the Notes
+	ParameterDependencies []ParameterDependency `json:"x-parameterDependencies,omitempty"`
+}
+
+// ParameterDependency is an extension that describes a relationship between parameters, e.g. mutuallyExclusive
+type ParameterDependency struct {
+	Kind       string   `json:"kind"`
+	Parameters []string `json:"parameters"`
 }
 
 // 5.2.4 Parameter Object
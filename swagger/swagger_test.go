@@ -282,3 +282,41 @@ func TestIssue78(t *testing.T) {
 		t.Fatal("wrong $ref:" + *ref)
 	}
 }
+
+// go test -v -test.run TestParameterDependencies ...swagger
+func TestParameterDependencies(t *testing.T) {
+	ws := new(restful.WebService)
+	ws.Route(ws.GET("/orders").To(dummy).
+		Notes("Lists orders.").
+		MutuallyExclusive("since", "until").
+		RequiredTogether("page", "size"))
+	sws := newSwaggerService(Config{WebServices: []*restful.WebService{ws}})
+	decl := sws.composeDeclaration(ws, "/")
+	operation := decl.Apis[0].Operations[0]
+	if got, want := operation.Notes, "Lists orders.\n\nParameters since, until are mutually exclusive.\n\nParameters page, size must be given together."; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := len(operation.ParameterDependencies), 2; got != want {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got, want := operation.ParameterDependencies[1].Kind, restful.RequiredTogether; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestExcludeFromDocumentation ...swagger
+func TestExcludeFromDocumentation(t *testing.T) {
+	ws := new(restful.WebService).Path("/orders")
+	ws.Route(ws.GET("").To(dummy))
+	ws.Route(ws.GET("/internal").To(dummy).Metadata(restful.KeyExcludeFromDocumentation, true))
+	health := new(restful.WebService).Path("/healthz").Metadata(restful.KeyExcludeFromDocumentation, true)
+	health.Route(health.GET("").To(dummy))
+	sws := newSwaggerService(Config{WebServices: []*restful.WebService{ws, health}})
+	if _, ok := sws.apiDeclarationMap.At("/healthz"); ok {
+		t.Error("healthz should not be documented")
+	}
+	decl, _ := sws.apiDeclarationMap.At("/orders")
+	if got, want := len(decl.Apis), 1; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
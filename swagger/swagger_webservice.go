@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/emicklei/go-restful/log"
 )
@@ -16,21 +17,23 @@ import (
 type SwaggerService struct {
 	config            Config
 	apiDeclarationMap *ApiDeclarationList
+	builtAt           time.Time // used for Last-Modified
 }
 
 func newSwaggerService(config Config) *SwaggerService {
 	sws := &SwaggerService{
 		config:            config,
-		apiDeclarationMap: new(ApiDeclarationList)}
+		apiDeclarationMap: new(ApiDeclarationList),
+		builtAt:           time.Now()}
 
 	// Build all ApiDeclarations
 	for _, each := range config.WebServices {
 		rootPath := each.RootPath()
-		// skip the api service itself
-		if rootPath != config.ApiPath {
+		// skip the api service itself and services without documented routes
+		if rootPath != config.ApiPath && len(each.DocumentedRoutes()) > 0 {
 			if rootPath == "" || rootPath == "/" {
 				// use routes
-				for _, route := range each.Routes() {
+				for _, route := range each.DocumentedRoutes() {
 					entry := staticPathFromRoute(route)
 					_, exists := sws.apiDeclarationMap.At(entry)
 					if !exists {
@@ -140,7 +143,7 @@ func enableCORS(req *restful.Request, resp *restful.Response, chain *restful.Fil
 
 func (sws SwaggerService) getListing(req *restful.Request, resp *restful.Response) {
 	listing := sws.produceListing()
-	resp.WriteAsJson(listing)
+	writeJsonConditionally(req, resp, sws.builtAt, listing)
 }
 
 func (sws SwaggerService) produceListing() ResourceListing {
@@ -188,7 +191,7 @@ func (sws SwaggerService) getDeclarations(req *restful.Request, resp *restful.Re
 		}
 		decl.BasePath = fmt.Sprintf("%s://%s", scheme, host)
 	}
-	resp.WriteAsJson(decl)
+	writeJsonConditionally(req, resp, sws.builtAt, decl)
 }
 
 func (sws SwaggerService) produceAllDeclarations() map[string]ApiDeclaration {
@@ -224,7 +227,7 @@ func (sws SwaggerService) composeDeclaration(ws *restful.WebService, pathPrefix
 	}
 	// aggregate by path
 	pathToRoutes := newOrderedRouteMap()
-	for _, other := range ws.Routes() {
+	for _, other := range ws.DocumentedRoutes() {
 		if strings.HasPrefix(other.Path, pathPrefix) {
 			pathToRoutes.Add(other.Path, other)
 		}
@@ -245,6 +248,10 @@ func (sws SwaggerService) composeDeclaration(ws *restful.WebService, pathPrefix
 
 			operation.Consumes = route.Consumes
 			operation.Produces = route.Produces
+			for _, each := range route.ParameterDependencies {
+				operation.ParameterDependencies = append(operation.ParameterDependencies, ParameterDependency{Kind: each.Kind, Parameters: each.Names})
+				operation.Notes = strings.TrimSpace(operation.Notes + "\n\n" + each.Description())
+			}
 
 			// share root params if any
 			for _, swparam := range rootParams {
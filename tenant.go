@@ -0,0 +1,144 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TenantKey is the attribute key of the tenant identifier that is set by a TenantFilter.
+var TenantKey = NewAttributeKey[string]("restful.tenant")
+
+// tenantContextKey is the key of the tenant identifier in the context of a request.
+type tenantContextKey struct{}
+
+// TenantSource returns the tenant identifier of a request ; empty if the request has none.
+type TenantSource func(req *Request) string
+
+// TenantFromHeader returns a TenantSource that uses the value of a request header, e.g. X-Tenant-ID.
+func TenantFromHeader(name string) TenantSource {
+	return func(req *Request) string {
+		return strings.TrimSpace(req.Request.Header.Get(name))
+	}
+}
+
+// TenantFromHost returns a TenantSource that uses the label of the host before the domain,
+// e.g. "acme" for the host acme.api.example.com and domain "api.example.com". The port is ignored.
+func TenantFromHost(domain string) TenantSource {
+	suffix := "." + strings.ToLower(strings.Trim(domain, "."))
+	return func(req *Request) string {
+		host := req.Request.Host
+		if withoutPort, _, err := net.SplitHostPort(host); err == nil {
+			host = withoutPort
+		}
+		host = strings.ToLower(host)
+		if !strings.HasSuffix(host, suffix) {
+			return ""
+		}
+		label := host[:len(host)-len(suffix)]
+		if strings.Contains(label, ".") {
+			return "" // not a direct subdomain
+		}
+		return label
+	}
+}
+
+// TenantFromPathPrefix returns a TenantSource that uses the path segment after the prefix,
+// e.g. "acme" for the path /tenants/acme/orders and prefix "/tenants". Unlike TenantFromPathParameter,
+// it does not depend on the selected Route and therefore also works for a Container filter of a 404.
+func TenantFromPathPrefix(prefix string) TenantSource {
+	prefix = "/" + strings.Trim(prefix, "/")
+	if prefix == "/" {
+		prefix = ""
+	}
+	return func(req *Request) string {
+		path := req.Request.URL.Path
+		if !strings.HasPrefix(path, prefix+"/") {
+			return ""
+		}
+		segment := path[len(prefix)+1:]
+		if slash := strings.Index(segment, "/"); slash != -1 {
+			segment = segment[:slash]
+		}
+		return segment
+	}
+}
+
+// TenantFromPathParameter returns a TenantSource that uses a path parameter of the selected Route.
+func TenantFromPathParameter(name string) TenantSource {
+	return func(req *Request) string {
+		return req.PathParameter(name)
+	}
+}
+
+// TenantFilter is used to create a Filter that extracts the tenant of a request from the first Source that has one.
+// The tenant is stored as the TenantKey attribute, in the context (see TenantFromContext) and as the "tenant"
+// annotation of the AccessLog, if any, such that metrics can be partitioned by tenant. Use PerTenant to partition rate limits.
+//
+//	tenants := restful.TenantFilter{Sources: []restful.TenantSource{restful.TenantFromHeader("X-Tenant-ID"), restful.TenantFromHost("api.example.com")}, Required: true}
+//	container.Filter(tenants.Filter)
+type TenantFilter struct {
+	Sources []TenantSource
+	// Required rejects a request without a tenant with a 400 (Bad Request).
+	Required bool
+	// Known, if not nil, rejects a request for an unknown tenant with a 404 (Not Found).
+	Known func(tenant string) bool
+}
+
+// Filter extracts the tenant and passes the request on.
+func (f TenantFilter) Filter(req *Request, resp *Response, chain *FilterChain) {
+	tenant := ""
+	for _, each := range f.Sources {
+		if tenant = each(req); len(tenant) > 0 {
+			break
+		}
+	}
+	if len(tenant) == 0 {
+		if f.Required {
+			resp.WriteProblem(NewProblemDocument(http.StatusBadRequest, "tenant required"))
+			return
+		}
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	if f.Known != nil && !f.Known(tenant) {
+		resp.WriteProblem(NewProblemDocument(http.StatusNotFound, "unknown tenant: "+tenant))
+		return
+	}
+	SetAttr(req, TenantKey, tenant)
+	AnnotateAccessLog(req.Context(), "tenant", tenant)
+	chain.ProcessFilter(req.WithContext(context.WithValue(req.Context(), tenantContextKey{}, tenant)), resp)
+}
+
+// TenantOf returns the tenant of the request as set by a TenantFilter ; empty if none.
+func TenantOf(req *Request) string {
+	tenant, _ := GetAttr(req, TenantKey)
+	return tenant
+}
+
+// TenantFromContext returns the tenant of the request with this context as set by a TenantFilter,
+// e.g. in services called by a RouteFunction.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok
+}
+
+// PerTenant returns a key function that prefixes the key with the tenant of the request,
+// e.g. to limit the rate of requests per client per tenant:
+//
+//	restful.RateLimitPolicy{RequestsPerSecond: 10, Burst: 20, Key: restful.PerTenant(restful.RemoteAddrKey)}
+//
+// A nil key limits the requests per tenant. The TenantFilter must come before the RateLimiter.
+func PerTenant(key func(req *Request) string) func(req *Request) string {
+	return func(req *Request) string {
+		if key == nil {
+			return TenantOf(req)
+		}
+		return TenantOf(req) + "|" + key(req)
+	}
+}
@@ -0,0 +1,83 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestTenantSources ...restful
+func TestTenantSources(t *testing.T) {
+	for i, each := range []struct {
+		source TenantSource
+		url    string
+		header string
+		want   string
+	}{
+		{TenantFromHeader("X-Tenant"), "/orders", "acme", "acme"},
+		{TenantFromHost("api.example.com"), "http://Acme.api.example.com:8080/orders", "", "acme"},
+		{TenantFromHost("api.example.com"), "http://a.b.api.example.com/orders", "", ""},
+		{TenantFromHost("api.example.com"), "http://api.example.com/orders", "", ""},
+		{TenantFromPathPrefix("/tenants/"), "/tenants/acme/orders", "", "acme"},
+		{TenantFromPathPrefix("/tenants"), "/tenantsacme/orders", "", ""},
+		{TenantFromPathPrefix("/"), "/acme/orders", "", "acme"},
+	} {
+		httpRequest := httptest.NewRequest("GET", each.url, nil)
+		httpRequest.Header.Set("X-Tenant", each.header)
+		if got := each.source(NewRequest(httpRequest)); got != each.want {
+			t.Errorf("[%d] got %q want %q", i, got, each.want)
+		}
+	}
+}
+
+// go test -v -test.run TestTenantFilter ...restful
+func TestTenantFilter(t *testing.T) {
+	container := NewContainer()
+	tenants := TenantFilter{
+		Sources:  []TenantSource{TenantFromHeader("X-Tenant"), TenantFromPathParameter("tenant")},
+		Required: true,
+		Known:    func(tenant string) bool { return tenant != "unknown" },
+	}
+	ws := new(WebService).Path("/tenants/{tenant}")
+	ws.Route(ws.GET("/orders").Filter(tenants.Filter).To(func(req *Request, resp *Response) {
+		fromContext, _ := TenantFromContext(req.Context())
+		resp.Write([]byte(TenantOf(req) + "," + fromContext + "," + PerTenant(HeaderKey("X-Client"))(req)))
+	}))
+	container.Add(ws)
+
+	for _, each := range []struct {
+		path, header string
+		status       int
+		body         string
+	}{
+		{"/tenants/acme/orders", "", http.StatusOK, "acme,acme,acme|client"},
+		{"/tenants/acme/orders", "other", http.StatusOK, "other,other,other|client"},
+		{"/tenants/unknown/orders", "", http.StatusNotFound, ""},
+	} {
+		httpRequest, _ := http.NewRequest("GET", each.path, nil)
+		httpRequest.Header.Set("X-Tenant", each.header)
+		httpRequest.Header.Set("X-Client", "client")
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, each.status; got != want {
+			t.Errorf("%s %s: got %d want %d", each.path, each.header, got, want)
+		}
+		if len(each.body) > 0 {
+			if got, want := httpWriter.Body.String(), each.body; got != want {
+				t.Errorf("got %q want %q", got, want)
+			}
+		}
+	}
+}
+
+// go test -v -test.run TestTenantFilterRequired ...restful
+func TestTenantFilterRequired(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/orders", nil)
+	httpWriter := httptest.NewRecorder()
+	chain := FilterChain{Filters: []FilterFunction{TenantFilter{Sources: []TenantSource{TenantFromHeader("X-Tenant")}, Required: true}.Filter},
+		Target: func(req *Request, resp *Response) { t.Error("request without tenant passed") }}
+	chain.ProcessFilter(NewRequest(httpRequest), NewResponse(httpWriter))
+	if got, want := httpWriter.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %d want %d", got, want)
+	}
+}
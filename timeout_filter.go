@@ -0,0 +1,182 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// KeyTimeout is the Route metadata key for a time.Duration that overrides the Duration of the Timeout filter for that Route.
+// A zero or negative duration disables the timeout for the Route.
+const KeyTimeout = "restful.timeout"
+
+// Timeout is used to create a Filter that limits the time the rest of the chain can take.
+// The request context is cancelled at the deadline ; handlers that call slow upstreams should pass it on.
+// If the chain has not completed by then, the filter writes a problem document with Status and
+// anything the chain writes afterwards is discarded.
+// The response is buffered until the chain completes, therefore streaming responses do not work with this filter.
+// Request scoped dependencies (see ProvideScoped) of a chain that is still running at the deadline are
+// cleaned up when it completes.
+//
+//	restful.Filter(restful.Timeout{Duration: 5 * time.Second}.Filter)
+type Timeout struct {
+	Duration time.Duration
+	Status   int // default is 503 (Service Unavailable) ; use 504 (Gateway Timeout) for routes that proxy upstreams
+}
+
+// Filter runs the rest of the chain with a deadline.
+// A panic in the chain is raised again on the goroutine of the request, as a *RecoveredPanic with the stack of the chain.
+func (t Timeout) Filter(req *Request, resp *Response, chain *FilterChain) {
+	duration := t.Duration
+	if route := req.SelectedRoute(); route != nil {
+		if override, ok := route.Metadata[KeyTimeout].(time.Duration); ok {
+			duration = override
+		}
+	}
+	if duration <= 0 {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), duration)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	// the chain writes to a shadow Response that has its own state ; after a timeout it may keep writing to it
+	buffer := &timeoutWriter{header: resp.Header().Clone()}
+	shadow := *resp
+	shadow.ResponseWriter = buffer
+	shadow.commitHooks = resp.commitHooks[:len(resp.commitHooks):len(resp.commitHooks)]
+	recorded := new(bytes.Buffer)
+	if resp.bodyRecorder != nil {
+		shadow.bodyRecorder = recorded
+	}
+	if resp.lifecycle != nil {
+		lifecycle := *resp.lifecycle
+		shadow.lifecycle = &lifecycle
+	}
+	done := make(chan struct{})
+	completed := make(chan struct{}) // also if the chain panicked
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer close(completed)
+		defer func() {
+			if reason := recoveredPanic(recover()); reason != nil {
+				panicked <- reason
+			}
+		}()
+		chain.ProcessFilter(req, &shadow)
+		buffer.complete()
+		close(done)
+	}()
+	select {
+	case reason := <-panicked:
+		panic(reason)
+	case <-done:
+	case <-ctx.Done():
+		if buffer.timeout() {
+			status := t.Status
+			if status == 0 {
+				status = http.StatusServiceUnavailable
+			}
+			resp.WriteProblem(NewProblemDocument(status, fmt.Sprintf("request did not complete within %v", duration)))
+			if req.scope != nil {
+				req.scope.closeAfter(completed)
+			}
+			return
+		}
+		<-done // completed just in time
+	}
+	shadow.closeCompressor()
+	header := resp.Header()
+	for k := range header {
+		if _, ok := buffer.header[k]; !ok {
+			delete(header, k) // removed by the chain
+		}
+	}
+	buffer.copyTo(resp.ResponseWriter)
+	if resp.bodyRecorder != nil {
+		resp.bodyRecorder.Write(recorded.Bytes())
+	}
+	original, recorder, lifecycle := resp.ResponseWriter, resp.bodyRecorder, resp.lifecycle
+	*resp = shadow
+	resp.ResponseWriter, resp.bodyRecorder, resp.lifecycle = original, recorder, lifecycle
+}
+
+// timeoutWriter buffers a response until it is copied or discarded.
+type timeoutWriter struct {
+	lock        sync.Mutex
+	header      http.Header
+	buffer      bytes.Buffer
+	code        int
+	wroteHeader bool
+	timedOut    bool
+	completed   bool
+}
+
+// Header is part of http.ResponseWriter
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader is part of http.ResponseWriter
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.timedOut || w.wroteHeader {
+		return
+	}
+	w.code, w.wroteHeader = code, true
+}
+
+// Write is part of http.ResponseWriter ; it fails with http.ErrHandlerTimeout after the deadline.
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !w.wroteHeader {
+		w.code, w.wroteHeader = http.StatusOK, true
+	}
+	return w.buffer.Write(data)
+}
+
+// timeout makes further writes fail ; it returns false if the chain has already completed.
+func (w *timeoutWriter) timeout() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.completed {
+		return false
+	}
+	w.timedOut = true
+	return true
+}
+
+// complete marks the end of the chain.
+func (w *timeoutWriter) complete() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.completed = true
+}
+
+// copyTo writes the buffered header and body to the destination.
+func (w *timeoutWriter) copyTo(destination http.ResponseWriter) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	for k, v := range w.header {
+		destination.Header()[k] = v
+	}
+	if w.wroteHeader {
+		destination.WriteHeader(w.code)
+	}
+	if w.buffer.Len() > 0 {
+		destination.Write(w.buffer.Bytes())
+	}
+}
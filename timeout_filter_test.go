@@ -0,0 +1,145 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestTimeout ...restful
+func TestTimeout(t *testing.T) {
+	cancelled := make(chan bool, 1)
+	container := NewContainer()
+	container.Filter(Timeout{Duration: time.Hour}.Filter)
+	ws := new(WebService).Path("/slow")
+	ws.Route(ws.GET("/hang").To(func(req *Request, resp *Response) {
+		<-req.Request.Context().Done()
+		cancelled <- true
+		time.Sleep(50 * time.Millisecond)
+		resp.WriteErrorString(http.StatusTeapot, "too late")
+	}).Metadata(KeyTimeout, 10*time.Millisecond))
+	ws.Route(ws.GET("/fast").To(writeFood).Produces(MIME_JSON))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/slow/hang", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_PROBLEM_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected request context to be cancelled")
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/slow/fast", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusOK; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentType), MIME_JSON; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if httpWriter.Body.Len() == 0 {
+		t.Error("expected body")
+	}
+}
+
+func TestTimeout_Compressed(t *testing.T) {
+	container := NewContainer()
+	container.EnableContentEncoding(true)
+	container.Filter(Timeout{Duration: time.Hour, Status: http.StatusGatewayTimeout}.Filter)
+	ws := new(WebService).Path("/food")
+	ws.Route(ws.GET("").To(writeFood).Produces(MIME_JSON))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/food", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Header().Get(HEADER_ContentEncoding), ENCODING_GZIP; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := httpWriter.Body.Len(); got < 20 {
+		t.Errorf("expected complete gzip stream, got %d bytes", got)
+	}
+}
+
+// go test -v -race -test.run TestTimeout_DetachedShadow ...restful
+func TestTimeout_DetachedShadow(t *testing.T) {
+	written := make(chan bool, 1)
+	container := NewContainer()
+	container.OnEntityWritten(func(e LifecycleEvent) {})
+	container.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		resp.Header().Set("X-Before", "filter")
+		chain.ProcessFilter(req, resp)
+	})
+	container.Filter(Timeout{Duration: 10 * time.Millisecond}.Filter)
+	ws := new(WebService).Path("/shadow").Produces(MIME_JSON)
+	ws.Route(ws.GET("/seen").To(func(req *Request, resp *Response) {
+		resp.WriteEntity(food{Kind: resp.Header().Get("X-Before")})
+	}))
+	ws.Route(ws.GET("/late").To(func(req *Request, resp *Response) {
+		<-req.Request.Context().Done()
+		time.Sleep(20 * time.Millisecond)
+		resp.WriteEntity(food{Kind: "late"})
+		written <- true
+	}))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/shadow/seen", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "filter"; !strings.Contains(got, want) {
+		t.Errorf("got %v want it to contain %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get("X-Before"), "filter"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "http://here.com/shadow/late", nil)
+	httpWriter = httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	<-written
+}
+
+func panicAfterTimeoutFilter(req *Request, resp *Response) {
+	panic("boom")
+}
+
+// go test -v -test.run TestTimeout_PanicStack ...restful
+func TestTimeout_PanicStack(t *testing.T) {
+	var reason interface{}
+	var stack []byte
+	container := NewContainer()
+	container.Filter(PanicRecovery{Logger: testLogger{t}, OnPanic: func(r interface{}, s []byte, req *Request) {
+		reason, stack = r, s
+	}}.Filter)
+	container.Filter(Timeout{Duration: time.Hour}.Filter)
+	ws := new(WebService).Path("/panic")
+	ws.Route(ws.GET("").To(panicAfterTimeoutFilter))
+	container.Add(ws)
+
+	httpRequest, _ := http.NewRequest("GET", "/panic", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusInternalServerError; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := reason, "boom"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := string(stack), "panicAfterTimeoutFilter"; !strings.Contains(got, want) {
+		t.Errorf("got stack %s want %s", got, want)
+	}
+}
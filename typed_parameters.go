@@ -0,0 +1,127 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The typed accessors below return the default value if the parameter is absent or empty.
+// If the value cannot be converted, they return a ServiceError with code 400 (Bad Request)
+// that can be written as is:
+//
+//	page, err := req.QueryInt("page", 1)
+//	if err != nil {
+//		resp.WriteServiceError(http.StatusBadRequest, err.(restful.ServiceError))
+//		return
+//	}
+
+// QueryInt returns the Query parameter value as int.
+func (r *Request) QueryInt(name string, defaultValue int) (int, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return i, nil
+}
+
+// QueryInt64 returns the Query parameter value as int64.
+func (r *Request) QueryInt64(name string, defaultValue int64) (int64, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return i, nil
+}
+
+// QueryFloat returns the Query parameter value as float64.
+func (r *Request) QueryFloat(name string, defaultValue float64) (float64, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return f, nil
+}
+
+// QueryBool returns the Query parameter value as bool ; accepted values are those of strconv.ParseBool.
+func (r *Request) QueryBool(name string, defaultValue bool) (bool, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return b, nil
+}
+
+// QueryDuration returns the Query parameter value as time.Duration, e.g. "1m30s".
+func (r *Request) QueryDuration(name string, defaultValue time.Duration) (time.Duration, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return d, nil
+}
+
+// QueryTime returns the Query parameter value as time.Time parsed using the layout, e.g. time.RFC3339.
+func (r *Request) QueryTime(name, layout string, defaultValue time.Time) (time.Time, error) {
+	value := r.QueryParameter(name)
+	if len(value) == 0 {
+		return defaultValue, nil
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return defaultValue, invalidParameter("query", name, value, err)
+	}
+	return t, nil
+}
+
+// PathParameterInt returns the Path parameter value as int.
+func (r *Request) PathParameterInt(name string) (int, error) {
+	value := r.PathParameter(name)
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, invalidParameter("path", name, value, err)
+	}
+	return i, nil
+}
+
+// PathParameterInt64 returns the Path parameter value as int64.
+func (r *Request) PathParameterInt64(name string) (int64, error) {
+	value := r.PathParameter(name)
+	i, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, invalidParameter("path", name, value, err)
+	}
+	return i, nil
+}
+
+// invalidParameter returns a 400 ServiceError that explains the ParameterError.
+func invalidParameter(kind, name, value string, err error) error {
+	if numError, ok := err.(*strconv.NumError); ok {
+		err = numError.Err
+	}
+	return NewError(http.StatusBadRequest, ParameterError{Kind: kind, Name: name, Value: value, Err: err}.Error())
+}
@@ -0,0 +1,62 @@
+package restful
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestTypedQueryParameters ...restful
+func TestTypedQueryParameters(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/?page=3&ratio=0.25&debug=true&wait=2s&since=2015-06-01&big=9000000000&bad=x", nil)
+	req := NewRequest(httpRequest)
+	if got, _ := req.QueryInt("page", 1); got != 3 {
+		t.Errorf("got %v want 3", got)
+	}
+	if got, _ := req.QueryInt("size", 20); got != 20 {
+		t.Errorf("got %v want default 20", got)
+	}
+	if got, _ := req.QueryInt64("big", 0); got != 9000000000 {
+		t.Errorf("got %v", got)
+	}
+	if got, _ := req.QueryFloat("ratio", 1); got != 0.25 {
+		t.Errorf("got %v", got)
+	}
+	if got, _ := req.QueryBool("debug", false); !got {
+		t.Errorf("got %v", got)
+	}
+	if got, _ := req.QueryDuration("wait", 0); got != 2*time.Second {
+		t.Errorf("got %v", got)
+	}
+	if got, _ := req.QueryTime("since", "2006-01-02", time.Time{}); got.Month() != time.June {
+		t.Errorf("got %v", got)
+	}
+	got, err := req.QueryInt("bad", 7)
+	if got != 7 {
+		t.Errorf("got %v want default 7", got)
+	}
+	serviceError, ok := err.(ServiceError)
+	if !ok || serviceError.Code != http.StatusBadRequest {
+		t.Fatalf("got %v want 400 ServiceError", err)
+	}
+	if got, want := serviceError.Message, `query parameter "bad" has invalid value "x": invalid syntax`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestPathParameterInt ...restful
+func TestPathParameterInt(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/", nil)
+	req := NewRequest(httpRequest)
+	req.PathParameters()["id"] = "42"
+	req.PathParameters()["name"] = "joe"
+	if got, err := req.PathParameterInt("id"); got != 42 || err != nil {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if got, err := req.PathParameterInt64("id"); got != 42 || err != nil {
+		t.Errorf("got %v, %v", got, err)
+	}
+	if _, err := req.PathParameterInt("name"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,100 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Validator checks values after they are decoded by Request.ReadEntity or Request.BindParameters.
+// Validate returns nil, a ValidationError listing the invalid fields or any other error.
+// See examples/restful-validation.go for an adapter of github.com/go-playground/validator.
+type Validator interface {
+	Validate(value interface{}) error
+}
+
+// ValidatorFunc is a function that implements Validator.
+type ValidatorFunc func(value interface{}) error
+
+// Validate is part of Validator
+func (f ValidatorFunc) Validate(value interface{}) error {
+	return f(value)
+}
+
+// FieldError describes an invalid field of an entity or an invalid parameter.
+type FieldError struct {
+	Field   string `json:"field"` // e.g. "address.zipCode" ; empty if the error is not about a single field
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by Request.ReadEntity (with Status 422) and Request.BindParameters
+// (with Status 400) if the Validator of the Container rejects the value.
+type ValidationError struct {
+	Status int
+	Fields []FieldError
+}
+
+// Error returns the messages of all fields
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, each := range e.Fields {
+		if len(each.Field) > 0 {
+			messages[i] = each.Field + ": " + each.Message
+		} else {
+			messages[i] = each.Message
+		}
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validator sets the Validator that is used for all entities and bound parameters of requests dispatched by the Container.
+func (c *Container) Validator(validator Validator) {
+	c.validator = validator
+}
+
+// validate runs the Validator of the Container, if any, and returns a ValidationError with the status if the value is invalid.
+func (r *Request) validate(value interface{}, status int) error {
+	if r.validator == nil {
+		return nil
+	}
+	switch err := r.validator.Validate(value).(type) {
+	case nil:
+		return nil
+	case ValidationError:
+		if err.Status == 0 {
+			err.Status = status
+		}
+		return err
+	default:
+		return ValidationError{Status: status, Fields: []FieldError{{Message: err.Error()}}}
+	}
+}
+
+// WriteValidationError writes a ProblemDocument that lists the invalid fields of a ValidationError
+// or the invalid parameters of ParameterErrors (as returned by BindParameters).
+// Other errors are written as 400 (Bad Request).
+//
+//	if err := req.ReadEntity(&order); err != nil {
+//		resp.WriteValidationError(err)
+//		return
+//	}
+func (r *Response) WriteValidationError(err error) error {
+	switch invalid := err.(type) {
+	case ValidationError:
+		problem := NewProblemDocument(invalid.Status, r.LocalizedMessage(MessageValidationFailed, "validation failed"))
+		problem.Errors = invalid.Fields
+		return r.WriteProblem(problem)
+	case ParameterErrors:
+		problem := NewProblemDocument(http.StatusBadRequest, r.LocalizedMessage(MessageInvalidParameters, "invalid parameters"))
+		for _, each := range invalid {
+			problem.Errors = append(problem.Errors, FieldError{Field: each.Name, Message: each.Error()})
+		}
+		return r.WriteProblem(problem)
+	case ServiceError:
+		return r.WriteProblem(NewProblemDocument(invalid.Code, invalid.Message))
+	}
+	return r.WriteProblem(NewProblemDocument(http.StatusBadRequest, err.Error()))
+}
@@ -0,0 +1,101 @@
+package restful
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validatedFood struct {
+	Kind string `json:"kind" query:"kind"`
+}
+
+var foodValidator = ValidatorFunc(func(value interface{}) error {
+	if food, ok := value.(*validatedFood); ok {
+		if len(food.Kind) == 0 {
+			return ValidationError{Fields: []FieldError{{Field: "kind", Message: "is required"}}}
+		}
+		if food.Kind == "poison" {
+			return errors.New("not edible")
+		}
+	}
+	return nil
+})
+
+func newValidatingContainer() *Container {
+	c := NewContainer()
+	c.Validator(foodValidator)
+	ws := new(WebService).Path("/validated").Consumes(MIME_JSON).Produces(MIME_JSON)
+	ws.Route(ws.POST("").To(func(req *Request, resp *Response) {
+		food := new(validatedFood)
+		if err := req.ReadEntity(food); err != nil {
+			resp.WriteValidationError(err)
+			return
+		}
+		resp.WriteEntity(food)
+	}))
+	ws.Route(ws.GET("").To(func(req *Request, resp *Response) {
+		food := new(validatedFood)
+		if err := req.BindParameters(food); err != nil {
+			resp.WriteValidationError(err)
+			return
+		}
+		resp.WriteEntity(food)
+	}))
+	c.Add(ws)
+	return c
+}
+
+// go test -v -test.run TestValidation ...restful
+func TestValidation(t *testing.T) {
+	c := newValidatingContainer()
+	for _, each := range []struct {
+		method, path, body string
+		status             int
+		errors             string
+	}{
+		{"POST", "/validated", `{"kind":"apple"}`, http.StatusOK, ""},
+		{"POST", "/validated", `{"kind":""}`, http.StatusUnprocessableEntity, "kind: is required"},
+		{"POST", "/validated", `{"kind":"poison"}`, http.StatusUnprocessableEntity, ": not edible"},
+		{"GET", "/validated?kind=pear", "", http.StatusOK, ""},
+		{"GET", "/validated", "", http.StatusBadRequest, "kind: is required"},
+	} {
+		httpRequest, _ := http.NewRequest(each.method, each.path, strings.NewReader(each.body))
+		httpRequest.Header.Set(HEADER_ContentType, MIME_JSON)
+		httpRequest.Header.Set(HEADER_Accept, MIME_JSON)
+		recorder := httptest.NewRecorder()
+		c.ServeHTTP(recorder, httpRequest)
+		if got := recorder.Code; got != each.status {
+			t.Errorf("%s %s: got %v want %v", each.method, each.body, got, each.status)
+			continue
+		}
+		if len(each.errors) == 0 {
+			continue
+		}
+		var problem ProblemDocument
+		json.Unmarshal(recorder.Body.Bytes(), &problem)
+		fields := []string{}
+		for _, field := range problem.Errors {
+			fields = append(fields, field.Field+": "+field.Message)
+		}
+		if got := strings.Join(fields, ","); got != each.errors {
+			t.Errorf("%s %s: got %v want %v", each.method, each.body, got, each.errors)
+		}
+	}
+}
+
+// go test -v -test.run TestWriteValidationErrorParameterErrors ...restful
+func TestWriteValidationErrorParameterErrors(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponse(recorder)
+	resp.WriteValidationError(ParameterErrors{{Kind: "query", Name: "page", Value: "x", Err: errors.New("invalid syntax")}})
+	if got, want := recorder.Code, http.StatusBadRequest; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := recorder.Body.String(), `"field":"page"`; !strings.Contains(got, want) {
+		t.Errorf("got %v want it to contain %v", got, want)
+	}
+}
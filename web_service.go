@@ -21,6 +21,7 @@ type WebService struct {
 	consumes       []string
 	pathParameters []*Parameter
 	filters        []FilterFunction
+	priorities     []int // priority of each of the filters
 	documentation  string
 	apiVersion     string
 
@@ -28,6 +29,14 @@ type WebService struct {
 
 	// protects 'routes' if dynamic routes are enabled
 	routesLock sync.RWMutex
+
+	onAttach []func(c *Container)
+	onDetach []func(c *Container)
+
+	metadata map[string]interface{} // defaults for the Metadata of its Routes
+
+	messageCatalog MessageCatalog // messages of this WebService, see MessageCatalog
+	mergedCatalog  MessageCatalog // messageCatalog then that of the Container it was added to
 }
 
 func (w *WebService) SetDynamicRoutes(enable bool) {
@@ -148,10 +157,36 @@ func (w *WebService) Route(builder *RouteBuilder) *WebService {
 	w.routesLock.Lock()
 	defer w.routesLock.Unlock()
 	builder.copyDefaults(w.produces, w.consumes)
+	for key, value := range w.metadata {
+		if _, ok := builder.metadata[key]; !ok {
+			builder.Metadata(key, value)
+		}
+	}
 	w.routes = append(w.routes, builder.Build())
 	return w
 }
 
+// Metadata adds a value for all its Routes, e.g. a KeyCORS or KeyRateLimit policy.
+// Routes that already have a value for the key (e.g. set by RouteBuilder.Metadata) keep it.
+func (w *WebService) Metadata(key string, value interface{}) *WebService {
+	w.routesLock.Lock()
+	defer w.routesLock.Unlock()
+	if w.metadata == nil {
+		w.metadata = map[string]interface{}{}
+	}
+	w.metadata[key] = value
+	for i := range w.routes {
+		if _, ok := w.routes[i].Metadata[key]; ok {
+			continue
+		}
+		if w.routes[i].Metadata == nil {
+			w.routes[i].Metadata = map[string]interface{}{}
+		}
+		w.routes[i].Metadata[key] = value
+	}
+	return w
+}
+
 // RemoveRoute removes the specified route, looks for something that matches 'path' and 'method'
 func (w *WebService) RemoveRoute(path, method string) error {
 	if !w.dynamicRoutes {
@@ -167,7 +202,8 @@ func (w *WebService) RemoveRoute(path, method string) error {
 	return nil
 }
 
-// Method creates a new RouteBuilder and initialize its http method
+// Method creates a new RouteBuilder and initialize its http method.
+// Any method can be used, including extension methods such as PROPFIND, MKCOL, REPORT or PURGE.
 func (w *WebService) Method(httpMethod string) *RouteBuilder {
 	return new(RouteBuilder).servicePath(w.rootPath).Method(httpMethod)
 }
@@ -201,6 +237,17 @@ func (w WebService) Routes() []Route {
 	return result
 }
 
+// DocumentedRoutes returns the Routes of this WebService that are not excluded from documentation.
+func (w *WebService) DocumentedRoutes() []Route {
+	routes := []Route{}
+	for _, each := range w.Routes() {
+		if !each.ExcludedFromDocumentation() {
+			routes = append(routes, each)
+		}
+	}
+	return routes
+}
+
 // RootPath returns the RootPath associated with this WebService. Default "/"
 func (w WebService) RootPath() string {
 	return w.rootPath
@@ -213,7 +260,13 @@ func (w WebService) PathParameters() []*Parameter {
 
 // Filter adds a filter function to the chain of filters applicable to all its Routes
 func (w *WebService) Filter(filter FilterFunction) *WebService {
-	w.filters = append(w.filters, filter)
+	return w.FilterWithPriority(DefaultFilterPriority, filter)
+}
+
+// FilterWithPriority adds a filter function that is called before all filters of the WebService with a higher priority
+// and after those with a lower or equal priority.
+func (w *WebService) FilterWithPriority(priority int, filter FilterFunction) *WebService {
+	w.filters, w.priorities = insertFilter(w.filters, w.priorities, filter, priority)
 	return w
 }
 
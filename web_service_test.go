@@ -252,3 +252,31 @@ func doPanic(req *Request, resp *Response) {
 
 func doNothing(req *Request, resp *Response) {
 }
+
+// go test -v -test.run TestExtensionMethod ...restful
+func TestExtensionMethod(t *testing.T) {
+	tearDown()
+	ws := new(WebService).Path("/dav")
+	ws.Route(ws.Method("PROPFIND").Path("/{name}").To(dummy))
+	ws.Route(ws.Method("MKCOL").Path("/{name}").To(dummy))
+	Add(ws)
+
+	for _, method := range []string{"PROPFIND", "MKCOL"} {
+		httpRequest, _ := http.NewRequest(method, "http://here.com/dav/docs", nil)
+		httpWriter := httptest.NewRecorder()
+		DefaultContainer.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Code, http.StatusOK; got != want {
+			t.Errorf("%s: got %v want %v", method, got, want)
+		}
+	}
+	httpRequest, _ := http.NewRequest("REPORT", "http://here.com/dav/docs", nil)
+	httpWriter := httptest.NewRecorder()
+	DefaultContainer.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusMethodNotAllowed; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	allowed := DefaultContainer.computeAllowedMethods(NewRequest(httpRequest))
+	if len(allowed) != 2 {
+		t.Errorf("unexpected allowed methods %v", allowed)
+	}
+}
@@ -0,0 +1,69 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// IsWebSocketUpgrade returns whether the client asks to upgrade the connection to the WebSocket protocol.
+func (r *Request) IsWebSocketUpgrade() bool {
+	return headerHasToken(r.Request.Header, HEADER_Connection, "upgrade") &&
+		headerHasToken(r.Request.Header, HEADER_Upgrade, "websocket")
+}
+
+// headerHasToken returns whether one of the comma separated values of the header is the token, ignoring case.
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header[http.CanonicalHeaderKey(name)] {
+		for _, each := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(each), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Hijack lets the caller take over the connection, e.g. after upgrading to the WebSocket protocol.
+// Hijack is part of the http.Hijacker interface. It returns http.ErrNotSupported if the underlying ResponseWriter
+// cannot be hijacked, e.g. when it is replaced by a Filter such as Timeout.
+// After a successful Hijack the status of the Response is 101 (Switching Protocols) such that
+// filters and logging can tell an upgraded connection from a regular response.
+func (r *Response) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	r.committed = true
+	r.wroteHeader = true
+	r.statusCode = http.StatusSwitchingProtocols
+	return conn, rw, nil
+}
+
+// WebSocket returns a RouteFunction that hands WebSocket upgrade requests to the handler,
+// e.g. a golang.org/x/net/websocket.Handler or a http.HandlerFunc that calls a gorilla/websocket Upgrader.
+// The handler gets the Response as http.ResponseWriter so that it can hijack the connection.
+// Other requests get a 426 (Upgrade Required). Because it is a regular Route, filters of
+// the Container, WebService and Route, e.g. for authentication, apply before the upgrade.
+//
+//	ws.Route(ws.GET("/chat").Filter(authenticate).To(restful.WebSocket(websocket.Handler(chat))))
+func WebSocket(handler http.Handler) RouteFunction {
+	return func(req *Request, resp *Response) {
+		if !req.IsWebSocketUpgrade() {
+			resp.Header().Set(HEADER_Upgrade, "websocket")
+			resp.Header().Set(HEADER_Connection, "Upgrade")
+			resp.WriteErrorString(http.StatusUpgradeRequired, "426: Upgrade Required")
+			return
+		}
+		handler.ServeHTTP(resp, req.Request)
+	}
+}
@@ -0,0 +1,86 @@
+package restful
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsWebSocketUpgrade(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/chat", nil)
+	httpRequest.Header.Set(HEADER_Connection, "keep-alive, Upgrade")
+	httpRequest.Header.Set(HEADER_Upgrade, "WebSocket")
+	if !NewRequest(httpRequest).IsWebSocketUpgrade() {
+		t.Error("upgrade expected")
+	}
+	httpRequest.Header.Del(HEADER_Upgrade)
+	if NewRequest(httpRequest).IsWebSocketUpgrade() {
+		t.Error("no upgrade expected")
+	}
+}
+
+// go test -v -test.run TestWebSocketHijack ...restful
+func TestWebSocketHijack(t *testing.T) {
+	status := make(chan int, 1)
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		line, _ := rw.ReadString('\n')
+		rw.WriteString("echo " + line)
+		rw.Flush()
+	})
+	ws := new(WebService).Path("/chat")
+	ws.Route(ws.GET("").To(WebSocket(echo)))
+	c := NewContainer().Add(ws)
+	c.Filter(func(req *Request, resp *Response, chain *FilterChain) {
+		chain.ProcessFilter(req, resp)
+		status <- resp.StatusCode()
+	})
+	server := httptest.NewServer(c)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("GET /chat HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\nhello\n"))
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := response.StatusCode, http.StatusSwitchingProtocols; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	line, _ := reader.ReadString('\n')
+	if got, want := line, "echo hello\n"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	if got, want := <-status, http.StatusSwitchingProtocols; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestWebSocketUpgradeRequired(t *testing.T) {
+	ws := new(WebService).Path("/chat")
+	ws.Route(ws.GET("").To(WebSocket(http.NotFoundHandler())))
+	httpRequest, _ := http.NewRequest("GET", "/chat", nil)
+	httpWriter := httptest.NewRecorder()
+	NewContainer().Add(ws).ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusUpgradeRequired; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_Upgrade), "websocket"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
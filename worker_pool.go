@@ -0,0 +1,138 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// RejectionPolicy determines what a WorkerPool does with a request if its queue is full.
+type RejectionPolicy int
+
+const (
+	// RejectWhenFull writes a 503 (Service Unavailable) immediately ; this is the default
+	RejectWhenFull RejectionPolicy = iota
+	// WaitWhenFull waits for room in the queue until the request context is done, then writes a 503
+	WaitWhenFull
+)
+
+// WorkerPool runs the RouteFunctions of selected Routes (and their remaining filters) on a bounded
+// number of goroutines, such that CPU-heavy endpoints cannot starve other ones.
+// The goroutine of the request waits for the worker to complete.
+//
+//	reports := restful.NewWorkerPool(4, 100)
+//	ws.Route(ws.GET("/reports/{id}").Filter(reports.Filter).To(buildReport))
+type WorkerPool struct {
+	jobs      chan func()
+	policy    RejectionPolicy
+	active    int64
+	rejected  int64
+	completed int64
+	closeOnce sync.Once
+	quit      chan struct{} // closed by Close to wake up requests waiting for room in the queue
+	lock      sync.RWMutex  // guards sending to jobs against closing it
+	closed    bool
+}
+
+// WorkerPoolStats is a snapshot of the counters of a WorkerPool.
+type WorkerPoolStats struct {
+	Queued    int   // requests waiting for a worker
+	Active    int   // requests being processed by a worker
+	Rejected  int64 // requests rejected since creation
+	Completed int64 // requests processed since creation
+}
+
+// NewWorkerPool creates and starts a WorkerPool with a number of workers and a queue of a size.
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	pool := &WorkerPool{jobs: make(chan func(), queueSize), quit: make(chan struct{})}
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+	return pool
+}
+
+// RejectionPolicy sets the behavior for a full queue ; default is RejectWhenFull.
+func (p *WorkerPool) RejectionPolicy(policy RejectionPolicy) *WorkerPool {
+	p.policy = policy
+	return p
+}
+
+// Stats returns the current counters, e.g. for exposing the queue length as a metric.
+func (p *WorkerPool) Stats() WorkerPoolStats {
+	return WorkerPoolStats{
+		Queued:    len(p.jobs),
+		Active:    int(atomic.LoadInt64(&p.active)),
+		Rejected:  atomic.LoadInt64(&p.rejected),
+		Completed: atomic.LoadInt64(&p.completed),
+	}
+}
+
+// Close stops the workers after the queued requests are processed.
+// Requests that arrive afterwards, e.g. during a graceful shutdown, are rejected with a 503 (Service Unavailable).
+func (p *WorkerPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.quit)
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		p.closed = true
+		close(p.jobs)
+	})
+}
+
+func (p *WorkerPool) work() {
+	for job := range p.jobs {
+		atomic.AddInt64(&p.active, 1)
+		job()
+		atomic.AddInt64(&p.active, -1)
+		atomic.AddInt64(&p.completed, 1)
+	}
+}
+
+// Filter runs the rest of the chain on a worker of the pool.
+// A panic on the worker is raised again on the goroutine of the request, as a *RecoveredPanic with the stack of the worker.
+func (p *WorkerPool) Filter(req *Request, resp *Response, chain *FilterChain) {
+	done := make(chan interface{}, 1)
+	job := func() {
+		defer func() {
+			done <- recoveredPanic(recover())
+		}()
+		chain.ProcessFilter(req, resp)
+	}
+	if !p.submit(req, job) {
+		atomic.AddInt64(&p.rejected, 1)
+		resp.WriteProblem(NewProblemDocument(http.StatusServiceUnavailable, "too many requests for this resource"))
+		return
+	}
+	if reason := <-done; reason != nil {
+		panic(reason)
+	}
+}
+
+// submit queues the job unless the pool is closed or, depending on the policy, the queue is full.
+func (p *WorkerPool) submit(req *Request, job func()) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.closed {
+		return false
+	}
+	if p.policy == WaitWhenFull {
+		select {
+		case p.jobs <- job:
+			return true
+		case <-req.Request.Context().Done():
+			return false
+		case <-p.quit:
+			return false
+		}
+	}
+	select {
+	case p.jobs <- job:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,118 @@
+package restful
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// go test -v -test.run TestWorkerPool ...restful
+func TestWorkerPool(t *testing.T) {
+	pool := NewWorkerPool(1, 1)
+	defer pool.Close()
+	started, release := make(chan bool), make(chan bool)
+	container := NewContainer()
+	ws := new(WebService).Path("/heavy")
+	ws.Route(ws.GET("").Filter(pool.Filter).To(func(req *Request, resp *Response) {
+		started <- true
+		<-release
+	}))
+	container.Add(ws)
+
+	results := make(chan int, 2)
+	dispatch := func() {
+		httpRequest, _ := http.NewRequest("GET", "http://here.com/heavy", nil)
+		httpWriter := httptest.NewRecorder()
+		container.dispatch(httpWriter, httpRequest)
+		results <- httpWriter.Code
+	}
+	go dispatch()
+	<-started // worker is busy
+	go dispatch()
+	for pool.Stats().Queued != 1 { // wait until the second request is queued
+		time.Sleep(time.Millisecond)
+	}
+	// queue is full now
+	httpRequest, _ := http.NewRequest("GET", "http://here.com/heavy", nil)
+	httpWriter := httptest.NewRecorder()
+	container.dispatch(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	release <- true
+	<-started
+	release <- true
+	for i := 0; i < 2; i++ {
+		if got, want := <-results, http.StatusOK; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+	stats := pool.Stats()
+	if got, want := stats.Rejected, int64(1); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := stats.Completed, int64(2); got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestWorkerPool_Closed ...restful
+func TestWorkerPool_Closed(t *testing.T) {
+	for _, policy := range []RejectionPolicy{RejectWhenFull, WaitWhenFull} {
+		pool := NewWorkerPool(1, 1).RejectionPolicy(policy)
+		pool.Close()
+		chain := FilterChain{Target: func(req *Request, resp *Response) { t.Error("unexpected processing") }}
+		httpWriter := httptest.NewRecorder()
+		pool.Filter(NewRequest(httptest.NewRequest("GET", "/", nil)), NewResponse(httpWriter), &chain)
+		if got, want := httpWriter.Code, http.StatusServiceUnavailable; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestWorkerPool_CloseWhileWaiting ...restful
+func TestWorkerPool_CloseWhileWaiting(t *testing.T) {
+	pool := NewWorkerPool(1, 0).RejectionPolicy(WaitWhenFull)
+	started, release := make(chan bool), make(chan bool)
+	busy := FilterChain{Target: func(req *Request, resp *Response) {
+		started <- true
+		<-release
+	}}
+	go pool.Filter(NewRequest(httptest.NewRequest("GET", "/", nil)), NewResponse(httptest.NewRecorder()), &busy)
+	<-started // the only worker is busy
+	waiting := make(chan int)
+	go func() {
+		chain := FilterChain{Target: func(req *Request, resp *Response) {}}
+		httpWriter := httptest.NewRecorder()
+		pool.Filter(NewRequest(httptest.NewRequest("GET", "/", nil)), NewResponse(httpWriter), &chain)
+		waiting <- httpWriter.Code
+	}()
+	time.Sleep(10 * time.Millisecond) // let the second request wait for room
+	pool.Close()
+	if got, want := <-waiting, http.StatusServiceUnavailable; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	release <- true
+}
+
+func TestWorkerPool_Panic(t *testing.T) {
+	pool := NewWorkerPool(1, 0)
+	defer pool.Close()
+	pool.RejectionPolicy(WaitWhenFull)
+	defer func() {
+		recovered, ok := recover().(*RecoveredPanic)
+		if !ok {
+			t.Fatal("expected a *RecoveredPanic")
+		}
+		if got, want := recovered.Reason, "boom"; got != want {
+			t.Errorf("got %v want %v", got, want)
+		}
+		if got, want := string(recovered.Stack), "TestWorkerPool_Panic.func"; !strings.Contains(got, want) {
+			t.Errorf("got stack %s want %s", got, want)
+		}
+	}()
+	chain := FilterChain{Target: func(req *Request, resp *Response) { panic("boom") }}
+	pool.Filter(NewRequest(httptest.NewRequest("GET", "/", nil)), NewResponse(httptest.NewRecorder()), &chain)
+}
@@ -0,0 +1,42 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"net/http"
+	"sync"
+)
+
+var requestPool = sync.Pool{New: func() interface{} { return new(Request) }}
+
+var responsePool = sync.Pool{New: func() interface{} { return new(Response) }}
+
+// EnableWrapperPooling (default=false) allows for reusing the Request and Response of a dispatched request
+// for a next one, which saves their allocations. Only enable it if no Route function or filter keeps a
+// reference to either after it returns, e.g. in a goroutine that writes late or in a Timeout filter.
+func (c *Container) EnableWrapperPooling(enabled bool) {
+	c.wrapperPooling = enabled
+}
+
+// acquireRequestResponse returns a Request and Response from the pools, equal to the ones that
+// NewRequest and NewResponse return.
+func acquireRequestResponse(httpWriter http.ResponseWriter, httpRequest *http.Request) (*Request, *Response) {
+	req := requestPool.Get().(*Request)
+	req.Request = httpRequest
+	resp := responsePool.Get().(*Response)
+	resp.ResponseWriter = httpWriter
+	resp.routeProduces = []string{}
+	resp.statusCode = http.StatusOK
+	resp.renderOptions = RenderOptions{PrettyPrint: PrettyPrintResponses}
+	return req, resp
+}
+
+// releaseRequestResponse clears the Request and Response and puts them back in the pools.
+func releaseRequestResponse(req *Request, resp *Response) {
+	*req = Request{}
+	requestPool.Put(req)
+	*resp = Response{}
+	responsePool.Put(resp)
+}
@@ -0,0 +1,58 @@
+package restful
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// go test -v -test.run TestWrapperPoolingClearsRequest ...restful
+func TestWrapperPoolingClearsRequest(t *testing.T) {
+	c := NewContainer()
+	c.EnableWrapperPooling(true)
+	ws := new(WebService).Path("/pooled")
+	ws.Route(ws.GET("/{id}").To(func(req *Request, resp *Response) {
+		if got := req.Attribute("seen"); got != nil {
+			t.Errorf("got attribute %v of a previous request", got)
+		}
+		req.SetAttribute("seen", req.PathParameter("id"))
+		resp.WriteHeader(http.StatusAccepted)
+		resp.Write([]byte(req.PathParameter("id")))
+	}))
+	ws.Route(ws.GET("/static/route").To(func(req *Request, resp *Response) {
+		if got := len(req.PathParameters()); got != 0 {
+			t.Errorf("got %d path parameters of a previous request", got)
+		}
+		resp.Write([]byte("static"))
+	}))
+	c.Add(ws)
+	for _, each := range []struct{ path, body string }{{"/pooled/1", "1"}, {"/pooled/static/route", "static"}, {"/pooled/2", "2"}} {
+		httpRequest, _ := http.NewRequest("GET", each.path, nil)
+		httpWriter := httptest.NewRecorder()
+		c.dispatch(httpWriter, httpRequest)
+		if got, want := httpWriter.Body.String(), each.body; got != want {
+			t.Errorf("%s: got %q want %q", each.path, got, want)
+		}
+	}
+}
+
+// go test -v -test.run TestRequestAllocatesLazily ...restful
+func TestRequestAllocatesLazily(t *testing.T) {
+	req := NewRequest(nil)
+	if req.pathParameters != nil || req.attributes != nil {
+		t.Error("maps allocated by NewRequest")
+	}
+	if got := req.Attribute("missing"); got != nil {
+		t.Errorf("got %v want nil", got)
+	}
+	req.Request, _ = http.NewRequest("GET", "/", nil)
+	copied := req.WithContext(context.Background())
+	copied.SetAttribute("shared", true)
+	if got, want := req.Attribute("shared"), true; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := (Route{Path: "/static"}).extractParameters("/static"); got != nil {
+		t.Errorf("got %v want nil", got)
+	}
+}
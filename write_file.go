@@ -0,0 +1,56 @@
+package restful
+
+// Copyright 2015 Ernest Micklei. All rights reserved.
+// Use of this source code is governed by a license
+// that can be found in the LICENSE file.
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// WriteFile writes the content of the file at the path as a download using WriteReader.
+// If the file cannot be opened or is a directory then the error is returned and nothing is written,
+// such that the RouteFunction can write a 404 (Not Found) or 403 (Forbidden).
+func (r *Response) WriteFile(req *Request, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return errors.New("cannot write directory:" + path)
+	}
+	return r.WriteReader(req, info.Name(), info.ModTime(), file)
+}
+
+// WriteReader writes the content as a download with the given (file) name by delegating to http.ServeContent.
+// Unless already set, the Content-Disposition header is set to attachment with the name and
+// the Content-Type header is derived from the extension of the name or else sniffed from the content.
+// Range requests are answered with 206 (Partial Content) and conditional requests with 304 (Not Modified)
+// using the modification time, if not zero. Because the Response is used for writing, filters and
+// logging see the status and length as written. The content is never compressed such that byte ranges
+// refer to the content as is.
+func (r *Response) WriteReader(req *Request, name string, modified time.Time, content io.ReadSeeker) error {
+	if len(r.Header().Get(HEADER_ContentDisposition)) == 0 {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(name)})
+		if len(disposition) == 0 { // name cannot be encoded
+			disposition = "attachment"
+		}
+		r.Header().Set(HEADER_ContentDisposition, disposition)
+	}
+	if !r.committed {
+		r.renderOptions.Encoding = ""
+	}
+	http.ServeContent(r, req.Request, name, modified, content)
+	return nil
+}
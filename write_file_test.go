@@ -0,0 +1,85 @@
+package restful
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newFileContainer(path string) *Container {
+	ws := new(WebService).Path("/files")
+	ws.Route(ws.GET("/{name}").To(func(req *Request, resp *Response) {
+		if err := resp.WriteFile(req, filepath.Join(path, req.PathParameter("name"))); err != nil {
+			resp.WriteErrorString(http.StatusNotFound, "404: Not Found")
+		}
+	}))
+	c := NewContainer().Add(ws)
+	c.EnableContentEncoding(true)
+	return c
+}
+
+// go test -v -test.run TestWriteFile ...restful
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "menu.txt"), []byte("apple pie"), 0644)
+	c := newFileContainer(dir)
+
+	httpRequest, _ := http.NewRequest("GET", "/files/menu.txt", nil)
+	httpRequest.Header.Set(HEADER_AcceptEncoding, ENCODING_GZIP)
+	httpWriter := httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Body.String(), "apple pie"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentDisposition), `attachment; filename=menu.txt`; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got := httpWriter.Header().Get(HEADER_ContentType); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("got %v want text/plain", got)
+	}
+	if got := httpWriter.Header().Get(HEADER_ContentEncoding); got != "" {
+		t.Errorf("got %v want no encoding", got)
+	}
+
+	httpRequest, _ = http.NewRequest("GET", "/files/missing.txt", nil)
+	httpWriter = httptest.NewRecorder()
+	c.ServeHTTP(httpWriter, httpRequest)
+	if got, want := httpWriter.Code, http.StatusNotFound; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+// go test -v -test.run TestWriteReaderRange ...restful
+func TestWriteReaderRange(t *testing.T) {
+	httpRequest, _ := http.NewRequest("GET", "/files/menu", nil)
+	httpRequest.Header.Set("Range", "bytes=6-8")
+	httpWriter := httptest.NewRecorder()
+	resp := NewResponse(httpWriter)
+	resp.Header().Set(HEADER_ContentDisposition, "inline")
+	resp.WriteReader(NewRequest(httpRequest), "menu", time.Time{}, strings.NewReader("apple pie"))
+	if got, want := resp.StatusCode(), http.StatusPartialContent; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := resp.ContentLength(), 3; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Body.String(), "pie"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+	if got, want := httpWriter.Header().Get(HEADER_ContentDisposition), "inline"; got != want {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestWriteFileDirectory(t *testing.T) {
+	resp := NewResponse(httptest.NewRecorder())
+	httpRequest, _ := http.NewRequest("GET", "/files", nil)
+	if err := resp.WriteFile(NewRequest(httpRequest), os.TempDir()); err == nil {
+		t.Error("error expected")
+	}
+}